@@ -0,0 +1,327 @@
+package gom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// MigrationPlan lists the schema changes that bring the tables of some models in line with their structs
+type MigrationPlan struct {
+	// Changes are applied in order by ApplyMigrationPlan
+	Changes []*define.SchemaChange
+
+	// Skipped are destructive changes not enabled by the AutoMigrateOptions
+	Skipped []*define.SchemaChange
+
+	factory define.SQLFactory
+}
+
+// Empty reports whether the plan has nothing to apply
+func (p *MigrationPlan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Statements renders the changes as DDL statements of the DB's dialect
+func (p *MigrationPlan) Statements() ([]*define.SqlProto, error) {
+	var protos []*define.SqlProto
+	for _, change := range p.Changes {
+		for _, proto := range p.factory.BuildSchemaChange(change) {
+			if proto.Error != nil {
+				return nil, proto.Error
+			}
+			protos = append(protos, proto)
+		}
+	}
+	return protos, nil
+}
+
+// SQL renders the plan as a script, listing skipped changes as comments
+func (p *MigrationPlan) SQL() (string, error) {
+	protos, err := p.Statements()
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, proto := range protos {
+		sb.WriteString(proto.Sql + ";\n")
+	}
+	for _, change := range p.Skipped {
+		fmt.Fprintf(&sb, "-- skipped %s on %s: %s\n", change.Kind, change.Table, change.Reason)
+	}
+	return sb.String(), nil
+}
+
+// AutoMigrate creates missing tables and adds or alters columns so that the tables match models
+// Destructive changes are skipped, see AutoMigrateWithOptions
+func (db *DB) AutoMigrate(models ...interface{}) error {
+	return db.AutoMigrateWithOptions(define.AutoMigrateOptions{}, models...)
+}
+
+// AutoMigrateWithOptions is AutoMigrate with the destructive changes enabled by opts
+func (db *DB) AutoMigrateWithOptions(opts define.AutoMigrateOptions, models ...interface{}) error {
+	plan, err := db.PlanAutoMigrate(opts, models...)
+	if err != nil {
+		return err
+	}
+	return db.ApplyMigrationPlan(plan)
+}
+
+// PlanAutoMigrate compares models with the live tables and returns the changes AutoMigrate would make
+func (db *DB) PlanAutoMigrate(opts define.AutoMigrateOptions, models ...interface{}) (*MigrationPlan, error) {
+	plan := &MigrationPlan{factory: db.Factory}
+	for _, model := range models {
+		modelType, err := structTypeOf(model)
+		if err != nil {
+			return nil, err
+		}
+
+		table := getTableNameFromStruct(modelType, model)
+		def := db.Factory.DescribeModel(table, modelType)
+		info, err := db.Factory.GetTableInfo(db.executor(), table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get table info of %s: %w", table, err)
+		}
+
+		if len(info.Columns) == 0 {
+			plan.Changes = append(plan.Changes, &define.SchemaChange{Kind: define.ChangeCreateTable, Table: table, TableDef: def})
+			continue
+		}
+		for _, change := range db.diffTable(def, info) {
+			if change.Destructive && !allowed(opts, change) {
+				plan.Skipped = append(plan.Skipped, change)
+			} else {
+				plan.Changes = append(plan.Changes, change)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// PlanCreateTables returns a plan creating the tables of models in the dialect of factory
+// It needs no database, so it can render the DDL of a model-first schema
+func PlanCreateTables(factory define.SQLFactory, models ...interface{}) (*MigrationPlan, error) {
+	plan := &MigrationPlan{factory: factory}
+	for _, model := range models {
+		modelType, err := structTypeOf(model)
+		if err != nil {
+			return nil, err
+		}
+		table := getTableNameFromStruct(modelType, model)
+		plan.Changes = append(plan.Changes, &define.SchemaChange{
+			Kind: define.ChangeCreateTable, Table: table, TableDef: factory.DescribeModel(table, modelType),
+		})
+	}
+	return plan, nil
+}
+
+// ApplyMigrationPlan runs the statements of plan
+// On PostgreSQL they run in one transaction; MySQL commits each DDL statement implicitly
+func (db *DB) ApplyMigrationPlan(plan *MigrationPlan) error {
+	protos, err := plan.Statements()
+	if err != nil || len(protos) == 0 {
+		return err
+	}
+	defer func() {
+		for _, change := range plan.Changes {
+			db.invalidateTableInfo(change.Table)
+		}
+	}()
+
+	run := func(c *Chain) error {
+		for _, proto := range protos {
+			if result := c.executeSqlProto(proto); result.Error != nil {
+				return fmt.Errorf("auto migrate: %s: %w", proto.Sql, result.Error)
+			}
+		}
+		return nil
+	}
+	if db.Factory.GetType() == "postgres" {
+		return db.Chain().Transaction(run)
+	}
+	return run(db.Chain())
+}
+
+// diffTable compares a model's table definition with a live table
+// Indexes and foreign keys are dropped before the columns change and created afterwards
+func (db *DB) diffTable(def *define.TableDef, info *define.TableInfo) []*define.SchemaChange {
+	drops, creates := diffKeys(def, info)
+	changes := drops
+	live := make(map[string]*define.ColumnInfo, len(info.Columns))
+	for i := range info.Columns {
+		live[info.Columns[i].Name] = &info.Columns[i]
+	}
+
+	for _, col := range def.Columns {
+		current, ok := live[col.Name]
+		if !ok {
+			changes = append(changes, &define.SchemaChange{
+				Kind: define.ChangeAddColumn, Table: def.Name, Column: col,
+				Reason: fmt.Sprintf("column %s is missing", col.Name),
+			})
+			continue
+		}
+
+		change := &define.SchemaChange{Kind: define.ChangeAlterColumn, Table: def.Name, Column: col, Current: current}
+		var reasons []string
+		if !db.Factory.ColumnTypeMatches(col, current) {
+			change.TypeChanged = true
+			change.Destructive = true
+			reasons = append(reasons, fmt.Sprintf("type %s -> %s", liveType(current), col.Type))
+		}
+		if !col.PrimaryKey && col.Nullable != current.IsNullable {
+			change.NullableChanged = true
+			if col.Nullable {
+				reasons = append(reasons, "NOT NULL -> NULL")
+			} else {
+				change.Destructive = true
+				reasons = append(reasons, "NULL -> NOT NULL")
+			}
+		}
+		if col.Default != "" && !col.AutoIncrement && define.NormalizeDefault(col.Default) != define.NormalizeDefault(current.DefaultValue) {
+			change.DefaultChanged = true
+			reasons = append(reasons, fmt.Sprintf("default %q -> %q", current.DefaultValue, col.Default))
+		}
+		if len(reasons) > 0 {
+			change.Reason = fmt.Sprintf("column %s: %s", col.Name, strings.Join(reasons, ", "))
+			changes = append(changes, change)
+		}
+	}
+
+	for i := range info.Columns {
+		current := &info.Columns[i]
+		if def.Column(current.Name) == nil {
+			changes = append(changes, &define.SchemaChange{
+				Kind: define.ChangeDropColumn, Table: def.Name, Current: current, Destructive: true,
+				Reason: fmt.Sprintf("column %s has no model field", current.Name),
+			})
+		}
+	}
+
+	return append(changes, creates...)
+}
+
+// diffKeys compares the declared indexes and foreign keys with info.Indexes and info.ForeignKeys by name
+// Missing ones are created, changed ones dropped and recreated, and undeclared ones dropped as destructive changes.
+// Primary keys, the unique indexes of unique columns and MySQL's indexes backing foreign keys are not declared as indexes and are kept.
+// Foreign keys are dropped before indexes and created after them, since MySQL needs an index on foreign key columns
+func diffKeys(def *define.TableDef, info *define.TableInfo) (drops, creates []*define.SchemaChange) {
+	var dropKeys, dropIndexes, createIndexes, createKeys []*define.SchemaChange
+
+	liveKeys := make(map[string]*define.ForeignKeyInfo, len(info.ForeignKeys))
+	fkNames := make(map[string]bool)
+	for i := range info.ForeignKeys {
+		liveKeys[strings.ToLower(info.ForeignKeys[i].Name)] = &info.ForeignKeys[i]
+		fkNames[strings.ToLower(info.ForeignKeys[i].Name)] = true
+	}
+	declaredKeys := make(map[string]bool, len(def.ForeignKeys))
+	for _, fk := range def.ForeignKeys {
+		name := strings.ToLower(fk.Name)
+		declaredKeys[name], fkNames[name] = true, true
+		current, ok := liveKeys[name]
+		if ok && fk.Matches(current) {
+			continue
+		}
+		reason := fmt.Sprintf("foreign key %s is missing", fk.Name)
+		if ok {
+			reason = fmt.Sprintf("foreign key %s changed", fk.Name)
+			dropKeys = append(dropKeys, &define.SchemaChange{
+				Kind: define.ChangeDropForeignKey, Table: def.Name, ForeignKey: define.ForeignKeyDefOf(current), Reason: reason,
+			})
+		}
+		createKeys = append(createKeys, &define.SchemaChange{Kind: define.ChangeAddForeignKey, Table: def.Name, ForeignKey: fk, Reason: reason})
+	}
+	for i := range info.ForeignKeys {
+		current := &info.ForeignKeys[i]
+		if !declaredKeys[strings.ToLower(current.Name)] {
+			dropKeys = append(dropKeys, &define.SchemaChange{
+				Kind: define.ChangeDropForeignKey, Table: def.Name, ForeignKey: define.ForeignKeyDefOf(current), Destructive: true,
+				Reason: fmt.Sprintf("foreign key %s is not declared by the model", current.Name),
+			})
+		}
+	}
+
+	liveIndexes := make(map[string]*define.IndexInfo, len(info.Indexes))
+	for i := range info.Indexes {
+		liveIndexes[strings.ToLower(info.Indexes[i].Name)] = &info.Indexes[i]
+	}
+	declaredIndexes := make(map[string]bool, len(def.Indexes))
+	for _, index := range def.Indexes {
+		name := strings.ToLower(index.Name)
+		declaredIndexes[name] = true
+		current, ok := liveIndexes[name]
+		if ok && index.Matches(current) {
+			continue
+		}
+		reason := fmt.Sprintf("index %s is missing", index.Name)
+		if ok {
+			reason = fmt.Sprintf("index %s changed", index.Name)
+			dropIndexes = append(dropIndexes, &define.SchemaChange{
+				Kind: define.ChangeDropIndex, Table: def.Name, Index: define.IndexDefOf(current), Reason: reason,
+			})
+		}
+		createIndexes = append(createIndexes, &define.SchemaChange{Kind: define.ChangeCreateIndex, Table: def.Name, Index: index, Reason: reason})
+	}
+	for i := range info.Indexes {
+		current := &info.Indexes[i]
+		name := strings.ToLower(current.Name)
+		if declaredIndexes[name] || current.Primary || fkNames[name] || uniqueColumnIndex(def, current) {
+			continue
+		}
+		dropIndexes = append(dropIndexes, &define.SchemaChange{
+			Kind: define.ChangeDropIndex, Table: def.Name, Index: define.IndexDefOf(current), Destructive: true,
+			Reason: fmt.Sprintf("index %s is not declared by the model", current.Name),
+		})
+	}
+	return append(dropKeys, dropIndexes...), append(createIndexes, createKeys...)
+}
+
+// uniqueColumnIndex reports whether index is the unique index of a column declared unique
+func uniqueColumnIndex(def *define.TableDef, index *define.IndexInfo) bool {
+	if !index.Unique || len(index.Columns) != 1 {
+		return false
+	}
+	col := def.Column(index.Columns[0])
+	return col != nil && col.Unique
+}
+
+// structTypeOf returns the struct type of a model given as a struct or pointer to struct
+func structTypeOf(model interface{}) (reflect.Type, error) {
+	modelType := reflect.TypeOf(model)
+	if modelType != nil && modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	if modelType == nil || modelType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("model must be a struct or pointer to struct, got %T", model)
+	}
+	return modelType, nil
+}
+
+// allowed reports whether opts enable a destructive change
+func allowed(opts define.AutoMigrateOptions, change *define.SchemaChange) bool {
+	switch change.Kind {
+	case define.ChangeDropColumn:
+		return opts.AllowDropColumns
+	case define.ChangeDropIndex, define.ChangeDropForeignKey:
+		return opts.AllowDropIndexes
+	}
+	return opts.AllowTypeChanges
+}
+
+// liveType describes the type of a live column for plan reasons
+func liveType(col *define.ColumnInfo) string {
+	if col.Length > 0 && strings.Contains(strings.ToLower(col.TypeName), "char") {
+		return fmt.Sprintf("%s(%d)", col.TypeName, col.Length)
+	}
+	return col.TypeName
+}
+
+// invalidateTableInfo drops the cached table info of table
+func (db *DB) invalidateTableInfo(table string) {
+	db.tableInfoCacheMutex.Lock()
+	defer db.tableInfoCacheMutex.Unlock()
+	delete(db.tableInfoCache, table)
+	delete(db.tableExpireTime, table)
+}
@@ -0,0 +1,247 @@
+package gom
+
+import (
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/gomtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type migrateUser struct {
+	ID       int64   `gom:"id,@"`
+	Name     string  `gom:"name,notnull"`
+	Nickname *string `gom:"nickname"`
+	Age      int     `gom:"age"`
+}
+
+func (u *migrateUser) TableName() string { return "users" }
+
+func openMock(t *testing.T, driver string) (*DB, *gomtest.Mock) {
+	mock := gomtest.New()
+	t.Cleanup(mock.Close)
+	db, err := Open(driver, mock.DSN(), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, mock
+}
+
+// mysqlColumns scripts the information_schema.columns rows read by the MySQL factory
+func mysqlColumns(rows ...[]interface{}) *gomtest.Rows {
+	r := gomtest.NewRows("column_name", "data_type", "character_maximum_length", "numeric_precision",
+		"numeric_scale", "is_nullable", "column_key", "extra", "column_default", "column_comment", "column_type")
+	for _, row := range rows {
+		r.AddRow(row...)
+	}
+	return r
+}
+
+func TestPlanAutoMigrateCreatesMissingTable(t *testing.T) {
+	db, _ := openMock(t, gomtest.Postgres)
+	plan, err := db.PlanAutoMigrate(define.AutoMigrateOptions{}, &migrateUser{})
+	require.Error(t, err, "CURRENT_SCHEMA is not scripted")
+	assert.Nil(t, plan)
+
+	db, mock := openMock(t, gomtest.Postgres)
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	plan, err = db.PlanAutoMigrate(define.AutoMigrateOptions{}, &migrateUser{})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, define.ChangeCreateTable, plan.Changes[0].Kind)
+
+	sql, err := plan.SQL()
+	require.NoError(t, err)
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS "users" ("id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, "name" VARCHAR(255) NOT NULL, "nickname" VARCHAR(255), "age" INTEGER NOT NULL);`+"\n", sql)
+}
+
+func TestPlanAutoMigrateDiffsColumns(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("information_schema.columns").Return(mysqlColumns(
+		[]interface{}{"id", "bigint", nil, 19, 0, "NO", "PRI", "auto_increment", nil, "", "bigint"},
+		[]interface{}{"name", "varchar", 100, nil, nil, "YES", "", "", nil, "", "varchar(100)"},
+		[]interface{}{"nickname", "varchar", 255, nil, nil, "NO", "", "", nil, "", "varchar(255)"},
+		[]interface{}{"legacy", "text", 65535, nil, nil, "YES", "", "", nil, "", "text"},
+	))
+
+	plan, err := db.PlanAutoMigrate(define.AutoMigrateOptions{}, &migrateUser{})
+	require.NoError(t, err)
+
+	sql, err := plan.SQL()
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE `users` MODIFY COLUMN `nickname` VARCHAR(255);\n"+
+		"ALTER TABLE `users` ADD COLUMN `age` INTEGER NOT NULL;\n"+
+		"-- skipped alter_column on users: column name: type varchar(100) -> VARCHAR(255), NULL -> NOT NULL\n"+
+		"-- skipped drop_column on users: column legacy has no model field\n", sql)
+
+	plan, err = db.PlanAutoMigrate(define.AutoMigrateOptions{AllowDropColumns: true, AllowTypeChanges: true}, &migrateUser{})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Skipped)
+	assert.Len(t, plan.Changes, 4)
+}
+
+func TestApplyMigrationPlanPostgres(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	mock.On("FROM pg_attribute").Return(gomtest.NewRows("column_name", "data_type", "max_length", "type_modifier",
+		"not_null", "default_value", "column_comment", "identity", "is_primary", "column_type").
+		AddRow("id", "int8", 8, -1, true, "nextval('users_id_seq'::regclass)", nil, "", true, "bigint").
+		AddRow("name", "varchar", -1, 259, true, nil, nil, "", false, "character varying(255)").
+		AddRow("nickname", "text", -1, -1, false, nil, nil, "", false, "text"))
+
+	require.NoError(t, db.AutoMigrate(&migrateUser{}))
+	mock.AssertStatementsInOrder(t,
+		"BEGIN",
+		`ALTER TABLE "users" ADD COLUMN "age" INTEGER NOT NULL`,
+		"COMMIT",
+	)
+	mock.AssertNotExecuted(t, `ALTER COLUMN "nickname"`)
+
+	mock.Reset()
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	mock.On("FROM pg_attribute").Return(gomtest.NewRows("column_name", "data_type", "max_length", "type_modifier",
+		"not_null", "default_value", "column_comment", "identity", "is_primary", "column_type").
+		AddRow("id", "int8", 8, -1, true, nil, nil, "a", true, "bigint").
+		AddRow("name", "varchar", -1, 259, true, nil, nil, "", false, "character varying(255)").
+		AddRow("nickname", "text", -1, -1, true, nil, nil, "", false, "text").
+		AddRow("age", "int4", 4, -1, true, nil, nil, "", false, "integer"))
+
+	plan, err := db.PlanAutoMigrate(define.AutoMigrateOptions{AllowTypeChanges: true}, &migrateUser{})
+	require.NoError(t, err)
+	sql, err := plan.SQL()
+	require.NoError(t, err)
+	assert.Equal(t, `ALTER TABLE "users" ALTER COLUMN "nickname" TYPE VARCHAR(255) USING "nickname"::VARCHAR(255);`+"\n"+
+		`ALTER TABLE "users" ALTER COLUMN "nickname" DROP NOT NULL;`+"\n", sql)
+}
+
+type migrateOrder struct {
+	ID     int64  `gom:"id,@"`
+	UserID int64  `gom:"user_id,index:idx_user_status,references:users(id),onDelete:cascade"`
+	Status string `gom:"status,index:idx_user_status,priority:1,comment:'order state'"`
+}
+
+func (o *migrateOrder) TableName() string { return "orders" }
+
+func TestCreateTableDeclaresIndexesMySQL(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	require.NoError(t, db.Chain().CreateTable(&migrateOrder{}))
+	mock.AssertStatements(t, "CREATE TABLE IF NOT EXISTS `orders` (\n"+
+		"`id` BIGINT AUTO_INCREMENT PRIMARY KEY,\n"+
+		"`user_id` BIGINT NOT NULL,\n"+
+		"`status` VARCHAR(255) NOT NULL COMMENT 'order state',\n"+
+		"INDEX `idx_user_status` (`status`, `user_id`),\n"+
+		"CONSTRAINT `fk_orders_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE\n)")
+}
+
+func TestPlanAutoMigrateAddsIndexOnNewColumns(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("information_schema.columns").Return(mysqlColumns(
+		[]interface{}{"id", "bigint", nil, 19, 0, "NO", "PRI", "auto_increment", nil, "", "bigint"},
+		[]interface{}{"status", "varchar", 255, nil, nil, "NO", "", "", nil, "", "varchar(255)"},
+	))
+
+	plan, err := db.PlanAutoMigrate(define.AutoMigrateOptions{}, &migrateOrder{})
+	require.NoError(t, err)
+	sql, err := plan.SQL()
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE `orders` ADD COLUMN `user_id` BIGINT NOT NULL;\n"+
+		"CREATE INDEX `idx_user_status` ON `orders` (`status`, `user_id`);\n"+
+		"ALTER TABLE `orders` ADD CONSTRAINT `fk_orders_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;\n", sql)
+}
+
+type migrateAccount struct {
+	ID     int64  `gom:"id,@"`
+	UserID int64  `gom:"user_id,index:idx_user_status,references:users(id),onDelete:cascade"`
+	Status string `gom:"status,index:idx_user_status,priority:1"`
+	Email  string `gom:"email,unique"`
+}
+
+func (a *migrateAccount) TableName() string { return "accounts" }
+
+func TestPlanAutoMigrateComparesIndexesAndForeignKeys(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("information_schema.columns").Return(mysqlColumns(
+		[]interface{}{"id", "bigint", nil, 19, 0, "NO", "PRI", "auto_increment", nil, "", "bigint"},
+		[]interface{}{"user_id", "bigint", nil, 19, 0, "NO", "MUL", "", nil, "", "bigint"},
+		[]interface{}{"status", "varchar", 255, nil, nil, "NO", "", "", nil, "", "varchar(255)"},
+		[]interface{}{"email", "varchar", 255, nil, nil, "NO", "UNI", "", nil, "", "varchar(255)"},
+	))
+	mock.On("information_schema.statistics").Return(gomtest.NewRows("index_name", "column_name", "non_unique", "index_type").
+		AddRow("PRIMARY", "id", 0, "BTREE").
+		AddRow("email", "email", 0, "BTREE").
+		AddRow("fk_accounts_user_id", "user_id", 1, "BTREE").
+		AddRow("idx_legacy", "status", 1, "BTREE").
+		AddRow("idx_user_status", "user_id", 1, "BTREE").
+		AddRow("idx_user_status", "status", 1, "BTREE"))
+	mock.On("information_schema.key_column_usage").Return(gomtest.NewRows("constraint_name", "column_name", "referenced_table_schema",
+		"referenced_table_name", "referenced_column_name", "same_schema", "update_rule", "delete_rule").
+		AddRow("fk_accounts_user_id", "user_id", "shop", "users", "id", true, "NO ACTION", "RESTRICT").
+		AddRow("fk_accounts_team_id", "team_id", "shop", "teams", "id", true, "NO ACTION", "NO ACTION"))
+
+	plan, err := db.PlanAutoMigrate(define.AutoMigrateOptions{}, &migrateAccount{})
+	require.NoError(t, err)
+	sql, err := plan.SQL()
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE `accounts` DROP FOREIGN KEY `fk_accounts_user_id`;\n"+
+		"DROP INDEX `idx_user_status` ON `accounts`;\n"+
+		"CREATE INDEX `idx_user_status` ON `accounts` (`status`, `user_id`);\n"+
+		"ALTER TABLE `accounts` ADD CONSTRAINT `fk_accounts_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;\n"+
+		"-- skipped drop_foreign_key on accounts: foreign key fk_accounts_team_id is not declared by the model\n"+
+		"-- skipped drop_index on accounts: index idx_legacy is not declared by the model\n", sql)
+
+	plan, err = db.PlanAutoMigrate(define.AutoMigrateOptions{AllowDropIndexes: true}, &migrateAccount{})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Skipped)
+	sql, err = plan.SQL()
+	require.NoError(t, err)
+	assert.Contains(t, sql, "ALTER TABLE `accounts` DROP FOREIGN KEY `fk_accounts_team_id`;\n")
+	assert.Contains(t, sql, "DROP INDEX `idx_legacy` ON `accounts`;\n")
+}
+
+type migrateProduct struct {
+	ID      int64          `gom:"id,@"`
+	Name    string         `gom:"name,size:64,default:''"`
+	Price   float64        `gom:"price,precision:10,scale:2"`
+	Status  string         `gom:"status,enum:draft|published,default:'draft'"`
+	Attrs   map[string]int `gom:"attrs,null"`
+	Body    string         `gom:"body,size:70000"`
+	Payload []byte         `gom:"payload,size:512"`
+}
+
+func (p *migrateProduct) TableName() string { return "products" }
+
+func (p *migrateProduct) TableOptions() define.TableOptions {
+	return define.TableOptions{Engine: "InnoDB", Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci", Comment: "products"}
+}
+
+func TestCreateTableColumnTypesMySQL(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	require.NoError(t, db.Chain().CreateTable(&migrateProduct{}))
+	mock.AssertStatements(t, "CREATE TABLE IF NOT EXISTS `products` (\n"+
+		"`id` BIGINT AUTO_INCREMENT PRIMARY KEY,\n"+
+		"`name` VARCHAR(64) NOT NULL DEFAULT '',\n"+
+		"`price` DECIMAL(10,2) NOT NULL,\n"+
+		"`status` ENUM('draft','published') NOT NULL DEFAULT 'draft',\n"+
+		"`attrs` JSON,\n"+
+		"`body` MEDIUMTEXT NOT NULL,\n"+
+		"`payload` VARBINARY(512) NOT NULL\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='products'")
+}
+
+func TestPlanCreateTables(t *testing.T) {
+	factory, err := define.GetFactory("postgres")
+	require.NoError(t, err)
+	plan, err := PlanCreateTables(factory, &migrateUser{}, &migrateOrder{})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	assert.Equal(t, "users", plan.Changes[0].Table)
+	assert.Equal(t, "orders", plan.Changes[1].Table)
+
+	sql, err := plan.SQL()
+	require.NoError(t, err)
+	assert.Contains(t, sql, `CREATE TABLE IF NOT EXISTS "users" ("id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY`)
+	assert.Contains(t, sql, `COMMENT ON COLUMN "orders"."status" IS 'order state';`)
+
+	_, err = PlanCreateTables(factory, "users")
+	assert.Error(t, err)
+}
@@ -11,9 +11,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -52,6 +52,7 @@ type Chain struct {
 	// Transaction state tracking
 	inTransaction bool
 	txError       error
+	txID          uint64
 
 	// Model for potential ID callback
 	model interface{}
@@ -88,6 +89,12 @@ type Chain struct {
 	rawSQL string
 	args   []interface{}
 	err    error
+
+	// Result cache TTL, 0 disables caching
+	cacheTTL time.Duration
+
+	// Records statements instead of executing them when set
+	dryRun *dryRunRecorder
 }
 
 // SensitiveType defines the type of sensitive data
@@ -491,9 +498,8 @@ func (c *Chain) From(model interface{}) *Chain {
 		c.tableName = tableName
 		return c
 	}
-	if c.tableName == "" && model.(define.ITableModel).TableName() != "" {
-		c.tableName = model.(define.ITableModel).TableName()
-
+	if tabler, ok := model.(interface{ TableName() string }); ok && c.tableName == "" {
+		c.tableName = tabler.TableName()
 	}
 
 	// Get model type and value
@@ -515,7 +521,7 @@ func (c *Chain) From(model interface{}) *Chain {
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
 		tag := field.Tag.Get("gom")
-		if tag == "" {
+		if tag == "" || tag == "-" {
 			continue
 		}
 
@@ -618,6 +624,9 @@ func (c *Chain) list() *define.Result {
 		return &define.Result{Error: sqlProto.Error}
 	}
 
+	if c.cacheEnabled() {
+		return c.executeCached(sqlProto)
+	}
 	return c.executeSqlProto(sqlProto)
 }
 
@@ -828,20 +837,16 @@ func (c *Chain) deleteMultipleModels(models []interface{}) *define.Result {
 		return c.executeMultipleDeletes(models)
 	}
 
-	// Start new transaction
-	tx, err := c.db.DB.Begin()
+	// Start new transaction and create new Chain with it
+	txChain, err := c.beginTx()
 	if err != nil {
 		return &define.Result{Error: fmt.Errorf("failed to begin transaction: %v", err)}
 	}
 
-	// Create new Chain with transaction
-	txChain := c.clone()
-	txChain.tx = tx
-
 	result := txChain.executeMultipleDeletes(models)
 	if result.Error != nil {
 		// Rollback on error
-		rollbackErr := tx.Rollback()
+		rollbackErr := txChain.rollback()
 		if rollbackErr != nil {
 			return &define.Result{Error: fmt.Errorf("delete failed: %v, rollback failed: %v", result.Error, rollbackErr)}
 		}
@@ -849,7 +854,7 @@ func (c *Chain) deleteMultipleModels(models []interface{}) *define.Result {
 	}
 
 	// Commit transaction
-	if err := tx.Commit(); err != nil {
+	if err := txChain.commit(); err != nil {
 		return &define.Result{Error: fmt.Errorf("failed to commit transaction: %v", err)}
 	}
 
@@ -925,6 +930,29 @@ func (c *Chain) Page(pageNum, pageSize int) *Chain {
 	return c
 }
 
+// ListOptions filters, orders and pages a query, e.g. the List method of generated repositories
+type ListOptions struct {
+	Where   []*define.Condition
+	OrderBy []define.OrderBy
+	Limit   int
+	Offset  int
+}
+
+// ApplyListOptions adds the conditions, ordering and paging of opts to the chain
+func (c *Chain) ApplyListOptions(opts ListOptions) *Chain {
+	for _, cond := range opts.Where {
+		c.Where2(cond)
+	}
+	c.orderByExprs = append(c.orderByExprs, opts.OrderBy...)
+	if opts.Limit > 0 {
+		c.limitCount = opts.Limit
+	}
+	if opts.Offset > 0 {
+		c.offsetCount = opts.Offset
+	}
+	return c
+}
+
 // Into scans the result into a struct or slice of structs
 func (c *Chain) Into(dest interface{}) error {
 	result := c.List()
@@ -936,17 +964,15 @@ func (c *Chain) Into(dest interface{}) error {
 
 // RawQuery executes a raw SQL query
 func (c *Chain) RawQuery(sqlStr string, args ...interface{}) *define.Result {
-	if define.Debug {
-		log.Printf("[SQL] %s %v", sqlStr, args)
-	}
+	proto := &define.SqlProto{SqlType: define.Query, Sql: sqlStr, Args: args}
+	return c.execute(proto, func(ctx context.Context, p *define.SqlProto) *define.Result {
+		return c.rawQuery(ctx, p.Sql, p.Args)
+	})
+}
 
-	var rows *sql.Rows
-	var err error
-	if c.tx != nil {
-		rows, err = c.tx.Query(sqlStr, args...)
-	} else {
-		rows, err = c.db.DB.Query(sqlStr, args...)
-	}
+// rawQuery is the internal implementation of RawQuery
+func (c *Chain) rawQuery(ctx context.Context, sqlStr string, args []interface{}) *define.Result {
+	rows, err := c.queryContext(ctx, sqlStr, args)
 	if err != nil {
 		return &define.Result{Error: err}
 	}
@@ -998,28 +1024,24 @@ func (c *Chain) RawQuery(sqlStr string, args ...interface{}) *define.Result {
 
 // RawExecute executes a raw SQL query
 func (c *Chain) RawExecute(sql string, args ...interface{}) define.Result {
-	if define.Debug {
-		log.Printf("[SQL] %s %v", sql, args)
-	}
-	var sqlResult interface {
-		LastInsertId() (int64, error)
-		RowsAffected() (int64, error)
-	}
-	var err error
-	if c.tx != nil {
-		sqlResult, err = c.tx.Exec(sql, args...)
-	} else {
-		sqlResult, err = c.db.DB.Exec(sql, args...)
-	}
+	proto := &define.SqlProto{SqlType: define.Exec, Sql: sql, Args: args}
+	return *c.execute(proto, func(ctx context.Context, p *define.SqlProto) *define.Result {
+		return c.rawExecute(ctx, p.Sql, p.Args)
+	})
+}
+
+// rawExecute is the internal implementation of RawExecute
+func (c *Chain) rawExecute(ctx context.Context, sql string, args []interface{}) *define.Result {
+	sqlResult, err := c.execContext(ctx, sql, args)
 	if err != nil {
-		return define.Result{Error: err}
+		return &define.Result{Error: err}
 	}
 	lastID, _ := sqlResult.LastInsertId()
 	affected, err := sqlResult.RowsAffected()
 	if err != nil {
-		return define.Result{Error: err}
+		return &define.Result{Error: err}
 	}
-	return define.Result{ID: lastID, Affected: affected}
+	return &define.Result{ID: lastID, Affected: affected}
 }
 
 // setFieldValue handles type conversion and setting of field values
@@ -1105,10 +1127,10 @@ func setFieldValue(field reflect.Value, value interface{}) error {
 	return nil
 }
 
-// getTableNameFromStruct derives table name from struct name or ITableModel interface
+// getTableNameFromStruct derives table name from struct name or the model's TableName method
 func getTableNameFromStruct(t reflect.Type, model interface{}) string {
-	// Check if model implements ITableModel interface
-	if tableModel, ok := model.(define.ITableModel); ok {
+	// Check if model implements TableName, as ITableModel does
+	if tableModel, ok := model.(interface{ TableName() string }); ok {
 		if tableName := tableModel.TableName(); tableName != "" {
 			return tableName
 		}
@@ -1162,9 +1184,6 @@ func (c *Chain) CreateTable(model interface{}) error {
 	// Check if model implements ITableModel interface
 	if tableModel, ok := model.(define.ITableModel); ok {
 		if createSql := tableModel.CreateSql(); createSql != "" {
-			if define.Debug {
-				log.Printf("[SQL] %s\n", createSql)
-			}
 			sqlProto := &define.SqlProto{
 				SqlType: define.Exec,
 				Sql:     createSql,
@@ -1179,10 +1198,19 @@ func (c *Chain) CreateTable(model interface{}) error {
 		}
 	}
 
-	// Build and execute CREATE TABLE statement using default logic
-	sqlProto := c.factory.BuildCreateTable(c.tableName, modelType)
-	result := c.executeSqlProto(sqlProto)
-	return result.Error
+	// Build and execute CREATE TABLE statement using default logic, followed by
+	// the indexes and comments the dialect cannot declare inline
+	change := &define.SchemaChange{
+		Kind:     define.ChangeCreateTable,
+		Table:    c.tableName,
+		TableDef: c.factory.DescribeModel(c.tableName, modelType),
+	}
+	for _, sqlProto := range c.factory.BuildSchemaChange(change) {
+		if result := c.executeSqlProto(sqlProto); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
 }
 
 // Begin starts a new transaction
@@ -1196,7 +1224,7 @@ func (c *Chain) BeginChain() (*Chain, error) {
 		return nil, errors.New("transaction already started")
 	}
 
-	tx, err := c.db.DB.Begin()
+	tx, txID, ctx, err := c.db.beginTx(c.getContext(), nil, true)
 	if err != nil {
 		return nil, err
 	}
@@ -1204,7 +1232,9 @@ func (c *Chain) BeginChain() (*Chain, error) {
 	return &Chain{
 		db:             c.db,
 		factory:        c.factory,
+		ctx:            ctx,
 		tx:             tx,
+		txID:           txID,
 		originalChain:  c,
 		isolationLevel: c.isolationLevel,
 		inTransaction:  true,
@@ -1216,7 +1246,7 @@ func (c *Chain) Commit() error {
 	if !c.inTransaction || c.tx == nil {
 		return errors.New("not in transaction")
 	}
-	return c.tx.Commit()
+	return c.commit()
 }
 
 // Rollback rolls back the current transaction
@@ -1224,7 +1254,7 @@ func (c *Chain) Rollback() error {
 	if !c.inTransaction || c.tx == nil {
 		return errors.New("not in transaction")
 	}
-	return c.tx.Rollback()
+	return c.rollback()
 }
 
 // Count returns the count of records matching the current conditions
@@ -1238,9 +1268,13 @@ func (c *Chain) Count2(field string) (int64, error) {
 		db:        c.db,
 		factory:   c.factory,
 		tx:        c.tx,
+		txID:      c.txID,
 		tableName: c.tableName,
 		conds:     c.conds,
 		fieldList: []string{fmt.Sprintf("COUNT(%s) as count", field)},
+		ctx:       c.ctx,
+		cacheTTL:  c.cacheTTL,
+		dryRun:    c.dryRun,
 	}
 
 	result := countChain.list()
@@ -1307,6 +1341,7 @@ func (c *Chain) Sum(field string) (float64, error) {
 		db:        c.db,
 		factory:   c.factory,
 		tx:        c.tx,
+		txID:      c.txID,
 		tableName: c.tableName,
 		conds:     c.conds,
 		fieldList: []string{fmt.Sprintf("SUM(%s) as sum_value", field)},
@@ -1400,6 +1435,7 @@ func (c *Chain) Transaction(fn func(tx *Chain) error) error {
 			db:            c.db,
 			factory:       c.factory,
 			tx:            c.tx,
+			txID:          c.txID,
 			inTransaction: true,
 		}
 
@@ -1420,7 +1456,7 @@ func (c *Chain) Transaction(fn func(tx *Chain) error) error {
 	}
 
 	// Start a new transaction if not already in one
-	tx, err := c.db.Begin()
+	tx, txID, ctx, err := c.db.beginTx(c.getContext(), nil, true)
 	if err != nil {
 		return err
 	}
@@ -1429,19 +1465,21 @@ func (c *Chain) Transaction(fn func(tx *Chain) error) error {
 	txChain := &Chain{
 		db:            c.db,
 		factory:       c.factory,
+		ctx:           ctx,
 		tx:            tx,
+		txID:          txID,
 		inTransaction: true,
 	}
 
 	err = fn(txChain)
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
+		if rbErr := txChain.rollback(); rbErr != nil {
 			return fmt.Errorf("error rolling back: %v (original error: %v)", rbErr, err)
 		}
 		return err
 	}
 
-	return tx.Commit()
+	return txChain.commit()
 }
 
 // IsInTransaction returns whether the chain is currently in a transaction
@@ -1608,6 +1646,9 @@ func (c *Chain) BatchInsert(batchSize int, enableConcurrent bool) (int64, error)
 			Err: fmt.Errorf("invalid batch size: %d (must be > 0)", batchSize),
 		}
 	}
+	if err := checkWritable("insert", c.model); err != nil {
+		return 0, &define.DBError{Op: "BatchInsert", Err: err}
+	}
 
 	// 数据校验
 	if len(c.batchValues) == 0 {
@@ -1619,14 +1660,22 @@ func (c *Chain) BatchInsert(batchSize int, enableConcurrent bool) (int64, error)
 
 	// 并发控制逻辑
 	numGoroutines := 1
+	if c.dryRun != nil || c.db.conn != nil {
+		// 预演模式按顺序记录语句；单连接上无法并发执行事务
+		enableConcurrent = false
+	}
 	if enableConcurrent {
 		// 智能计算并发数
 		numGoroutines = calculateOptimalGoroutines(len(c.batchValues), batchSize)
 		// 限制最大并发数
 		numGoroutines = min(numGoroutines, 8)
-		// 连接池检查
-		if c.db.Stats().OpenConnections < numGoroutines {
-			c.db.SetMaxOpenConns(numGoroutines + 2)
+		// 连接池检查：只调整自己管理的连接池，调用方的连接池按其上限限制并发数
+		if limit := c.db.Stats().MaxOpenConnections; limit > 0 && limit < numGoroutines+2 {
+			if c.db.ownsPool {
+				c.db.SetMaxOpenConns(numGoroutines + 2)
+			} else {
+				numGoroutines = limit
+			}
 		}
 	}
 
@@ -1647,15 +1696,17 @@ func (c *Chain) BatchInsert(batchSize int, enableConcurrent bool) (int64, error)
 		processBatch = c.sequentialProcessBatch
 	}
 
-	// 执行批处理
+	// 执行批处理，每个批次在独立的子 span 中执行
+	ctx, span := c.traceBatch(ctx, "insert", enableConcurrent)
 	result := processBatchesWithTimeout(
 		ctx,
 		c.batchValues,
 		batchSize,
 		numGoroutines,
 		60*time.Second,
-		processBatch,
+		c.traceChunks(c.retryChunks("batch_insert", processBatch)),
 	)
+	endSpan(span, result)
 
 	// 错误处理
 	if result.Error != nil {
@@ -1687,23 +1738,20 @@ func (c *Chain) sequentialProcessBatch(ctx context.Context, batch []map[string]i
 		return &define.Result{Error: err}
 	}
 
-	tx, err := c.db.Begin()
+	// 使用context创建一个带事务的链式对象执行SQL
+	chainWithContext, err := c.beginTxContext(ctx)
 	if err != nil {
 		return &define.Result{Error: err}
 	}
 
-	// 使用context创建一个新的链式对象执行SQL
-	chainWithContext := c.clone().SetContext(ctx)
-	chainWithContext.tx = tx // 设置事务
-
 	sqlProto := c.factory.BuildBatchInsert(c.tableName, batch)
 	result := chainWithContext.executeSqlProto(sqlProto)
 	if result.Error != nil {
-		tx.Rollback()
+		chainWithContext.rollback()
 		return result
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := chainWithContext.commit(); err != nil {
 		return &define.Result{Error: err}
 	}
 
@@ -1717,23 +1765,20 @@ func (c *Chain) concurrentProcessBatch(ctx context.Context, batch []map[string]i
 		return &define.Result{Error: err}
 	}
 
-	tx, err := c.db.Begin()
+	// 使用context创建一个带事务的链式对象执行SQL
+	chainWithContext, err := c.beginTxContext(ctx)
 	if err != nil {
 		return &define.Result{Error: err}
 	}
 
-	// 使用context创建一个新的链式对象执行SQL
-	chainWithContext := c.clone().SetContext(ctx)
-	chainWithContext.tx = tx // 设置事务
-
 	sqlProto := chainWithContext.factory.BuildBatchInsert(chainWithContext.tableName, batch)
 	result := chainWithContext.executeSqlProto(sqlProto)
 	if result.Error != nil {
-		tx.Rollback()
+		chainWithContext.rollback()
 		return result
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := chainWithContext.commit(); err != nil {
 		return &define.Result{Error: err}
 	}
 
@@ -1854,6 +1899,7 @@ func (c *Chain) clone() *Chain {
 		db:              c.db,
 		factory:         c.factory,
 		tx:              c.tx,
+		txID:            c.txID,
 		tableName:       c.tableName,
 		conds:           c.conds,
 		fieldList:       c.fieldList,
@@ -1866,17 +1912,19 @@ func (c *Chain) clone() *Chain {
 		isolationLevel:  c.isolationLevel,
 		sensitiveFields: c.sensitiveFields,
 		ctx:             c.ctx,
+		cacheTTL:        c.cacheTTL,
+		dryRun:          c.dryRun,
 	}
 }
 
 // Begin starts a new transaction
 func (db *DB) Begin() (*sql.Tx, error) {
-	return db.DB.Begin()
+	return db.executor().BeginTx(context.Background(), nil)
 }
 
 // BeginChain starts a new transaction and returns a Chain
 func (db *DB) BeginChain() (*Chain, error) {
-	tx, err := db.DB.Begin()
+	tx, txID, ctx, err := db.beginTx(context.Background(), nil, true)
 	if err != nil {
 		return nil, err
 	}
@@ -1884,7 +1932,9 @@ func (db *DB) BeginChain() (*Chain, error) {
 	return &Chain{
 		db:            db,
 		factory:       db.Factory,
+		ctx:           ctx,
 		tx:            tx,
+		txID:          txID,
 		inTransaction: true,
 	}, nil
 }
@@ -1897,6 +1947,9 @@ func (c *Chain) BatchUpdate(batchSize int) (int64, error) {
 			Err: fmt.Errorf("invalid batch size (must be greater than 0, got %d)", batchSize),
 		}
 	}
+	if err := checkWritable("update", c.model); err != nil {
+		return 0, &DBError{Op: "BatchUpdate", Err: err}
+	}
 
 	if len(c.batchValues) == 0 {
 		return 0, &DBError{
@@ -1922,14 +1975,20 @@ func (c *Chain) BatchUpdate(batchSize int) (int64, error) {
 			return &define.Result{Error: err}
 		}
 
-		tx, err := c.db.Begin()
+		txChain, err := c.beginTxContext(ctx)
 		if err != nil {
 			return &define.Result{Error: fmt.Errorf("failed to start transaction: %w", err)}
 		}
-		defer tx.Rollback()
+		ctx = txChain.getContext()
+		committed := false
+		defer func() {
+			if !committed {
+				txChain.rollback()
+			}
+		}()
 
-		txChain := c.clone().SetContext(ctx)
-		txChain.tx = tx
+		// 只统计提交成功的批次，重试的批次不会重复计数
+		var affected int64
 
 		for _, item := range batch {
 			// 检查每个项处理前是否已取消
@@ -1961,24 +2020,28 @@ func (c *Chain) BatchUpdate(batchSize int) (int64, error) {
 				return result
 			}
 
-			progress.increment(result.Affected)
+			affected += result.Affected
 		}
 
-		if err := tx.Commit(); err != nil {
+		committed = true
+		if err := txChain.commit(); err != nil {
 			return &define.Result{Error: fmt.Errorf("failed to commit transaction: %w", err)}
 		}
+		progress.increment(affected)
 
 		return &define.Result{Error: nil}
 	}
 
+	ctx, span := c.traceBatch(ctx, "update", true)
 	result := processBatchesWithTimeout(
 		ctx,
 		c.batchValues,
 		batchSize,
-		4, // Use 4 concurrent goroutines
+		c.db.batchConcurrency(4), // Use 4 concurrent goroutines
 		30*time.Second,
-		processBatch,
+		c.traceChunks(c.retryChunks("batch_update", processBatch)),
 	)
+	endSpan(span, result)
 
 	if result.Error != nil {
 		// 区分上下文取消和其他错误
@@ -2011,14 +2074,18 @@ func (c *Chain) BatchDelete(batchSize int) (int64, error) {
 			Err: fmt.Errorf("invalid batch size (must be greater than 0, got %d)", batchSize),
 		}
 	}
+	if err := checkWritable("delete", c.model); err != nil {
+		return 0, &DBError{Op: "BatchDelete", Err: err}
+	}
 
 	// If batchValues is empty but we have conditions, use conditions for delete
 	if len(c.batchValues) == 0 && len(c.conds) > 0 {
 		sqlProto := c.factory.BuildDelete(c.tableName, c.conds)
 		result := c.executeSqlProto(sqlProto)
-		affected := result.Affected
-
-		return affected, nil
+		if result.Error != nil {
+			return 0, c.queryError("BatchDelete", result.Error, sqlProto.Sql)
+		}
+		return result.Affected, nil
 	}
 
 	if len(c.batchValues) == 0 {
@@ -2045,14 +2112,20 @@ func (c *Chain) BatchDelete(batchSize int) (int64, error) {
 			return &define.Result{Error: err}
 		}
 
-		tx, err := c.db.Begin()
+		txChain, err := c.beginTxContext(ctx)
 		if err != nil {
 			return &define.Result{Error: fmt.Errorf("failed to start transaction: %w", err)}
 		}
-		defer tx.Rollback()
+		ctx = txChain.getContext()
+		committed := false
+		defer func() {
+			if !committed {
+				txChain.rollback()
+			}
+		}()
 
-		txChain := c.clone().SetContext(ctx)
-		txChain.tx = tx
+		// 只统计提交成功的批次，重试的批次不会重复计数
+		var affected int64
 
 		for _, item := range batch {
 			// 检查每个项处理前是否已取消
@@ -2085,24 +2158,28 @@ func (c *Chain) BatchDelete(batchSize int) (int64, error) {
 				return result
 			}
 
-			progress.increment(result.Affected)
+			affected += result.Affected
 		}
 
-		if err := tx.Commit(); err != nil {
+		committed = true
+		if err := txChain.commit(); err != nil {
 			return &define.Result{Error: fmt.Errorf("failed to commit transaction: %w", err)}
 		}
+		progress.increment(affected)
 
 		return &define.Result{Error: nil}
 	}
 
+	ctx, span := c.traceBatch(ctx, "delete", true)
 	result := processBatchesWithTimeout(
 		ctx,
 		c.batchValues,
 		batchSize,
-		4, // Use 4 concurrent goroutines
+		c.db.batchConcurrency(4), // Use 4 concurrent goroutines
 		30*time.Second,
-		processBatch,
+		c.traceChunks(c.retryChunks("batch_delete", processBatch)),
 	)
+	endSpan(span, result)
 
 	if result.Error != nil {
 		// 区分上下文取消和其他错误
@@ -2223,7 +2300,7 @@ func (c *Chain) TransactionWithOptions(opts define.TransactionOptions, fn func(t
 	}
 
 	// Start new transaction with timeout
-	ctx := context.Background()
+	ctx := c.getContext()
 	var cancel context.CancelFunc
 	if opts.Timeout > 0 {
 		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
@@ -2231,10 +2308,10 @@ func (c *Chain) TransactionWithOptions(opts define.TransactionOptions, fn func(t
 	}
 
 	// Begin transaction
-	tx, err := c.db.DB.BeginTx(ctx, &sql.TxOptions{
+	tx, txID, ctx, err := c.db.beginTx(ctx, &sql.TxOptions{
 		Isolation: sql.IsolationLevel(opts.IsolationLevel),
 		ReadOnly:  opts.ReadOnly,
-	})
+	}, false)
 	if err != nil {
 		return &DBError{
 			Op:  "TransactionWithOptions",
@@ -2246,6 +2323,7 @@ func (c *Chain) TransactionWithOptions(opts define.TransactionOptions, fn func(t
 		db:             c.db,
 		factory:        c.factory,
 		tx:             tx,
+		txID:           txID,
 		originalChain:  c,
 		isolationLevel: sql.IsolationLevel(opts.IsolationLevel),
 		inTransaction:  true,
@@ -2254,13 +2332,13 @@ func (c *Chain) TransactionWithOptions(opts define.TransactionOptions, fn func(t
 
 	err = fn(newChain)
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
+		if rbErr := newChain.rollback(); rbErr != nil {
 			return fmt.Errorf("error rolling back: %v (original error: %v)", rbErr, err)
 		}
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := newChain.commit(); err != nil {
 		return &DBError{
 			Op:  "TransactionWithOptions",
 			Err: fmt.Errorf("failed to commit transaction: %w", err),
@@ -2570,10 +2648,6 @@ func (c *Chain) endQueryStats(rowsAffected int64) {
 	if c.queryStats != nil {
 		c.queryStats.Duration = time.Since(c.queryStats.StartTime)
 		c.queryStats.RowsAffected = rowsAffected
-		if define.Debug {
-			log.Printf("[QueryStats] SQL: %s, Duration: %v, RowsAffected: %d",
-				c.queryStats.SQL, c.queryStats.Duration, c.queryStats.RowsAffected)
-		}
 	}
 }
 
@@ -2679,6 +2753,9 @@ func (c *Chain) BatchInsert2(models []interface{}, batchSize int, enableConcurre
 			Err: errors.New("no models to insert"),
 		}
 	}
+	if err := checkWritable("insert", models[0]); err != nil {
+		return 0, &define.DBError{Op: "BatchInsert2", Err: err}
+	}
 
 	if batchSize <= 0 {
 		return 0, &define.DBError{
@@ -2857,18 +2934,21 @@ func (c *Chain) Exec() *define.Result {
 		return &define.Result{Error: errors.New("raw SQL is empty")}
 	}
 
-	result, err := c.db.DB.Exec(c.rawSQL, c.args...)
-	if err != nil {
-		return &define.Result{Error: err}
-	}
+	proto := &define.SqlProto{SqlType: define.Exec, Sql: c.rawSQL, Args: c.args}
+	return c.execute(proto, func(ctx context.Context, p *define.SqlProto) *define.Result {
+		result, err := c.db.executor().ExecContext(ctx, p.Sql, p.Args...)
+		if err != nil {
+			return &define.Result{Error: err}
+		}
 
-	lastInsertID, _ := result.LastInsertId()
-	rowsAffected, _ := result.RowsAffected()
+		lastInsertID, _ := result.LastInsertId()
+		rowsAffected, _ := result.RowsAffected()
 
-	return &define.Result{
-		ID:       lastInsertID,
-		Affected: rowsAffected,
-	}
+		return &define.Result{
+			ID:       lastInsertID,
+			Affected: rowsAffected,
+		}
+	})
 }
 
 // Query 执行原始 SQL 查询并返回结果集
@@ -2877,22 +2957,14 @@ func (c *Chain) Query() *define.Result {
 		return &define.Result{Error: errors.New("raw SQL is empty")}
 	}
 
+	proto := &define.SqlProto{SqlType: define.Query, Sql: c.rawSQL, Args: c.args}
+	return c.execute(proto, c.query)
+}
+
+// query is the internal implementation of Query
+func (c *Chain) query(ctx context.Context, proto *define.SqlProto) *define.Result {
 	// 执行查询
-	var rows *sql.Rows
-	var err error
-	if c.tx != nil {
-		if c.ctx == nil {
-			rows, err = c.tx.Query(c.rawSQL, c.args...)
-		} else {
-			rows, err = c.tx.QueryContext(c.ctx, c.rawSQL, c.args...)
-		}
-	} else {
-		if c.ctx == nil {
-			rows, err = c.db.DB.Query(c.rawSQL, c.args...)
-		} else {
-			rows, err = c.db.DB.QueryContext(c.ctx, c.rawSQL, c.args...)
-		}
-	}
+	rows, err := c.queryContext(ctx, proto.Sql, proto.Args)
 
 	if err != nil {
 		return &define.Result{Error: err}
@@ -2957,13 +3029,31 @@ func (c *Chain) Sets(fields map[string]interface{}) *Chain {
 	for k, v := range fields {
 		c.fieldMap[k] = v
 	}
-	c.fieldOrder = make([]string, 0, len(c.fieldMap))
-	for field := range c.fieldMap {
-		c.fieldOrder = append(c.fieldOrder, field)
-	}
+	c.fieldOrder = orderFields(nil, c.fieldMap)
 	return c
 }
 
+// orderFields returns the keys of fields, those in preferred first and the rest sorted
+// A stable column order keeps the generated SQL identical between runs
+func orderFields(preferred []string, fields map[string]interface{}) []string {
+	order := make([]string, 0, len(fields))
+	used := make(map[string]bool, len(fields))
+	for _, field := range preferred {
+		if _, ok := fields[field]; ok && !used[field] {
+			order = append(order, field)
+			used[field] = true
+		}
+	}
+	rest := make([]string, 0, len(fields)-len(order))
+	for field := range fields {
+		if !used[field] {
+			rest = append(rest, field)
+		}
+	}
+	sort.Strings(rest)
+	return append(order, rest...)
+}
+
 // NewChain creates a new Chain instance with the given database and factory
 func NewChain(db *DB, factory define.SQLFactory) *Chain {
 	return &Chain{
@@ -3111,6 +3201,9 @@ func (c *Chain) Insert(model interface{}) *define.Result {
 	if c.err != nil {
 		return &define.Result{Error: c.err}
 	}
+	if err := checkWritable("insert", model); err != nil {
+		return &define.Result{Error: err}
+	}
 
 	// Process encrypted fields
 	if err := c.processEncryptedFields(model); err != nil {
@@ -3136,8 +3229,86 @@ func (c *Chain) Insert(model interface{}) *define.Result {
 	// Store model for potential ID callback
 	c.model = model
 
-	// Perform insert
-	result := c.Sets(fields).executeInsert()
+	// Perform insert, keeping the struct's column order
+	c.Sets(fields)
+	c.fieldOrder = orderFields(transfer.FieldOrder, c.fieldMap)
+	result := c.executeInsert()
+
+	return result
+}
+
+// checkWritable fails with define.ErrReadOnlyModel when model is read-only, such as a model generated for a view
+func checkWritable(op string, model interface{}) error {
+	if model == nil {
+		return nil
+	}
+	readOnly, ok := model.(define.IReadOnlyModel)
+	if !ok {
+		// ReadOnly is declared on the pointer receiver like TableName, so check the pointer of a struct value too
+		if t := reflect.TypeOf(model); t.Kind() == reflect.Struct {
+			readOnly, ok = reflect.New(t).Interface().(define.IReadOnlyModel)
+		}
+	}
+	if ok && readOnly.ReadOnly() {
+		return fmt.Errorf("cannot %s %T: %w", op, model, define.ErrReadOnlyModel)
+	}
+	return nil
+}
+
+// Upsert inserts model, or updates the existing row when it conflicts on conflictColumns
+// conflictColumns default to the model's primary key; every other column is updated.
+// When model is nil the fields set with Set/Sets are used instead
+func (c *Chain) Upsert(model interface{}, conflictColumns ...string) *define.Result {
+	if c.err != nil {
+		return &define.Result{Error: c.err}
+	}
+	if c.factory == nil {
+		return &define.Result{Error: fmt.Errorf("SQL factory is not initialized")}
+	}
+
+	if model != nil {
+		if err := checkWritable("upsert", model); err != nil {
+			return &define.Result{Error: err}
+		}
+		if err := c.processEncryptedFields(model); err != nil {
+			return &define.Result{Error: err}
+		}
+		transfer := define.GetTransfer(model)
+		if transfer == nil {
+			return &define.Result{Error: fmt.Errorf("failed to get transfer for model")}
+		}
+		if c.tableName == "" {
+			c.tableName = transfer.GetTableName()
+		}
+		if len(conflictColumns) == 0 && transfer.PrimaryKey != nil {
+			conflictColumns = []string{transfer.PrimaryKey.Column}
+		}
+		c.model = model
+		// Zero values are kept, so that an existing row is overwritten with them
+		c.Sets(transfer.ToUpsertMap(model))
+		c.fieldOrder = orderFields(transfer.FieldOrder, c.fieldMap)
+	}
+	if len(c.fieldMap) == 0 {
+		return &define.Result{Error: fmt.Errorf("no fields to upsert")}
+	}
+
+	if len(c.sensitiveFields) > 0 {
+		if err := c.processSensitiveData(c.fieldMap); err != nil {
+			return &define.Result{Error: err}
+		}
+	}
+
+	var updateColumns []string
+	for _, field := range c.fieldOrder {
+		if !contains(conflictColumns, field) {
+			updateColumns = append(updateColumns, field)
+		}
+	}
+
+	sqlProto := c.factory.BuildUpsert(c.tableName, c.fieldMap, c.fieldOrder, conflictColumns, updateColumns)
+	result := c.executeSqlProto(sqlProto)
+
+	c.clearTemporaryData()
 
 	return result
 }
@@ -3150,6 +3321,9 @@ func (c *Chain) SetEncryptionConfig(config *EncryptionConfig) *Chain {
 
 // executeUpdate executes an UPDATE query
 func (c *Chain) executeUpdate() *define.Result {
+	if err := checkWritable("update", c.model); err != nil {
+		return &define.Result{Error: err}
+	}
 	if len(c.fieldMap) == 0 {
 		return &define.Result{Error: fmt.Errorf("no fields to update")}
 	}
@@ -3213,6 +3387,9 @@ func (c *Chain) Delete(models ...interface{}) *define.Result {
 		// If model is provided, use it to set conditions
 		return c.From(models[0]).Delete()
 	}
+	if err := checkWritable("delete", c.model); err != nil {
+		return &define.Result{Error: err}
+	}
 
 	if c.factory == nil {
 		return &define.Result{Error: fmt.Errorf("SQL factory is not initialized")}
@@ -3226,35 +3403,27 @@ func (c *Chain) Delete(models ...interface{}) *define.Result {
 	return c.executeSqlProto(sqlProto)
 
 }
+
+// executeSqlProto executes a built statement through the interceptor chain
 func (c *Chain) executeSqlProto(sqlProto *define.SqlProto) *define.Result {
-	if define.Debug {
-		log.Printf("[SQL] %s %s %v %v", sqlProto.SqlType, sqlProto.Sql, sqlProto.Args, sqlProto.Error)
+	if sqlProto.Error != nil {
+		c.logQuery(c.getContext(), sqlProto.Sql, sqlProto.Args, time.Now(), 0, sqlProto.Error)
+		return &define.Result{Error: sqlProto.Error}
 	}
+	return c.execute(sqlProto, c.runSqlProto)
+}
 
+// runSqlProto runs a built statement against the current connection or transaction
+func (c *Chain) runSqlProto(ctx context.Context, sqlProto *define.SqlProto) *define.Result {
 	if sqlProto.Error != nil {
 		return &define.Result{Error: sqlProto.Error}
 	}
 	if sqlProto.SqlType == define.Query {
-
 		// Start query stats
 		c.startQueryStats(sqlProto.Sql, sqlProto.Args)
 
 		// Execute query
-		var rows *sql.Rows
-		var err error
-		if c.tx != nil {
-			if c.ctx == nil {
-				rows, err = c.tx.Query(sqlProto.Sql, sqlProto.Args...)
-			} else {
-				rows, err = c.tx.QueryContext(c.ctx, sqlProto.Sql, sqlProto.Args...)
-			}
-		} else {
-			if c.ctx == nil {
-				rows, err = c.db.DB.Query(sqlProto.Sql, sqlProto.Args...)
-			} else {
-				rows, err = c.db.DB.QueryContext(c.ctx, sqlProto.Sql, sqlProto.Args...)
-			}
-		}
+		rows, err := c.preparedQueryContext(ctx, sqlProto.Sql, sqlProto.Args)
 		if err != nil {
 			return &define.Result{Error: err}
 		}
@@ -3270,38 +3439,32 @@ func (c *Chain) executeSqlProto(sqlProto *define.SqlProto) *define.Result {
 		// End query stats
 		c.endQueryStats(result.Affected)
 		return result
+	}
 
-	} else {
-		var sqlResult interface {
-			LastInsertId() (int64, error)
-			RowsAffected() (int64, error)
-		}
-		var err error
-		if c.tx != nil {
-			if c.ctx == nil {
-				sqlResult, err = c.tx.Exec(sqlProto.Sql, sqlProto.Args...)
-			} else {
-				sqlResult, err = c.tx.ExecContext(c.ctx, sqlProto.Sql, sqlProto.Args...)
-			}
-		} else {
-			if c.ctx == nil {
-				sqlResult, err = c.db.DB.Exec(sqlProto.Sql, sqlProto.Args...)
-			} else {
-				sqlResult, err = c.db.DB.ExecContext(c.ctx, sqlProto.Sql, sqlProto.Args...)
-			}
-		}
-		if err != nil {
-			return &define.Result{Error: err}
-		}
+	sqlResult, err := c.preparedExecContext(ctx, sqlProto.Sql, sqlProto.Args)
+	if err != nil {
+		return &define.Result{Error: err}
+	}
 
-		affected, _ := sqlResult.RowsAffected()
-		if err != nil {
-			return &define.Result{Error: err}
-		}
-		lastId, _ := sqlResult.LastInsertId()
-		return &define.Result{Affected: affected, ID: lastId}
+	affected, _ := sqlResult.RowsAffected()
+	lastId, _ := sqlResult.LastInsertId()
+	return &define.Result{Affected: affected, ID: lastId}
+}
+
+// queryContext runs a query on the chain transaction, or on the DB when outside one
+func (c *Chain) queryContext(ctx context.Context, query string, args []interface{}) (*sql.Rows, error) {
+	if c.tx != nil {
+		return c.tx.QueryContext(ctx, query, args...)
 	}
+	return c.db.executor().QueryContext(ctx, query, args...)
+}
 
+// execContext runs a statement on the chain transaction, or on the DB when outside one
+func (c *Chain) execContext(ctx context.Context, query string, args []interface{}) (sql.Result, error) {
+	if c.tx != nil {
+		return c.tx.ExecContext(ctx, query, args...)
+	}
+	return c.db.executor().ExecContext(ctx, query, args...)
 }
 
 // saveFromFieldMap 方法已删除，因为 Save 方法已移除
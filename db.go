@@ -1,7 +1,9 @@
 package gom
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -34,17 +36,19 @@ type DBError struct {
 	Op      string
 	Err     error
 	Details string
-	Query   string // Optional, for debugging (only set in debug mode)
+	Query   string // Optional, for debugging
+	Debug   bool   // Whether Error shows Query, copied from DBOptions.Debug of the DB that failed
 }
 
 func (e *DBError) Error() string {
-	if e.Query != "" && define.Debug {
-		return fmt.Sprintf("%s: %v (%s) [Query: %s]", e.Op, e.Err, e.Details, e.Query)
-	}
+	msg := fmt.Sprintf("%s: %v", e.Op, e.Err)
 	if e.Details != "" {
-		return fmt.Sprintf("%s: %v (%s)", e.Op, e.Err, e.Details)
+		msg += " (" + e.Details + ")"
+	}
+	if e.Query != "" && e.Debug {
+		msg += " [Query: " + e.Query + "]"
 	}
-	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+	return msg
 }
 
 // newDBError creates a new DBError with the given parameters
@@ -57,6 +61,16 @@ func newDBError(errType DBErrorType, op string, err error, details string) *DBEr
 	}
 }
 
+// Unwrap returns the underlying error
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
+
+// queryError wraps an error returned by query; the query is shown only when debug is enabled for the DB
+func (c *Chain) queryError(op string, err error, query string) *DBError {
+	return &DBError{Type: ErrQuery, Op: op, Err: err, Query: query, Debug: c.db != nil && c.db.options.Debug}
+}
+
 // GenerateOptions 代码生成选项
 type GenerateOptions struct {
 	OutputDir   string // 输出目录
@@ -83,10 +97,39 @@ type DB struct {
 	Factory             define.SQLFactory
 	RoutineID           int64
 	options             define.DBOptions
-	metrics             *DBMetrics
+	metrics             *metricsCollector
 	tableInfoCache      map[string]*define.TableInfo
 	tableExpireTime     map[string]time.Time
 	tableInfoCacheMutex sync.RWMutex
+	plugins             *plugins
+	stmts               *stmtCache
+	results             *resultCache
+	conn                *sql.Conn // Set by WrapConn; statements run on it instead of the pool
+	ownsPool            bool      // Close closes DB or conn
+}
+
+// executor is the part of *sql.DB and *sql.Conn gom runs statements on
+type executor interface {
+	define.Queryer
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// executor returns the wrapped connection, or the pool
+func (db *DB) executor() executor {
+	if db.conn != nil {
+		return db.conn
+	}
+	return db.DB
+}
+
+// batchConcurrency limits batch workers to one when statements share a single connection
+func (db *DB) batchConcurrency(n int) int {
+	if db.conn != nil {
+		return 1
+	}
+	return n
 }
 
 // cloneSelfIfDifferentGoRoutine ensures thread safety by cloning DB instance if needed
@@ -111,6 +154,11 @@ func (db *DB) cloneSelfIfDifferentGoRoutine() *DB {
 			RoutineID:       currentID,
 			options:         db.options,
 			metrics:         db.metrics,
+			plugins:         db.plugins,
+			stmts:           db.stmts,
+			results:         db.results,
+			conn:            db.conn,
+			ownsPool:        db.ownsPool,
 			tableInfoCache:  make(map[string]*define.TableInfo),
 			tableExpireTime: make(map[string]time.Time),
 		}
@@ -128,22 +176,24 @@ func (db *DB) Chain() *Chain {
 	}
 }
 
-// Close closes the database connection
+// Close stops the metrics sampler and drops cached statements
+// The pool, or the connection given to WrapConn, is closed only when the DB owns it
 func (db *DB) Close() error {
+	db.metrics.close()
+	db.invalidateStatements()
+	if !db.ownsPool {
+		return nil
+	}
+	if db.conn != nil {
+		return db.conn.Close()
+	}
 	return db.DB.Close()
 }
 
-// GetMetrics returns the current database metrics
+// GetMetrics returns the current connection pool statistics
+// See Metrics for statement, transaction and retry metrics
 func (db *DB) GetMetrics() DBMetrics {
-	stats := db.DB.Stats()
-	return DBMetrics{
-		OpenConnections:   int64(stats.OpenConnections),
-		InUseConnections:  int64(stats.InUse),
-		IdleConnections:   int64(stats.Idle),
-		WaitCount:         stats.WaitCount,
-		WaitDuration:      stats.WaitDuration,
-		MaxIdleTimeClosed: stats.MaxIdleClosed,
-	}
+	return poolMetrics(db.Stats())
 }
 
 // optimizeConnectionPool configures the database connection pool
@@ -153,30 +203,6 @@ func (db *DB) optimizeConnectionPool(opts define.DBOptions) {
 	db.DB.SetConnMaxLifetime(opts.ConnMaxLifetime)
 	db.DB.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
 	db.options = opts
-
-	// Initialize metrics
-	db.metrics = &DBMetrics{}
-
-	// Start metrics collection if debug mode is enabled
-	if opts.Debug {
-		go db.collectMetrics()
-	}
-}
-
-// collectMetrics periodically updates connection pool metrics
-func (db *DB) collectMetrics() {
-	ticker := time.NewTicker(time.Second * 5)
-	for range ticker.C {
-		stats := db.DB.Stats()
-		db.metrics = &DBMetrics{
-			OpenConnections:   int64(stats.OpenConnections),
-			InUseConnections:  int64(stats.InUse),
-			IdleConnections:   int64(stats.Idle),
-			WaitCount:         stats.WaitCount,
-			WaitDuration:      stats.WaitDuration,
-			MaxIdleTimeClosed: stats.MaxIdleClosed,
-		}
-	}
 }
 
 // Open creates a new DB connection with options
@@ -202,24 +228,90 @@ func Open(driverName, dsn string, opts *define.DBOptions) (*DB, error) {
 		return nil, err
 	}
 
+	owned := *opts
+	owned.OwnPool = true
+	return newDB(sqlDB, nil, factory, owned), nil
+}
+
+// OpenDB creates a DB on an existing pool, such as one with a custom dialer,
+// instrumentation wrappers or sqlmock. dialect names a registered SQL factory, e.g. "mysql" or "postgres".
+// Unless opts.OwnPool is set, the pool settings are left alone and Close does not close sqlDB
+func OpenDB(sqlDB *sql.DB, dialect string, opts *define.DBOptions) (*DB, error) {
+	if sqlDB == nil {
+		return nil, errors.New("sql.DB is nil")
+	}
+	factory, opts, err := prepareWrap(dialect, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newDB(sqlDB, nil, factory, *opts), nil
+}
+
+// WrapConn creates a DB that runs every statement on conn, for example to keep session
+// state such as temporary tables or session variables. Pool statistics are not available,
+// batch operations run sequentially and transactions must not overlap.
+// Unless opts.OwnPool is set, Close does not close conn
+func WrapConn(conn *sql.Conn, dialect string, opts *define.DBOptions) (*DB, error) {
+	if conn == nil {
+		return nil, errors.New("sql.Conn is nil")
+	}
+	factory, opts, err := prepareWrap(dialect, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newDB(nil, conn, factory, *opts), nil
+}
+
+// prepareWrap resolves the factory and options for OpenDB and WrapConn
+func prepareWrap(dialect string, opts *define.DBOptions) (define.SQLFactory, *define.DBOptions, error) {
+	if opts == nil {
+		defaultOpts := define.DefaultDBOptions()
+		opts = &defaultOpts
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid options: %v", err)
+	}
+	factory, err := define.GetFactory(dialect)
+	if err != nil {
+		return nil, nil, fmt.Errorf("no SQL factory registered for dialect: %s", dialect)
+	}
+	return factory, opts, nil
+}
+
+// newDB builds a DB on sqlDB, or on conn when sqlDB is nil
+func newDB(sqlDB *sql.DB, conn *sql.Conn, factory define.SQLFactory, opts define.DBOptions) *DB {
 	db := &DB{
 		DB:                  sqlDB,
 		Factory:             factory,
-		options:             *opts,
+		options:             opts,
 		tableInfoCache:      make(map[string]*define.TableInfo),
 		tableExpireTime:     make(map[string]time.Time),
-		metrics:             &DBMetrics{},
+		metrics:             newMetricsCollector(),
 		tableInfoCacheMutex: sync.RWMutex{},
+		plugins:             &plugins{},
+		conn:                conn,
+		ownsPool:            opts.OwnPool,
 		RoutineID:           atomic.AddInt64(&routineIDCounter, 1),
 	}
 
 	// Configure connection pool
-	db.optimizeConnectionPool(*opts)
-
-	// Set debug mode
-	define.Debug = opts.Debug
+	if sqlDB != nil && opts.OwnPool {
+		db.optimizeConnectionPool(opts)
+	}
+	db.UseTracer(opts.Tracer)
+	if opts.StmtCacheSize > 0 {
+		db.stmts = newStmtCache(db.executor(), opts.StmtCacheSize)
+	}
+	if opts.ResultCache != nil {
+		db.results = newResultCache(opts.ResultCache)
+	} else {
+		db.results = newResultCache(define.NewLRUCache(defaultResultCacheSize))
+	}
+	if sqlDB != nil {
+		go db.metrics.run(sqlDB)
+	}
 
-	return db, nil
+	return db
 }
 
 // OpenWithDefaults creates a new DB connection with default options
@@ -247,8 +339,9 @@ func (db *DB) UpdateOptions(opts define.DBOptions) error {
 		return fmt.Errorf("invalid options: %v", err)
 	}
 
-	db.optimizeConnectionPool(opts)
-	define.Debug = opts.Debug
+	if db.DB != nil {
+		db.optimizeConnectionPool(opts)
+	}
 	return nil
 }
 
@@ -274,7 +367,7 @@ func (db *DB) GetTableInfo(tableName string) (*define.TableInfo, error) {
 	}
 
 	// 获取最新表信息
-	newInfo, err := db.Factory.GetTableInfo(db.DB, tableName)
+	newInfo, err := db.Factory.GetTableInfo(db.executor(), tableName)
 	if err != nil {
 		if exists {
 			// 获取失败时延长旧缓存有效期（5秒）
@@ -292,7 +385,22 @@ func (db *DB) GetTableInfo(tableName string) (*define.TableInfo, error) {
 
 // GetTables returns a list of table names in the database
 func (db *DB) GetTables(pattern string) ([]string, error) {
-	return db.Factory.GetTables(db.DB, pattern)
+	return db.Factory.GetTables(db.executor(), pattern)
+}
+
+// GetViews returns the names of the views matching pattern, not including materialized views
+func (db *DB) GetViews(pattern string) ([]string, error) {
+	return db.Factory.GetViews(db.executor(), pattern)
+}
+
+// GetMaterializedViews returns the names of the materialized views matching pattern
+func (db *DB) GetMaterializedViews(pattern string) ([]string, error) {
+	return db.Factory.GetMaterializedViews(db.executor(), pattern)
+}
+
+// ListSchemas returns the user schemas of the database, on MySQL the databases
+func (db *DB) ListSchemas() ([]string, error) {
+	return db.Factory.ListSchemas(db.executor())
 }
 
 // GenerateStruct 生成单个表的结构体代码
@@ -540,15 +648,21 @@ func (db *DB) GetTableStruct2(i any) (*define.TableStruct, error) {
 	return db.GetTableStruct(i, tableName)
 }
 
-// GetDB returns the underlying sql.DB object
+// GetDB returns the underlying sql.DB object, or nil for a DB created by WrapConn
 func (db *DB) GetDB() *sql.DB {
 	return db.DB
 }
 
+// Stats returns the pool statistics, or zero values for a DB created by WrapConn
 func (db *DB) Stats() sql.DBStats {
+	if db.DB == nil {
+		return sql.DBStats{}
+	}
 	return db.DB.Stats()
 }
 
 func (db *DB) SetMaxOpenConns(n int) {
-	db.DB.SetMaxOpenConns(n)
+	if db.DB != nil {
+		db.DB.SetMaxOpenConns(n)
+	}
 }
@@ -0,0 +1,189 @@
+package gom
+
+import (
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// DropTable drops table
+func (c *Chain) DropTable(table string) error {
+	return c.execDDL(table, c.factory.BuildDropTable(table, false))
+}
+
+// DropTableIfExists drops table when it exists
+func (c *Chain) DropTableIfExists(table string) error {
+	return c.execDDL(table, c.factory.BuildDropTable(table, true))
+}
+
+// TruncateTable removes all rows of table and resets its auto-increment counter
+func (c *Chain) TruncateTable(table string) error {
+	return c.execDDL(table, c.factory.BuildTruncateTable(table))
+}
+
+// RenameTable renames table to newName
+func (c *Chain) RenameTable(table, newName string) error {
+	defer c.db.invalidateTableInfo(newName)
+	return c.execDDL(table, c.factory.BuildRenameTable(table, newName))
+}
+
+// AddColumn adds col to table; col.Type is the column type of the dialect, e.g. VARCHAR(64)
+func (c *Chain) AddColumn(table string, col *define.ColumnDef) error {
+	return c.execDDL(table, c.factory.BuildSchemaChange(&define.SchemaChange{Kind: define.ChangeAddColumn, Table: table, Column: col})...)
+}
+
+// DropColumn drops column from table
+func (c *Chain) DropColumn(table, column string) error {
+	return c.execDDL(table, c.factory.BuildDropColumn(table, column))
+}
+
+// RenameColumn renames column of table to newName
+func (c *Chain) RenameColumn(table, column, newName string) error {
+	return c.execDDL(table, c.factory.BuildRenameColumn(table, column, newName))
+}
+
+// AlterColumnType changes the type of column col.Name to col.Type
+// MySQL restates the whole column, so set the nullability and default of col as well
+func (c *Chain) AlterColumnType(table string, col *define.ColumnDef) error {
+	return c.execDDL(table, c.factory.BuildAlterColumnType(table, col))
+}
+
+// CreateIndex creates index on table
+func (c *Chain) CreateIndex(table string, index *define.IndexDef) error {
+	return c.execDDL(table, c.factory.BuildCreateIndex(table, index, false))
+}
+
+// CreateIndexConcurrently creates index without blocking writes on PostgreSQL
+// It cannot run inside a transaction; on MySQL it is the same as CreateIndex
+func (c *Chain) CreateIndexConcurrently(table string, index *define.IndexDef) error {
+	return c.execDDL(table, c.factory.BuildCreateIndex(table, index, true))
+}
+
+// DropIndex drops the index called index from table
+func (c *Chain) DropIndex(table, index string) error {
+	return c.execDDL(table, c.factory.BuildDropIndex(table, index, false))
+}
+
+// DropIndexConcurrently drops an index without blocking writes on PostgreSQL
+// It cannot run inside a transaction; on MySQL it is the same as DropIndex
+func (c *Chain) DropIndexConcurrently(table, index string) error {
+	return c.execDDL(table, c.factory.BuildDropIndex(table, index, true))
+}
+
+// RefreshMaterializedView recomputes the rows of a PostgreSQL materialized view
+// A concurrent refresh does not block reads but needs a unique index on the view and cannot run inside a transaction
+func (c *Chain) RefreshMaterializedView(view string, concurrently bool) error {
+	return c.execDDL(view, c.factory.BuildRefreshMaterializedView(view, concurrently))
+}
+
+// HasTable reports whether table exists
+func (c *Chain) HasTable(table string) (bool, error) {
+	return c.factory.HasTable(c.queryer(), table)
+}
+
+// HasColumn reports whether table has column
+func (c *Chain) HasColumn(table, column string) (bool, error) {
+	return c.factory.HasColumn(c.queryer(), table, column)
+}
+
+// HasIndex reports whether table has an index called index
+func (c *Chain) HasIndex(table, index string) (bool, error) {
+	return c.factory.HasIndex(c.queryer(), table, index)
+}
+
+// queryer returns the transaction of the chain, or the DB's connection
+func (c *Chain) queryer() define.Queryer {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.db.executor()
+}
+
+// execDDL runs DDL statements on table and drops its cached table info
+func (c *Chain) execDDL(table string, protos ...*define.SqlProto) error {
+	defer c.db.invalidateTableInfo(table)
+	for _, proto := range protos {
+		if result := c.executeSqlProto(proto); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// DropTable drops table
+func (db *DB) DropTable(table string) error {
+	return db.Chain().DropTable(table)
+}
+
+// DropTableIfExists drops table when it exists
+func (db *DB) DropTableIfExists(table string) error {
+	return db.Chain().DropTableIfExists(table)
+}
+
+// TruncateTable removes all rows of table and resets its auto-increment counter
+func (db *DB) TruncateTable(table string) error {
+	return db.Chain().TruncateTable(table)
+}
+
+// RenameTable renames table to newName
+func (db *DB) RenameTable(table, newName string) error {
+	return db.Chain().RenameTable(table, newName)
+}
+
+// AddColumn adds col to table
+func (db *DB) AddColumn(table string, col *define.ColumnDef) error {
+	return db.Chain().AddColumn(table, col)
+}
+
+// DropColumn drops column from table
+func (db *DB) DropColumn(table, column string) error {
+	return db.Chain().DropColumn(table, column)
+}
+
+// RenameColumn renames column of table to newName
+func (db *DB) RenameColumn(table, column, newName string) error {
+	return db.Chain().RenameColumn(table, column, newName)
+}
+
+// AlterColumnType changes the type of column col.Name to col.Type
+func (db *DB) AlterColumnType(table string, col *define.ColumnDef) error {
+	return db.Chain().AlterColumnType(table, col)
+}
+
+// CreateIndex creates index on table
+func (db *DB) CreateIndex(table string, index *define.IndexDef) error {
+	return db.Chain().CreateIndex(table, index)
+}
+
+// CreateIndexConcurrently creates index without blocking writes on PostgreSQL
+func (db *DB) CreateIndexConcurrently(table string, index *define.IndexDef) error {
+	return db.Chain().CreateIndexConcurrently(table, index)
+}
+
+// DropIndex drops the index called index from table
+func (db *DB) DropIndex(table, index string) error {
+	return db.Chain().DropIndex(table, index)
+}
+
+// DropIndexConcurrently drops an index without blocking writes on PostgreSQL
+func (db *DB) DropIndexConcurrently(table, index string) error {
+	return db.Chain().DropIndexConcurrently(table, index)
+}
+
+// RefreshMaterializedView recomputes the rows of a PostgreSQL materialized view
+func (db *DB) RefreshMaterializedView(view string, concurrently bool) error {
+	return db.Chain().RefreshMaterializedView(view, concurrently)
+}
+
+// HasTable reports whether table exists
+func (db *DB) HasTable(table string) (bool, error) {
+	return db.Chain().HasTable(table)
+}
+
+// HasColumn reports whether table has column
+func (db *DB) HasColumn(table, column string) (bool, error) {
+	return db.Chain().HasColumn(table, column)
+}
+
+// HasIndex reports whether table has an index called index
+func (db *DB) HasIndex(table, index string) (bool, error) {
+	return db.Chain().HasIndex(table, index)
+}
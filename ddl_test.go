@@ -0,0 +1,213 @@
+package gom
+
+import (
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/gomtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDLMySQL(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	require.NoError(t, db.DropTableIfExists("logs"))
+	require.NoError(t, db.TruncateTable("users"))
+	require.NoError(t, db.RenameTable("users", "members"))
+	require.NoError(t, db.AddColumn("members", &define.ColumnDef{Name: "email", Type: "VARCHAR(128)", Comment: "login"}))
+	require.NoError(t, db.RenameColumn("members", "email", "mail"))
+	require.NoError(t, db.AlterColumnType("members", &define.ColumnDef{Name: "mail", Type: "VARCHAR(255)", Nullable: true}))
+	require.NoError(t, db.CreateIndexConcurrently("members", &define.IndexDef{Name: "uk_mail", Columns: []string{"mail"}, Unique: true}))
+	require.NoError(t, db.DropIndex("members", "uk_mail"))
+	require.NoError(t, db.DropColumn("members", "mail"))
+
+	mock.AssertStatementsInOrder(t,
+		"DROP TABLE IF EXISTS `logs`",
+		"TRUNCATE TABLE `users`",
+		"RENAME TABLE `users` TO `members`",
+		"ALTER TABLE `members` ADD COLUMN `email` VARCHAR(128) NOT NULL COMMENT 'login'",
+		"ALTER TABLE `members` RENAME COLUMN `email` TO `mail`",
+		"ALTER TABLE `members` MODIFY COLUMN `mail` VARCHAR(255)",
+		"CREATE UNIQUE INDEX `uk_mail` ON `members` (`mail`)",
+		"DROP INDEX `uk_mail` ON `members`",
+		"ALTER TABLE `members` DROP COLUMN `mail`",
+	)
+
+	err := db.AddColumn("members", &define.ColumnDef{Name: "age"})
+	assert.EqualError(t, err, "column age has no type")
+	assert.ErrorIs(t, db.DropTable(""), define.ErrEmptyTableName)
+}
+
+func TestDDLPostgres(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	require.NoError(t, db.DropTable("audit.logs"))
+	require.NoError(t, db.TruncateTable("users"))
+	require.NoError(t, db.RenameTable("audit.users", "audit.members"))
+	require.NoError(t, db.AddColumn("members", &define.ColumnDef{Name: "email", Type: "VARCHAR(128)", Nullable: true, Comment: "login"}))
+	require.NoError(t, db.AlterColumnType("members", &define.ColumnDef{Name: "age", Type: "BIGINT"}))
+	require.NoError(t, db.CreateIndexConcurrently("members", &define.IndexDef{Name: "idx_email", Columns: []string{"email"}}))
+	require.NoError(t, db.DropIndexConcurrently("audit.members", "idx_email"))
+
+	mock.AssertStatementsInOrder(t,
+		`DROP TABLE "audit"."logs"`,
+		`TRUNCATE TABLE "users" RESTART IDENTITY`,
+		`ALTER TABLE "audit"."users" RENAME TO "members"`,
+		`ALTER TABLE "members" ADD COLUMN "email" VARCHAR(128)`,
+		`COMMENT ON COLUMN "members"."email" IS 'login'`,
+		`ALTER TABLE "members" ALTER COLUMN "age" TYPE BIGINT USING "age"::BIGINT`,
+		`CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_email" ON "members" ("email")`,
+		`DROP INDEX CONCURRENTLY IF EXISTS "audit"."idx_email"`,
+	)
+}
+
+func TestHasTableColumnIndex(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	mock.On("information_schema.tables").Return(gomtest.NewRows("count").AddRow(1))
+	mock.On("information_schema.columns").Return(gomtest.NewRows("count").AddRow(0))
+	mock.On("pg_indexes").Return(gomtest.NewRows("count").AddRow(1))
+
+	ok, err := db.HasTable("audit.users")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = db.HasColumn("users", "email")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = db.HasIndex("users", "idx_email")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	db, mock = openMock(t, gomtest.MySQL)
+	mock.On("information_schema.statistics").Return(gomtest.NewRows("count").AddRow(1))
+	ok, err = db.HasIndex("users", "PRIMARY")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestGetTableInfoIndexes(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("information_schema.columns").Return(mysqlColumns(
+		[]interface{}{"id", "bigint", nil, 19, 0, "NO", "PRI", "auto_increment", nil, "", "bigint"},
+		[]interface{}{"email", "varchar", 128, nil, nil, "NO", "UNI", "", nil, "", "varchar(128)"},
+	))
+	mock.On("information_schema.statistics").Return(gomtest.NewRows("index_name", "column_name", "non_unique", "index_type").
+		AddRow("PRIMARY", "id", 0, "BTREE").
+		AddRow("idx_tenant_email", "tenant_id", 0, "BTREE").
+		AddRow("idx_tenant_email", "email", 0, "BTREE").
+		AddRow("idx_lower_name", nil, 1, "BTREE"))
+	info, err := db.GetTableInfo("users")
+	require.NoError(t, err)
+	assert.Equal(t, []define.IndexInfo{
+		{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true, Method: "BTREE"},
+		{Name: "idx_tenant_email", Columns: []string{"tenant_id", "email"}, Unique: true, Method: "BTREE"},
+	}, info.Indexes)
+
+	db, mock = openMock(t, gomtest.Postgres)
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	mock.On("FROM pg_index").Return(gomtest.NewRows("relname", "attname", "indisunique", "indisprimary", "amname").
+		AddRow("users_pkey", "id", true, true, "btree").
+		AddRow("idx_name", "name", false, false, "gin"))
+	info, err = db.GetTableInfo("users")
+	require.NoError(t, err)
+	assert.Equal(t, []define.IndexInfo{
+		{Name: "users_pkey", Columns: []string{"id"}, Unique: true, Primary: true, Method: "btree"},
+		{Name: "idx_name", Columns: []string{"name"}, Method: "gin"},
+	}, info.Indexes)
+}
+
+func TestGetTableInfoForeignKeys(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("information_schema.key_column_usage").Return(gomtest.NewRows("constraint_name", "column_name",
+		"referenced_table_schema", "referenced_table_name", "referenced_column_name", "same_schema", "update_rule", "delete_rule").
+		AddRow("fk_order_items_order", "tenant_id", "shop", "orders", "tenant_id", 1, "NO ACTION", "CASCADE").
+		AddRow("fk_order_items_order", "order_id", "shop", "orders", "id", 1, "NO ACTION", "CASCADE").
+		AddRow("fk_order_items_product", "product_id", "catalog", "products", "id", 0, "CASCADE", "SET NULL"))
+	info, err := db.GetTableInfo("order_items")
+	require.NoError(t, err)
+	assert.Equal(t, []define.ForeignKeyInfo{
+		{Name: "fk_order_items_order", Columns: []string{"tenant_id", "order_id"}, RefTable: "orders", RefColumns: []string{"tenant_id", "id"},
+			OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+		{Name: "fk_order_items_product", Columns: []string{"product_id"}, RefTable: "catalog.products", RefColumns: []string{"id"},
+			OnUpdate: "CASCADE", OnDelete: "SET NULL"},
+	}, info.ForeignKeys)
+
+	db, mock = openMock(t, gomtest.Postgres)
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	mock.On("FROM pg_constraint con").Return(gomtest.NewRows("conname", "attname", "nspname", "relname", "attname",
+		"confupdtype", "confdeltype").
+		AddRow("orders_user_id_fkey", "user_id", "public", "users", "id", "a", "c").
+		AddRow("orders_region_fkey", "region", "geo", "regions", "code", "r", "d"))
+	info, err = db.GetTableInfo("orders")
+	require.NoError(t, err)
+	assert.Equal(t, []define.ForeignKeyInfo{
+		{Name: "orders_user_id_fkey", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"},
+			OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+		{Name: "orders_region_fkey", Columns: []string{"region"}, RefTable: "geo.regions", RefColumns: []string{"code"},
+			OnUpdate: "RESTRICT", OnDelete: "SET DEFAULT"},
+	}, info.ForeignKeys)
+}
+
+func TestGetTableInfoChecksAndOptions(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("information_schema.tables").Return(gomtest.NewRows("table_comment", "engine", "table_collation", "table_type").
+		AddRow("events", "InnoDB", "utf8mb4_unicode_ci", "BASE TABLE"))
+	mock.On("information_schema.check_constraints").Return(gomtest.NewRows("constraint_name", "check_clause").
+		AddRow("chk_amount", "(`amount` > 0)"))
+	mock.On("information_schema.partitions").Return(gomtest.NewRows("partition_ordinal_position", "partition_name",
+		"partition_method", "partition_expression").
+		AddRow(1, "p2024", "RANGE", "year(`created_at`)").
+		AddRow(2, "pmax", "RANGE", "year(`created_at`)"))
+	info, err := db.GetTableInfo("events")
+	require.NoError(t, err)
+	assert.Equal(t, "events", info.TableComment)
+	assert.Equal(t, "InnoDB", info.Engine)
+	assert.Equal(t, "utf8mb4_unicode_ci", info.Collation)
+	assert.Equal(t, []define.CheckConstraintInfo{{Name: "chk_amount", Expression: "(`amount` > 0)"}}, info.CheckConstraints)
+	assert.Equal(t, &define.PartitionInfo{Method: "RANGE", Expression: "year(`created_at`)", Partitions: []string{"p2024", "pmax"}}, info.Partition)
+
+	db, mock = openMock(t, gomtest.Postgres)
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	mock.On("FROM pg_constraint ck").Return(gomtest.NewRows("conname", "pg_get_expr").
+		AddRow("events_amount_check", "(amount > (0)::numeric)"))
+	mock.On("FROM pg_partitioned_table").Return(gomtest.NewRows("pg_get_partkeydef", "nspname", "relname").
+		AddRow("RANGE (created_at)", "archive", "events_2023").
+		AddRow("RANGE (created_at)", "public", "events_2024"))
+	info, err = db.GetTableInfo("events")
+	require.NoError(t, err)
+	assert.Equal(t, []define.CheckConstraintInfo{{Name: "events_amount_check", Expression: "(amount > (0)::numeric)"}}, info.CheckConstraints)
+	assert.Equal(t, &define.PartitionInfo{Method: "RANGE", Expression: "created_at", Partitions: []string{"archive.events_2023", "events_2024"}}, info.Partition)
+
+	// 没有分区的表
+	db, mock = openMock(t, gomtest.Postgres)
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	info, err = db.GetTableInfo("users")
+	require.NoError(t, err)
+	assert.Nil(t, info.Partition)
+	assert.Empty(t, info.CheckConstraints)
+}
+
+func TestGetTableInfoEnumValues(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("information_schema.columns").Return(mysqlColumns(
+		[]interface{}{"status", "enum", 11, nil, nil, "NO", "", "", "new", "", `enum('new','it''s done','a\\b')`},
+		[]interface{}{"name", "varchar", 64, nil, nil, "NO", "", "", nil, "", "varchar(64)"},
+	))
+	info, err := db.GetTableInfo("orders")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new", "it's done", `a\b`}, info.Columns[0].EnumValues)
+	assert.Nil(t, info.Columns[1].EnumValues)
+
+	db, mock = openMock(t, gomtest.Postgres)
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	mock.On("FROM pg_attribute").Return(gomtest.NewRows("column_name", "data_type", "max_length", "type_modifier",
+		"not_null", "default_value", "column_comment", "identity", "is_primary", "column_type").
+		AddRow("mood", "mood", 4, -1, true, nil, nil, "", false, "mood").
+		AddRow("name", "text", -1, -1, true, nil, nil, "", false, "text"))
+	mock.On("FROM pg_enum").Return(gomtest.NewRows("attname", "enumlabel").
+		AddRow("mood", "happy").
+		AddRow("mood", "sad"))
+	info, err = db.GetTableInfo("people")
+	require.NoError(t, err)
+	assert.Equal(t, "mood", info.Columns[0].TypeName)
+	assert.Equal(t, []string{"happy", "sad"}, info.Columns[0].EnumValues)
+	assert.Nil(t, info.Columns[1].EnumValues)
+}
@@ -0,0 +1,132 @@
+package define
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache stores query results tagged with the tables they read
+// Implementations must be safe for concurrent use
+type Cache interface {
+	// Get returns the cached result for key, if present and not expired
+	Get(key string) (*Result, bool)
+	// Set stores result under key for ttl, tagged with tables
+	Set(key string, result *Result, ttl time.Duration, tables []string)
+	// InvalidateTables removes every entry tagged with one of tables
+	InvalidateTables(tables ...string)
+	// Clear removes every entry
+	Clear()
+}
+
+// LRUCache is an in-process Cache bounded by entry count
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // Front is most recently used
+	entries    map[string]*list.Element
+	tables     map[string]map[string]struct{} // Table -> keys tagged with it
+}
+
+type lruEntry struct {
+	key     string
+	result  *Result
+	expires time.Time
+	tables  []string
+}
+
+// NewLRUCache creates an LRUCache holding at most maxEntries results
+// If maxEntries <= 0, the cache is unbounded and relies on TTLs only
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		tables:     make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements Cache
+func (c *LRUCache) Get(key string) (*Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.result, true
+}
+
+// Set implements Cache
+func (c *LRUCache) Set(key string, result *Result, ttl time.Duration, tables []string) {
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	entry := &lruEntry{key: key, result: result, expires: time.Now().Add(ttl), tables: tables}
+	c.entries[key] = c.order.PushFront(entry)
+	for _, table := range tables {
+		keys, ok := c.tables[table]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tables[table] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// InvalidateTables implements Cache
+func (c *LRUCache) InvalidateTables(tables ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, table := range tables {
+		for key := range c.tables[table] {
+			if elem, ok := c.entries[key]; ok {
+				c.remove(elem)
+			}
+		}
+	}
+}
+
+// Clear implements Cache
+func (c *LRUCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	c.tables = make(map[string]map[string]struct{})
+}
+
+// Len returns the number of cached entries, including expired ones not yet evicted
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove unlinks elem and its table tags; the caller holds c.mu
+func (c *LRUCache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*lruEntry)
+	delete(c.entries, entry.key)
+	for _, table := range entry.tables {
+		if keys, ok := c.tables[table]; ok {
+			delete(keys, entry.key)
+			if len(keys) == 0 {
+				delete(c.tables, table)
+			}
+		}
+	}
+}
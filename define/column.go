@@ -0,0 +1,101 @@
+package define
+
+// Column is a typed column descriptor; generated models declare one per column so that
+// conditions are checked by the compiler, e.g. UserCols.Name.Eq("tom")
+type Column[T any] struct {
+	name string
+}
+
+// Col creates a column descriptor for the column called name
+func Col[T any](name string) Column[T] {
+	return Column[T]{name: name}
+}
+
+// Name returns the column name
+func (c Column[T]) Name() string {
+	return c.name
+}
+
+// String returns the column name
+func (c Column[T]) String() string {
+	return c.name
+}
+
+// Eq creates a column = value condition
+func (c Column[T]) Eq(value T) *Condition {
+	return Eq(c.name, value)
+}
+
+// Ne creates a column <> value condition
+func (c Column[T]) Ne(value T) *Condition {
+	return Ne(c.name, value)
+}
+
+// Gt creates a column > value condition
+func (c Column[T]) Gt(value T) *Condition {
+	return Gt(c.name, value)
+}
+
+// Ge creates a column >= value condition
+func (c Column[T]) Ge(value T) *Condition {
+	return Ge(c.name, value)
+}
+
+// Lt creates a column < value condition
+func (c Column[T]) Lt(value T) *Condition {
+	return Lt(c.name, value)
+}
+
+// Le creates a column <= value condition
+func (c Column[T]) Le(value T) *Condition {
+	return Le(c.name, value)
+}
+
+// Like creates a column LIKE pattern condition
+func (c Column[T]) Like(pattern string) *Condition {
+	return Like(c.name, pattern)
+}
+
+// NotLike creates a column NOT LIKE pattern condition
+func (c Column[T]) NotLike(pattern string) *Condition {
+	return NotLike(c.name, pattern)
+}
+
+// In creates a column IN (values) condition
+func (c Column[T]) In(values ...T) *Condition {
+	return &Condition{Field: c.name, Op: OpIn, Value: toInterfaces(values)}
+}
+
+// NotIn creates a column NOT IN (values) condition
+func (c Column[T]) NotIn(values ...T) *Condition {
+	return &Condition{Field: c.name, Op: OpNotIn, Value: toInterfaces(values)}
+}
+
+// IsNull creates a column IS NULL condition
+func (c Column[T]) IsNull() *Condition {
+	return IsNull(c.name)
+}
+
+// IsNotNull creates a column IS NOT NULL condition
+func (c Column[T]) IsNotNull() *Condition {
+	return IsNotNull(c.name)
+}
+
+// Between creates a column BETWEEN start AND end condition
+func (c Column[T]) Between(start, end T) *Condition {
+	return Between(c.name, start, end)
+}
+
+// NotBetween creates a column NOT BETWEEN start AND end condition
+func (c Column[T]) NotBetween(start, end T) *Condition {
+	return NotBetween(c.name, start, end)
+}
+
+// toInterfaces copies values into a []interface{} without flattening slice values such as []byte
+func toInterfaces[T any](values []T) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
@@ -0,0 +1,24 @@
+package define
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnConditions(t *testing.T) {
+	name := Col[string]("user_name")
+	assert.Equal(t, "user_name", name.Name())
+	assert.Equal(t, &Condition{Field: "user_name", Op: OpEq, Value: "tom"}, name.Eq("tom"))
+	assert.Equal(t, &Condition{Field: "user_name", Op: OpIsNull}, name.IsNull())
+
+	start, end := time.Unix(0, 0), time.Unix(3600, 0)
+	between := Col[time.Time]("created_at").Between(start, end)
+	assert.Equal(t, OpBetween, between.Op)
+	assert.Equal(t, []interface{}{start, end}, between.Value)
+
+	// Slice values are kept whole rather than flattened
+	in := Col[[]byte]("data").In([]byte("a"), []byte("b"))
+	assert.Equal(t, []interface{}{[]byte("a"), []byte("b")}, in.Value)
+}
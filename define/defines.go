@@ -2,8 +2,13 @@ package define
 
 import "errors"
 
-// Debug flag for enabling debug mode
+// Debug flag for enabling low-level diagnostics such as column scan tracing
+// Query logging is configured per DB through DBOptions.Debug and DBOptions.Logger;
+// gom.Open no longer changes this flag
 var Debug bool
 
 // ErrManualRollback is used to manually trigger a transaction rollback
 var ErrManualRollback = errors.New("manual rollback")
+
+// ErrReadOnlyModel is returned when inserting, updating or deleting a model whose ReadOnly method returns true
+var ErrReadOnlyModel = errors.New("model is read-only")
@@ -21,3 +21,8 @@ func (e *DBError) Error() string {
 	}
 	return fmt.Sprintf("[%s] Code %d: %s", e.Op, e.Code, e.Message)
 }
+
+// Unwrap returns the underlying error, so errors.Is and errors.As see through a DBError
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
@@ -1,8 +1,11 @@
 package define
 
 import (
+	"context"
 	"database/sql"
 	"reflect"
+	"strings"
+	"unicode"
 )
 
 // OrderType represents the type of ordering
@@ -33,29 +36,72 @@ type OrderBy struct {
 
 // TableInfo 表信息
 type TableInfo struct {
-	TableName    string       `json:"table_name"`    // 表名
-	TableComment string       `json:"table_comment"` // 表注释
-	PrimaryKeys  []string     `json:"primary_keys"`  // 主键列表
-	Columns      []ColumnInfo `json:"columns"`       // 列信息
-	HasDecimal   bool         `json:"has_decimal"`   // 是否包含 Decimal 类型
-	HasUUID      bool         `json:"has_uuid"`      // 是否包含 UUID 类型
-	HasIP        bool         `json:"has_ip"`        // 是否包含 IP 类型
-	HasTime      bool         `json:"has_time"`      // 是否包含时间类型
+	TableName        string                `json:"table_name"`        // 表名
+	TableComment     string                `json:"table_comment"`     // 表注释
+	PrimaryKeys      []string              `json:"primary_keys"`      // 主键列表
+	Columns          []ColumnInfo          `json:"columns"`           // 列信息
+	Indexes          []IndexInfo           `json:"indexes"`           // 索引列表
+	ForeignKeys      []ForeignKeyInfo      `json:"foreign_keys"`      // 外键列表
+	CheckConstraints []CheckConstraintInfo `json:"check_constraints"` // 检查约束列表
+	Engine           string                `json:"engine"`            // 存储引擎，如 InnoDB，仅 MySQL
+	Collation        string                `json:"collation"`         // 默认排序规则，如 utf8mb4_unicode_ci，仅 MySQL
+	Partition        *PartitionInfo        `json:"partition"`         // 分区信息，不是分区表时为 nil
+	IsView           bool                  `json:"is_view"`           // 是否为视图，物化视图也是视图
+	IsMaterialized   bool                  `json:"is_materialized"`   // 是否为物化视图，仅 PostgreSQL
+	ViewDefinition   string                `json:"view_definition"`   // 视图的查询语句，不是视图时为空
+	HasDecimal       bool                  `json:"has_decimal"`       // 是否包含 Decimal 类型
+	HasUUID          bool                  `json:"has_uuid"`          // 是否包含 UUID 类型
+	HasIP            bool                  `json:"has_ip"`            // 是否包含 IP 类型
+	HasTime          bool                  `json:"has_time"`          // 是否包含时间类型
 }
 
 // ColumnInfo 列信息
 type ColumnInfo struct {
-	Name            string `json:"name"`              // 列名
-	TypeName        string `json:"type_name"`         // 数据库类型名称
-	DataType        string `json:"data_type"`         // 标准SQL数据类型
-	Length          int64  `json:"length"`            // 长度
-	Precision       int    `json:"precision"`         // 精度
-	Scale           int    `json:"scale"`             // 小数位数
-	IsNullable      bool   `json:"is_nullable"`       // 是否可空
-	IsPrimaryKey    bool   `json:"is_primary_key"`    // 是否主键
-	IsAutoIncrement bool   `json:"is_auto_increment"` // 是否自增
-	DefaultValue    string `json:"default_value"`     // 默认值
-	Comment         string `json:"comment"`           // 注释
+	Name            string   `json:"name"`              // 列名
+	TypeName        string   `json:"type_name"`         // 数据库类型名称
+	ColumnType      string   `json:"column_type"`       // 完整列类型，如 varchar(255)、tinyint(1)
+	DataType        string   `json:"data_type"`         // 标准SQL数据类型
+	Length          int64    `json:"length"`            // 长度
+	Precision       int      `json:"precision"`         // 精度
+	Scale           int      `json:"scale"`             // 小数位数
+	IsNullable      bool     `json:"is_nullable"`       // 是否可空
+	IsPrimaryKey    bool     `json:"is_primary_key"`    // 是否主键
+	IsAutoIncrement bool     `json:"is_auto_increment"` // 是否自增
+	DefaultValue    string   `json:"default_value"`     // 默认值
+	Comment         string   `json:"comment"`           // 注释
+	EnumValues      []string `json:"enum_values"`       // 枚举列的可选值，按定义顺序；PostgreSQL 中 TypeName 为枚举类型名
+}
+
+// IndexInfo 索引信息
+type IndexInfo struct {
+	Name    string   `json:"name"`    // 索引名
+	Columns []string `json:"columns"` // 索引列，按在索引中的顺序
+	Unique  bool     `json:"unique"`  // 是否唯一索引
+	Primary bool     `json:"primary"` // 是否主键
+	Method  string   `json:"method"`  // 索引方法，如 BTREE、FULLTEXT (MySQL) 或 btree、gin (PostgreSQL)
+}
+
+// ForeignKeyInfo 外键信息
+type ForeignKeyInfo struct {
+	Name       string   `json:"name"`        // 约束名
+	Columns    []string `json:"columns"`     // 本表的列，按在约束中的顺序
+	RefTable   string   `json:"ref_table"`   // 引用的表，与本表不在同一 schema（数据库）时为 schema.table
+	RefColumns []string `json:"ref_columns"` // 引用的列，与 Columns 一一对应
+	OnUpdate   string   `json:"on_update"`   // 更新引用行时的动作，如 NO ACTION、CASCADE、SET NULL
+	OnDelete   string   `json:"on_delete"`   // 删除引用行时的动作
+}
+
+// CheckConstraintInfo 检查约束信息
+type CheckConstraintInfo struct {
+	Name       string `json:"name"`       // 约束名
+	Expression string `json:"expression"` // 检查的表达式，如 (price > 0)，格式由数据库决定
+}
+
+// PartitionInfo 分区表信息
+type PartitionInfo struct {
+	Method     string   `json:"method"`     // 分区方式，如 RANGE、LIST、HASH、RANGE COLUMNS
+	Expression string   `json:"expression"` // 分区键的表达式，如 year(created_at)
+	Partitions []string `json:"partitions"` // 分区名；PostgreSQL 中为分区子表，与父表不在同一 schema 时为 schema.table
 }
 
 type TableStruct struct {
@@ -76,6 +122,25 @@ type SqlProto struct {
 	Sql     string
 	Args    []any
 	Error   error
+	Table   string // Target table, filled in by Chain when empty
+}
+
+// Operation returns the upper-cased leading keyword of the statement, e.g. SELECT
+func (p *SqlProto) Operation() string {
+	sql := strings.TrimLeft(p.Sql, " \t\r\n(")
+	end := strings.IndexFunc(sql, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end < 0 {
+		end = len(sql)
+	}
+	return strings.ToUpper(sql[:end])
+}
+
+// Queryer runs read-only queries; *sql.DB, *sql.Conn and *sql.Tx implement it
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 // SQLFactory defines the interface for SQL query builders
@@ -98,24 +163,89 @@ type SQLFactory interface {
 	// BuildBatchInsert builds a batch INSERT query
 	BuildBatchInsert(table string, values []map[string]interface{}) *SqlProto
 
+	// BuildUpsert builds an INSERT that updates updateColumns when a row with the same conflictColumns exists
+	BuildUpsert(table string, fields map[string]interface{}, fieldOrder []string, conflictColumns []string, updateColumns []string) *SqlProto
+
 	// BuildDelete builds a DELETE query
 	BuildDelete(table string, conditions []*Condition) *SqlProto
 
 	// BuildCreateTable builds a CREATE TABLE query
 	BuildCreateTable(table string, modelType reflect.Type) *SqlProto
 
+	// DescribeModel describes the table modelType maps to, with the column types of the dialect
+	DescribeModel(table string, modelType reflect.Type) *TableDef
+
+	// ColumnTypeMatches reports whether a live column already has the type of a declared column
+	ColumnTypeMatches(def *ColumnDef, col *ColumnInfo) bool
+
+	// BuildSchemaChange renders a schema change as DDL statements
+	BuildSchemaChange(change *SchemaChange) []*SqlProto
+
+	// BuildDropTable builds a DROP TABLE query, with IF EXISTS when ifExists is set
+	BuildDropTable(table string, ifExists bool) *SqlProto
+
+	// BuildTruncateTable builds a TRUNCATE TABLE query that also resets auto-increment counters
+	BuildTruncateTable(table string) *SqlProto
+
+	// BuildRenameTable builds a query renaming table to newName
+	BuildRenameTable(table, newName string) *SqlProto
+
+	// BuildAddColumn builds an ALTER TABLE query adding col
+	BuildAddColumn(table string, col *ColumnDef) *SqlProto
+
+	// BuildDropColumn builds an ALTER TABLE query dropping column
+	BuildDropColumn(table, column string) *SqlProto
+
+	// BuildRenameColumn builds an ALTER TABLE query renaming column to newName
+	BuildRenameColumn(table, column, newName string) *SqlProto
+
+	// BuildAlterColumnType builds an ALTER TABLE query changing the type of col to col.Type
+	BuildAlterColumnType(table string, col *ColumnDef) *SqlProto
+
+	// BuildCreateIndex builds a CREATE INDEX query; concurrently builds it without locking writes where supported
+	BuildCreateIndex(table string, index *IndexDef, concurrently bool) *SqlProto
+
+	// BuildDropIndex builds a DROP INDEX query; concurrently drops it without locking writes where supported
+	BuildDropIndex(table, index string, concurrently bool) *SqlProto
+
+	// HasTable reports whether table exists
+	HasTable(db Queryer, table string) (bool, error)
+
+	// HasColumn reports whether table has column
+	HasColumn(db Queryer, table, column string) (bool, error)
+
+	// HasIndex reports whether table has an index called index
+	HasIndex(db Queryer, table, index string) (bool, error)
+
 	// GetTableInfo 获取表信息
-	GetTableInfo(db *sql.DB, tableName string) (*TableInfo, error)
+	GetTableInfo(db Queryer, tableName string) (*TableInfo, error)
 
 	// GetTables 获取符合模式的所有表
 	// pattern: 表名匹配模式，支持 * 通配符
 	// 对于 PostgreSQL，pattern 可以是 schema.table 格式
-	GetTables(db *sql.DB, pattern string) ([]string, error)
+	GetTables(db Queryer, pattern string) ([]string, error)
+
+	// GetViews 获取符合模式的所有视图，不包括物化视图；pattern 的规则与 GetTables 相同
+	GetViews(db Queryer, pattern string) ([]string, error)
+
+	// GetMaterializedViews 获取符合模式的所有物化视图；不支持物化视图的数据库返回空
+	GetMaterializedViews(db Queryer, pattern string) ([]string, error)
+
+	// ListSchemas 获取所有用户 schema（MySQL 中为数据库），不包括系统 schema
+	ListSchemas(db Queryer) ([]string, error)
+
+	// BuildRefreshMaterializedView builds a query refreshing a materialized view; concurrently refreshes it without locking reads where supported
+	BuildRefreshMaterializedView(view string, concurrently bool) *SqlProto
 
 	// BuildOrderBy builds the ORDER BY clause
 	BuildOrderBy(orders []OrderBy) string
 }
 
+// IReadOnlyModel is implemented by models mapped to views; gom refuses to insert, update or delete them when ReadOnly returns true
+type IReadOnlyModel interface {
+	ReadOnly() bool
+}
+
 // ITableModel defines the interface for custom table models
 type ITableModel interface {
 	// TableName returns the custom table name
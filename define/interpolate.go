@@ -0,0 +1,122 @@
+package define
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interpolate renders proto with its arguments inlined, for logs and reviews
+// dialect is a factory type such as "mysql" or "postgres"; it selects the placeholder
+// style ($1 or ?) and the literal syntax. The result is meant to be read, not executed
+func Interpolate(dialect string, proto *SqlProto) string {
+	if proto == nil {
+		return ""
+	}
+	postgres := dialect == "postgres"
+	var sb strings.Builder
+	var quote byte
+	next := 0
+	query := proto.Sql
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '?' && !postgres:
+			if next < len(proto.Args) {
+				sb.WriteString(formatLiteral(proto.Args[next], postgres))
+				next++
+				continue
+			}
+		case ch == '$' && postgres && i+1 < len(query) && isDigit(query[i+1]):
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			n, _ := strconv.Atoi(query[i+1 : j])
+			if n >= 1 && n <= len(proto.Args) {
+				sb.WriteString(formatLiteral(proto.Args[n-1], postgres))
+				i = j - 1
+				continue
+			}
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String()
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// formatLiteral renders value as a SQL literal
+func formatLiteral(value interface{}, postgres bool) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return fmt.Sprintf("/* %v */ NULL", err)
+		}
+		value = v
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case string:
+		return quoteString(v, postgres)
+	case []byte:
+		if postgres {
+			return `'\x` + hex.EncodeToString(v) + "'"
+		}
+		return "X'" + hex.EncodeToString(v) + "'"
+	case time.Time:
+		if postgres {
+			return "'" + v.Format("2006-01-02 15:04:05.999999-07:00") + "'"
+		}
+		return "'" + v.Format("2006-01-02 15:04:05.999999") + "'"
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return formatLiteral(rv.Elem().Interface(), postgres)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Bool:
+		return formatLiteral(rv.Bool(), postgres)
+	case reflect.String:
+		return quoteString(rv.String(), postgres)
+	}
+	return quoteString(fmt.Sprint(value), postgres)
+}
+
+// quoteString quotes s as a string literal; MySQL also treats backslash as an escape
+func quoteString(s string, postgres bool) string {
+	if !postgres {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,31 @@
+package define
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolateMySQL(t *testing.T) {
+	at := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	proto := &SqlProto{
+		Sql:  "INSERT INTO `t` (`a`, `b`, `c`, `d`, `e`) VALUES (?, ?, ?, ?, ?) -- '?'",
+		Args: []interface{}{"it's a \\ test", 42, nil, at, []byte{0xde, 0xad}},
+	}
+	assert.Equal(t,
+		"INSERT INTO `t` (`a`, `b`, `c`, `d`, `e`) VALUES ('it''s a \\\\ test', 42, NULL, '2024-05-01 08:30:00', X'dead') -- '?'",
+		Interpolate("mysql", proto))
+}
+
+func TestInterpolatePostgres(t *testing.T) {
+	name := "bob"
+	var missing *string
+	proto := &SqlProto{
+		Sql:  `UPDATE "t" SET "name" = $1, "note" = $2, "ok" = $3, "raw" = $10 WHERE "tag" = '$1' AND "id" = $4`,
+		Args: []interface{}{&name, missing, false, 1.5},
+	}
+	assert.Equal(t,
+		`UPDATE "t" SET "name" = 'bob', "note" = NULL, "ok" = FALSE, "raw" = $10 WHERE "tag" = '$1' AND "id" = 1.5`,
+		Interpolate("postgres", proto))
+}
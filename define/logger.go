@@ -0,0 +1,154 @@
+package define
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"time"
+)
+
+// LogLevel controls which query events are delivered to a Logger
+type LogLevel int
+
+const (
+	// LogLevelDefault derives the effective level from DBOptions
+	LogLevelDefault LogLevel = iota
+	// LogLevelSilent disables query logging
+	LogLevelSilent
+	// LogLevelError logs failed statements only
+	LogLevelError
+	// LogLevelWarn logs failed and slow statements
+	LogLevelWarn
+	// LogLevelInfo logs every statement
+	LogLevelInfo
+	// LogLevelDebug logs every statement plus diagnostic events
+	LogLevelDebug
+)
+
+// String returns the string representation of LogLevel
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelSilent:
+		return "SILENT"
+	case LogLevelError:
+		return "ERROR"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelDebug:
+		return "DEBUG"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// Enabled reports whether an event logged at level should be emitted under l
+func (l LogLevel) Enabled(level LogLevel) bool {
+	return l > LogLevelSilent && level > LogLevelSilent && level <= l
+}
+
+// RedactedValue replaces the arguments of sensitive columns in query events
+const RedactedValue = "[REDACTED]"
+
+// QueryEvent describes a single executed statement
+type QueryEvent struct {
+	SQL      string        // Final SQL text sent to the database
+	Args     []any         // Statement arguments, sensitive values redacted
+	Duration time.Duration // Execution time
+	Rows     int64         // Rows returned or affected
+	Err      error         // Execution error, if any
+	TxID     uint64        // Transaction id, 0 when outside a transaction
+	Caller   string        // file:line of the first caller outside gom
+	Slow     bool          // Whether Duration exceeded the slow-query threshold
+}
+
+// Logger receives structured query events
+type Logger interface {
+	// LogQuery is called once per executed statement at the level chosen by gom
+	LogQuery(ctx context.Context, level LogLevel, event *QueryEvent)
+}
+
+// StdLogger writes query events through the standard log package
+type StdLogger struct {
+	logger *log.Logger
+}
+
+// NewStdLogger creates a StdLogger; a nil logger uses the log package default
+func NewStdLogger(logger *log.Logger) *StdLogger {
+	return &StdLogger{logger: logger}
+}
+
+// LogQuery implements Logger
+func (l *StdLogger) LogQuery(ctx context.Context, level LogLevel, event *QueryEvent) {
+	msg := fmt.Sprintf("[SQL] %s %v [%v, rows:%d", event.SQL, event.Args, event.Duration, event.Rows)
+	if event.TxID != 0 {
+		msg += fmt.Sprintf(", tx:%d", event.TxID)
+	}
+	if event.Caller != "" {
+		msg += ", " + event.Caller
+	}
+	msg += "]"
+	if event.Slow {
+		msg = "[SLOW] " + msg
+	}
+	if event.Err != nil {
+		msg += fmt.Sprintf(" error: %v", event.Err)
+	}
+	if l.logger != nil {
+		l.logger.Print(msg)
+	} else {
+		log.Print(msg)
+	}
+}
+
+// SlogLogger adapts a *slog.Logger to the Logger interface
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a SlogLogger; a nil logger uses slog.Default()
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogLogger{logger: logger}
+}
+
+// LogQuery implements Logger
+func (l *SlogLogger) LogQuery(ctx context.Context, level LogLevel, event *QueryEvent) {
+	attrs := []slog.Attr{
+		slog.String("sql", event.SQL),
+		slog.Any("args", event.Args),
+		slog.Duration("duration", event.Duration),
+		slog.Int64("rows", event.Rows),
+	}
+	if event.TxID != 0 {
+		attrs = append(attrs, slog.Uint64("tx_id", event.TxID))
+	}
+	if event.Caller != "" {
+		attrs = append(attrs, slog.String("caller", event.Caller))
+	}
+	if event.Slow {
+		attrs = append(attrs, slog.Bool("slow", true))
+	}
+	if event.Err != nil {
+		attrs = append(attrs, slog.String("error", event.Err.Error()))
+	}
+	l.logger.LogAttrs(ctx, slogLevel(level), "gom query", attrs...)
+}
+
+// slogLevel maps a LogLevel to the matching slog.Level
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelError:
+		return slog.LevelError
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelDebug:
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
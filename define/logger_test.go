@@ -0,0 +1,83 @@
+package define
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveLogLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		opts DBOptions
+		want LogLevel
+	}{
+		{"silent by default", DBOptions{}, LogLevelSilent},
+		{"debug enables everything", DBOptions{Debug: true}, LogLevelDebug},
+		{"logger enables warnings", DBOptions{Logger: NewStdLogger(nil)}, LogLevelWarn},
+		{"slow threshold enables warnings", DBOptions{SlowThreshold: time.Second}, LogLevelWarn},
+		{"explicit level wins", DBOptions{Debug: true, LogLevel: LogLevelError}, LogLevelError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.EffectiveLogLevel())
+		})
+	}
+}
+
+func TestLogLevelEnabled(t *testing.T) {
+	assert.False(t, LogLevelSilent.Enabled(LogLevelError))
+	assert.True(t, LogLevelWarn.Enabled(LogLevelError))
+	assert.True(t, LogLevelWarn.Enabled(LogLevelWarn))
+	assert.False(t, LogLevelWarn.Enabled(LogLevelInfo))
+	assert.True(t, LogLevelDebug.Enabled(LogLevelInfo))
+	assert.Equal(t, "WARN", LogLevelWarn.String())
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0))
+
+	logger.LogQuery(context.Background(), LogLevelWarn, &QueryEvent{
+		SQL:      "SELECT * FROM users WHERE id = ?",
+		Args:     []any{1},
+		Duration: 2 * time.Second,
+		Rows:     1,
+		TxID:     7,
+		Slow:     true,
+	})
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "[SLOW] [SQL] SELECT * FROM users WHERE id = ? [1]"))
+	assert.Contains(t, out, "tx:7")
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	logger := NewSlogLogger(slog.New(handler))
+
+	logger.LogQuery(context.Background(), LogLevelError, &QueryEvent{
+		SQL:    "DELETE FROM users",
+		Args:   []any{RedactedValue},
+		Rows:   0,
+		Err:    errors.New("boom"),
+		Caller: "main.go:10",
+	})
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "DELETE FROM users", record["sql"])
+	assert.Equal(t, "boom", record["error"])
+	assert.Equal(t, "main.go:10", record["caller"])
+	assert.Equal(t, []any{RedactedValue}, record["args"])
+}
@@ -78,6 +78,14 @@ func (f *MockSQLFactory) BuildInsert(table string, fields map[string]interface{}
 	}
 }
 
+func (f *MockSQLFactory) BuildUpsert(table string, fields map[string]interface{}, fieldOrder []string, conflictColumns []string, updateColumns []string) *SqlProto {
+	return &SqlProto{
+		SqlType: Exec,
+		Sql:     "",
+		Args:    nil,
+	}
+}
+
 func (f *MockSQLFactory) BuildBatchInsert(table string, values []map[string]interface{}) *SqlProto {
 	if len(values) == 0 {
 		return &SqlProto{
@@ -131,14 +139,90 @@ func (f *MockSQLFactory) BuildCreateTable(table string, modelType reflect.Type)
 	}
 }
 
-func (f *MockSQLFactory) GetTableInfo(db *sql.DB, tableName string) (*TableInfo, error) {
+func (f *MockSQLFactory) DescribeModel(table string, modelType reflect.Type) *TableDef {
+	return &TableDef{Name: table}
+}
+
+func (f *MockSQLFactory) ColumnTypeMatches(def *ColumnDef, col *ColumnInfo) bool {
+	return true
+}
+
+func (f *MockSQLFactory) BuildSchemaChange(change *SchemaChange) []*SqlProto {
+	return nil
+}
+
+func (f *MockSQLFactory) BuildDropTable(table string, ifExists bool) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "DROP TABLE " + table}
+}
+
+func (f *MockSQLFactory) BuildTruncateTable(table string) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "TRUNCATE TABLE " + table}
+}
+
+func (f *MockSQLFactory) BuildRenameTable(table, newName string) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "ALTER TABLE " + table + " RENAME TO " + newName}
+}
+
+func (f *MockSQLFactory) BuildAddColumn(table string, col *ColumnDef) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "ALTER TABLE " + table + " ADD COLUMN " + col.Name + " " + col.Type}
+}
+
+func (f *MockSQLFactory) BuildDropColumn(table, column string) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "ALTER TABLE " + table + " DROP COLUMN " + column}
+}
+
+func (f *MockSQLFactory) BuildRenameColumn(table, column, newName string) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "ALTER TABLE " + table + " RENAME COLUMN " + column + " TO " + newName}
+}
+
+func (f *MockSQLFactory) BuildAlterColumnType(table string, col *ColumnDef) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "ALTER TABLE " + table + " ALTER COLUMN " + col.Name + " TYPE " + col.Type}
+}
+
+func (f *MockSQLFactory) BuildCreateIndex(table string, index *IndexDef, concurrently bool) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "CREATE INDEX " + index.Name + " ON " + table}
+}
+
+func (f *MockSQLFactory) BuildDropIndex(table, index string, concurrently bool) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "DROP INDEX " + index}
+}
+
+func (f *MockSQLFactory) HasTable(db Queryer, table string) (bool, error) {
+	return false, nil
+}
+
+func (f *MockSQLFactory) HasColumn(db Queryer, table, column string) (bool, error) {
+	return false, nil
+}
+
+func (f *MockSQLFactory) HasIndex(db Queryer, table, index string) (bool, error) {
+	return false, nil
+}
+
+func (f *MockSQLFactory) GetTableInfo(db Queryer, tableName string) (*TableInfo, error) {
 	return nil, fmt.Errorf("table %s not found", tableName)
 }
 
-func (f *MockSQLFactory) GetTables(db *sql.DB, pattern string) ([]string, error) {
+func (f *MockSQLFactory) GetTables(db Queryer, pattern string) ([]string, error) {
 	return nil, nil
 }
 
+func (f *MockSQLFactory) GetViews(db Queryer, pattern string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *MockSQLFactory) GetMaterializedViews(db Queryer, pattern string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *MockSQLFactory) ListSchemas(db Queryer) ([]string, error) {
+	return nil, nil
+}
+
+func (f *MockSQLFactory) BuildRefreshMaterializedView(view string, concurrently bool) *SqlProto {
+	return &SqlProto{SqlType: Exec, Sql: "REFRESH MATERIALIZED VIEW " + view}
+}
+
 func (f *MockSQLFactory) BuildOrderBy(orders []OrderBy) string {
 	return ""
 }
@@ -73,8 +73,48 @@ type DBOptions struct {
 	// If ConnMaxIdleTime <= 0, connections are not closed due to idle time
 	ConnMaxIdleTime time.Duration
 
-	// Debug enables debug logging of SQL queries
+	// Debug enables debug logging of SQL queries for this DB only
 	Debug bool
+
+	// Logger receives structured query events
+	// If Logger is nil, events are written through the standard log package
+	Logger Logger
+
+	// LogLevel is the minimum level of query events delivered to Logger
+	// If LogLevel is LogLevelDefault, it is derived from Debug, Logger and SlowThreshold
+	LogLevel LogLevel
+
+	// SlowThreshold marks statements taking longer than this as slow and logs them at WARN
+	// If SlowThreshold <= 0, slow-query detection is disabled
+	SlowThreshold time.Duration
+
+	// StmtCacheSize is the number of prepared statements kept per DB, least recently used first out
+	// Statements built by the SQL factories are prepared once and reused, also inside transactions.
+	// If StmtCacheSize <= 0, statements are not prepared
+	StmtCacheSize int
+
+	// ResultCache stores results of chains using Cache(ttl)
+	// If ResultCache is nil, an in-process LRU cache is used
+	ResultCache Cache
+
+	// Tracer receives spans for statements, transactions and batch chunks
+	// If Tracer is nil, tracing is disabled
+	Tracer Tracer
+
+	// RetryCount is the number of times a batch chunk failing with a deadlock, serialization or
+	// connection error is retried. Every chunk runs in its own transaction that is rolled back on failure;
+	// a connection error during COMMIT is not retried, since the chunk may already be applied.
+	// If RetryCount <= 0, failed chunks are not retried
+	RetryCount int
+
+	// RetryInterval is the wait before the first retry, doubled for every further retry
+	// If RetryInterval <= 0, 100ms is used
+	RetryInterval time.Duration
+
+	// OwnPool hands the pool given to OpenDB, or the connection given to WrapConn, to gom:
+	// the pool settings above are applied and DB.Close closes it.
+	// If OwnPool is false, the caller keeps managing it. Open always owns the pool it creates
+	OwnPool bool
 }
 
 // DefaultDBOptions returns the default database options
@@ -102,5 +142,24 @@ func (o *DBOptions) Validate() error {
 	if o.ConnMaxIdleTime < 0 {
 		o.ConnMaxIdleTime = DefaultDBOptions().ConnMaxIdleTime
 	}
+	if o.SlowThreshold < 0 {
+		o.SlowThreshold = 0
+	}
 	return nil
 }
+
+// EffectiveLogLevel returns the log level used for query events
+// Debug enables every event; otherwise a configured Logger or SlowThreshold
+// enables failed and slow statements, and logging is silent by default
+func (o *DBOptions) EffectiveLogLevel() LogLevel {
+	if o.LogLevel != LogLevelDefault {
+		return o.LogLevel
+	}
+	if o.Debug {
+		return LogLevelDebug
+	}
+	if o.Logger != nil || o.SlowThreshold > 0 {
+		return LogLevelWarn
+	}
+	return LogLevelSilent
+}
@@ -0,0 +1,463 @@
+package define
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnDef describes a column as declared by a model field
+type ColumnDef struct {
+	Name          string
+	Type          string // Dialect type, e.g. VARCHAR(255)
+	Nullable      bool
+	Default       string // SQL default expression, empty for none
+	PrimaryKey    bool
+	AutoIncrement bool
+	Unique        bool
+	Comment       string
+	Extra         string // Raw SQL appended to the column definition
+
+	// Size, Precision and Scale shape the type chosen for the field, e.g. VARCHAR(Size)
+	Size      int
+	Precision int
+	Scale     int
+	Enum      []string // Allowed values of an enum column
+}
+
+// IndexDef describes an index declared by a model
+type IndexDef struct {
+	Name    string
+	Columns []string
+	Unique  bool
+
+	priorities []int // Priority of each column, see TableDef.ApplyTagOptions
+}
+
+// TableDef describes the table a model maps to, with dialect column types
+type TableDef struct {
+	Name        string
+	Columns     []*ColumnDef
+	PrimaryKeys []string
+	Indexes     []*IndexDef
+	ForeignKeys []*ForeignKeyDef
+	Options     TableOptions
+}
+
+// TableOptions are table-level DDL options; dialects ignore the ones they do not support
+type TableOptions struct {
+	Engine    string // MySQL storage engine, e.g. InnoDB
+	Charset   string // MySQL default character set, e.g. utf8mb4
+	Collation string // MySQL default collation, e.g. utf8mb4_unicode_ci
+	Comment   string
+}
+
+// ITableOptions is implemented by models that declare table options
+type ITableOptions interface {
+	TableOptions() TableOptions
+}
+
+// ModelTableOptions returns the table options declared by the model type, if any
+func ModelTableOptions(modelType reflect.Type) TableOptions {
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	if model, ok := reflect.New(modelType).Interface().(ITableOptions); ok {
+		return model.TableOptions()
+	}
+	return TableOptions{}
+}
+
+// Column returns the column named name, or nil
+func (t *TableDef) Column(name string) *ColumnDef {
+	for _, col := range t.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// SchemaChangeKind identifies a schema change
+type SchemaChangeKind string
+
+const (
+	ChangeCreateTable    SchemaChangeKind = "create_table"
+	ChangeAddColumn      SchemaChangeKind = "add_column"
+	ChangeAlterColumn    SchemaChangeKind = "alter_column"
+	ChangeDropColumn     SchemaChangeKind = "drop_column"
+	ChangeCreateIndex    SchemaChangeKind = "create_index"
+	ChangeDropIndex      SchemaChangeKind = "drop_index"
+	ChangeAddForeignKey  SchemaChangeKind = "add_foreign_key"
+	ChangeDropForeignKey SchemaChangeKind = "drop_foreign_key"
+)
+
+// SchemaChange is a step of a migration plan, rendered to SQL by SQLFactory.BuildSchemaChange
+type SchemaChange struct {
+	Kind  SchemaChangeKind
+	Table string
+
+	TableDef   *TableDef      // ChangeCreateTable
+	Column     *ColumnDef     // ChangeAddColumn and ChangeAlterColumn: the declared column
+	Current    *ColumnInfo    // ChangeAlterColumn and ChangeDropColumn: the live column
+	Index      *IndexDef      // ChangeCreateIndex and ChangeDropIndex
+	ForeignKey *ForeignKeyDef // ChangeAddForeignKey and ChangeDropForeignKey
+
+	// What a ChangeAlterColumn changes
+	TypeChanged     bool
+	NullableChanged bool
+	DefaultChanged  bool
+
+	// Destructive changes can lose data or fail on existing rows
+	Destructive bool
+	Reason      string
+}
+
+// AutoMigrateOptions enables the destructive changes of AutoMigrate
+// Disabled changes are reported in the plan as skipped instead of applied
+type AutoMigrateOptions struct {
+	// AllowDropColumns drops columns that no longer have a model field
+	AllowDropColumns bool
+
+	// AllowTypeChanges changes column types and makes nullable columns NOT NULL
+	AllowTypeChanges bool
+
+	// AllowDropIndexes drops indexes and foreign keys that the model does not declare
+	// Declared ones whose columns or actions changed are always recreated
+	AllowDropIndexes bool
+}
+
+// SplitColumnType splits a column type such as "DECIMAL(10, 2)" into its lower-cased
+// base name and numeric arguments; unparsable arguments are ignored
+func SplitColumnType(columnType string) (string, []int) {
+	base := strings.TrimSpace(columnType)
+	var args []int
+	if open := strings.IndexByte(base, '('); open >= 0 {
+		if end := strings.IndexByte(base[open:], ')'); end > 0 {
+			for _, arg := range strings.Split(base[open+1:open+end], ",") {
+				if n, err := strconv.Atoi(strings.TrimSpace(arg)); err == nil {
+					args = append(args, n)
+				}
+			}
+			base = base[:open] + base[open+end+1:]
+		} else {
+			base = base[:open]
+		}
+	}
+	return strings.ToLower(strings.Join(strings.Fields(base), " ")), args
+}
+
+// NormalizeDefault reduces a default expression to a comparable form, dropping quotes,
+// casts such as ::character varying and letter case
+func NormalizeDefault(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if i := strings.Index(expr, "::"); i >= 0 && !strings.Contains(expr[i:], "'") {
+		expr = expr[:i]
+	}
+	for len(expr) >= 2 && expr[0] == '(' && expr[len(expr)-1] == ')' {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	if len(expr) >= 2 && expr[0] == '\'' && expr[len(expr)-1] == '\'' {
+		return strings.ReplaceAll(expr[1:len(expr)-1], "''", "'")
+	}
+	switch lower := strings.ToLower(expr); lower {
+	case "now()", "current_timestamp()", "current_timestamp":
+		return "current_timestamp"
+	case "true", "false", "null":
+		return lower
+	default:
+		return expr
+	}
+}
+
+// ForeignKeyDef describes a foreign key declared by a model
+type ForeignKeyDef struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string // e.g. CASCADE, SET NULL
+	OnUpdate   string
+}
+
+// defaultIndexPriority orders the columns of a composite index without an explicit priority
+const defaultIndexPriority = 10
+
+// SplitTag splits a gom tag on commas outside quotes and parentheses, so that
+// comment:'a, b' and type:decimal(10,2) stay whole
+func SplitTag(tag string) []string {
+	var parts []string
+	var quote rune
+	depth, start := 0, 0
+	for i, r := range tag {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(tag[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(tag[start:]))
+}
+
+// TagOption splits a tag option such as index:idx_name into its key and unquoted value
+func TagOption(opt string) (string, string) {
+	key, value, _ := strings.Cut(strings.TrimSpace(opt), ":")
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return strings.TrimSpace(key), value
+}
+
+// ApplyTagOptions applies the dialect-independent schema options of a column tag:
+//
+//	type:decimal(10,2)          column type, used as is
+//	size:n                      length of string and binary columns
+//	precision:p, scale:s        precision and scale of decimal and time columns
+//	default:expr                default value, an SQL expression such as 0, 'new' or CURRENT_TIMESTAMP
+//	autoIncrement               auto-increment column
+//	notnull, null               nullability; pointers and sql.Null* types are nullable by default
+//	enum:a|b|c                  enum column with the given values
+//	unique                      unique column
+//	index, index:name           index, composite when several columns share the name
+//	uniqueIndex[:name]          unique index
+//	priority:n                  position of the column in the preceding composite index, default 10
+//	references:table(column)    foreign key, the column defaults to id
+//	onDelete:cascade            foreign key actions, also onUpdate
+//	comment:'text'              column comment
+func (t *TableDef) ApplyTagOptions(col *ColumnDef, options []string) {
+	var index *IndexDef
+	var fk *ForeignKeyDef
+	for _, opt := range options {
+		key, value := TagOption(opt)
+		switch strings.ToLower(key) {
+		case "type":
+			col.Type = value
+		case "size", "precision", "scale":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			switch strings.ToLower(key) {
+			case "size":
+				col.Size = n
+			case "precision":
+				col.Precision = n
+			default:
+				col.Scale = n
+			}
+		case "default":
+			// Keep quotes, the value is an SQL expression
+			if _, raw, ok := strings.Cut(opt, ":"); ok {
+				col.Default = strings.TrimSpace(raw)
+			}
+		case "autoincrement":
+			col.AutoIncrement = true
+		case "notnull":
+			col.Nullable = false
+		case "null":
+			col.Nullable = true
+		case "enum":
+			col.Enum = strings.Split(value, "|")
+		case "unique":
+			col.Unique = true
+		case "index", "uniqueindex":
+			unique := strings.EqualFold(key, "uniqueIndex")
+			if value == "" {
+				value = fmt.Sprintf("idx_%s_%s", strings.ReplaceAll(t.Name, ".", "_"), col.Name)
+			}
+			index = t.addIndexColumn(value, col.Name, unique)
+		case "priority":
+			if priority, err := strconv.Atoi(value); err == nil && index != nil {
+				index.setPriority(col.Name, priority)
+			}
+		case "references":
+			fk = &ForeignKeyDef{
+				Name:       fmt.Sprintf("fk_%s_%s", strings.ReplaceAll(t.Name, ".", "_"), col.Name),
+				Columns:    []string{col.Name},
+				RefTable:   value,
+				RefColumns: []string{"id"},
+			}
+			if open := strings.IndexByte(value, '('); open >= 0 && strings.HasSuffix(value, ")") {
+				fk.RefTable = strings.TrimSpace(value[:open])
+				fk.RefColumns = []string{strings.TrimSpace(value[open+1 : len(value)-1])}
+			}
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		case "ondelete", "onupdate":
+			if fk == nil {
+				continue
+			}
+			action := strings.ToUpper(strings.ReplaceAll(value, "_", " "))
+			if strings.EqualFold(key, "onDelete") {
+				fk.OnDelete = action
+			} else {
+				fk.OnUpdate = action
+			}
+		case "comment":
+			col.Comment = value
+		}
+	}
+}
+
+// IndexDefOf returns the definition of a live index
+func IndexDefOf(info *IndexInfo) *IndexDef {
+	return &IndexDef{Name: info.Name, Columns: info.Columns, Unique: info.Unique}
+}
+
+// Matches reports whether a live index has the columns and uniqueness of the definition
+func (i *IndexDef) Matches(info *IndexInfo) bool {
+	return i.Unique == info.Unique && sameNames(i.Columns, info.Columns)
+}
+
+// ForeignKeyDefOf returns the definition of a live foreign key
+func ForeignKeyDefOf(info *ForeignKeyInfo) *ForeignKeyDef {
+	return &ForeignKeyDef{Name: info.Name, Columns: info.Columns, RefTable: info.RefTable, RefColumns: info.RefColumns,
+		OnDelete: info.OnDelete, OnUpdate: info.OnUpdate}
+}
+
+// Matches reports whether a live foreign key has the columns, referenced table and actions of the definition
+// Unset actions match NO ACTION and RESTRICT, the defaults databases report
+func (fk *ForeignKeyDef) Matches(info *ForeignKeyInfo) bool {
+	return sameNames(fk.Columns, info.Columns) && sameNames(fk.RefColumns, info.RefColumns) &&
+		strings.EqualFold(unqualified(fk.RefTable), unqualified(info.RefTable)) &&
+		referentialAction(fk.OnDelete) == referentialAction(info.OnDelete) &&
+		referentialAction(fk.OnUpdate) == referentialAction(info.OnUpdate)
+}
+
+// sameNames reports whether two lists of identifiers are equal ignoring case
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// unqualified strips the schema of a table name
+func unqualified(table string) string {
+	if idx := strings.LastIndexByte(table, '.'); idx >= 0 {
+		return table[idx+1:]
+	}
+	return table
+}
+
+// referentialAction normalizes a foreign key action, treating the defaults as unset
+func referentialAction(action string) string {
+	action = strings.ToUpper(strings.Join(strings.Fields(action), " "))
+	if action == "NO ACTION" || action == "RESTRICT" {
+		return ""
+	}
+	return action
+}
+
+// addIndexColumn adds a column to the index called name, creating the index if needed
+func (t *TableDef) addIndexColumn(name, column string, unique bool) *IndexDef {
+	for _, index := range t.Indexes {
+		if index.Name == name {
+			index.Unique = index.Unique || unique
+			index.insert(column, defaultIndexPriority)
+			return index
+		}
+	}
+	index := &IndexDef{Name: name, Unique: unique}
+	index.insert(column, defaultIndexPriority)
+	t.Indexes = append(t.Indexes, index)
+	return index
+}
+
+// insert adds column after the columns with the same or a lower priority
+func (i *IndexDef) insert(column string, priority int) {
+	for len(i.priorities) < len(i.Columns) {
+		i.priorities = append(i.priorities, defaultIndexPriority)
+	}
+	pos := len(i.Columns)
+	for pos > 0 && i.priorities[pos-1] > priority {
+		pos--
+	}
+	i.Columns = append(i.Columns[:pos], append([]string{column}, i.Columns[pos:]...)...)
+	i.priorities = append(i.priorities[:pos], append([]int{priority}, i.priorities[pos:]...)...)
+}
+
+// setPriority moves column to the position given by priority
+func (i *IndexDef) setPriority(column string, priority int) {
+	for pos, name := range i.Columns {
+		if name == column {
+			i.Columns = append(i.Columns[:pos], i.Columns[pos+1:]...)
+			i.priorities = append(i.priorities[:pos], i.priorities[pos+1:]...)
+			break
+		}
+	}
+	i.insert(column, priority)
+}
+
+// Field type kinds that map to dedicated column types, see FieldTypeKind
+const (
+	FieldDecimal = "decimal"
+	FieldUUID    = "uuid"
+	FieldTime    = "time"
+	FieldBytes   = "bytes"
+	FieldJSON    = "json"
+)
+
+// nullTypes maps the sql.Null* types to the type of their value
+var nullTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(sql.NullString{}):  reflect.TypeOf(""),
+	reflect.TypeOf(sql.NullInt64{}):   reflect.TypeOf(int64(0)),
+	reflect.TypeOf(sql.NullInt32{}):   reflect.TypeOf(int32(0)),
+	reflect.TypeOf(sql.NullInt16{}):   reflect.TypeOf(int16(0)),
+	reflect.TypeOf(sql.NullByte{}):    reflect.TypeOf(uint8(0)),
+	reflect.TypeOf(sql.NullFloat64{}): reflect.TypeOf(float64(0)),
+	reflect.TypeOf(sql.NullBool{}):    reflect.TypeOf(false),
+	reflect.TypeOf(sql.NullTime{}):    reflect.TypeOf(time.Time{}),
+}
+
+// UnwrapFieldType dereferences pointers and replaces sql.Null* types with the type of their
+// value; it reports whether the field can hold NULL
+func UnwrapFieldType(t reflect.Type) (reflect.Type, bool) {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	if valueType, ok := nullTypes[t]; ok {
+		return valueType, true
+	}
+	return t, nullable
+}
+
+// FieldTypeKind classifies field types that need a dedicated column type: decimal types
+// such as decimal.Decimal, UUID types, time.Time, []byte, and maps, slices and structs stored as JSON
+// It returns an empty string for other types
+func FieldTypeKind(t reflect.Type) string {
+	t, _ = UnwrapFieldType(t)
+	switch {
+	case t.Name() == "Decimal":
+		return FieldDecimal
+	case t.Name() == "UUID":
+		return FieldUUID
+	case t == reflect.TypeOf(time.Time{}):
+		return FieldTime
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return FieldBytes
+	case t.Kind() == reflect.Map, t.Kind() == reflect.Slice, t.Kind() == reflect.Array, t.Kind() == reflect.Struct:
+		return FieldJSON
+	}
+	return ""
+}
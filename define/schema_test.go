@@ -0,0 +1,78 @@
+package define
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTag(t *testing.T) {
+	assert.Equal(t, []string{"name", "comment:'a, b'", "index"}, SplitTag(`name, comment:'a, b' ,index`))
+	assert.Equal(t, []string{"name", `comment:"x,y"`}, SplitTag(`name,comment:"x,y"`))
+
+	key, value := TagOption(`comment:"x,y"`)
+	assert.Equal(t, "comment", key)
+	assert.Equal(t, "x,y", value)
+}
+
+func TestApplyTagOptionsCompositeIndex(t *testing.T) {
+	def := &TableDef{Name: "events"}
+	a, b, c := &ColumnDef{Name: "a"}, &ColumnDef{Name: "b"}, &ColumnDef{Name: "c"}
+	def.ApplyTagOptions(a, []string{"uniqueIndex:uk_abc", "priority:3"})
+	def.ApplyTagOptions(b, []string{"index:uk_abc"})
+	def.ApplyTagOptions(c, []string{"index:uk_abc", "priority:1", "references:accounts", "onUpdate:set_null"})
+
+	if assert.Len(t, def.Indexes, 1) {
+		assert.True(t, def.Indexes[0].Unique)
+		assert.Equal(t, []string{"c", "a", "b"}, def.Indexes[0].Columns)
+	}
+	if assert.Len(t, def.ForeignKeys, 1) {
+		assert.Equal(t, "accounts", def.ForeignKeys[0].RefTable)
+		assert.Equal(t, []string{"id"}, def.ForeignKeys[0].RefColumns)
+		assert.Equal(t, "SET NULL", def.ForeignKeys[0].OnUpdate)
+	}
+}
+
+func TestSplitColumnType(t *testing.T) {
+	base, args := SplitColumnType("DECIMAL(10, 2)")
+	assert.Equal(t, "decimal", base)
+	assert.Equal(t, []int{10, 2}, args)
+
+	base, args = SplitColumnType("INTEGER  UNSIGNED")
+	assert.Equal(t, "integer unsigned", base)
+	assert.Empty(t, args)
+}
+
+func TestApplyTagOptionsColumnShape(t *testing.T) {
+	def := &TableDef{Name: "items"}
+	col := &ColumnDef{Name: "price", Nullable: true}
+	def.ApplyTagOptions(col, SplitTag(`precision:12,scale:2,default:'0.00',notnull`))
+	assert.Equal(t, 12, col.Precision)
+	assert.Equal(t, 2, col.Scale)
+	assert.Equal(t, "'0.00'", col.Default)
+	assert.False(t, col.Nullable)
+
+	col = &ColumnDef{Name: "state"}
+	def.ApplyTagOptions(col, SplitTag(`type:varchar(16),enum:new|paid|shipped,null`))
+	assert.Equal(t, "varchar(16)", col.Type)
+	assert.Equal(t, []string{"new", "paid", "shipped"}, col.Enum)
+	assert.True(t, col.Nullable)
+}
+
+func TestFieldTypeKind(t *testing.T) {
+	type uuid [16]byte
+	type UUID uuid
+	assert.Equal(t, FieldTime, FieldTypeKind(reflect.TypeOf(&time.Time{})))
+	assert.Equal(t, FieldBytes, FieldTypeKind(reflect.TypeOf([]byte{})))
+	assert.Equal(t, FieldJSON, FieldTypeKind(reflect.TypeOf(map[string]interface{}{})))
+	assert.Equal(t, FieldJSON, FieldTypeKind(reflect.TypeOf([]string{})))
+	assert.Equal(t, FieldUUID, FieldTypeKind(reflect.TypeOf(UUID{})))
+	assert.Equal(t, "", FieldTypeKind(reflect.TypeOf("")))
+
+	valueType, nullable := UnwrapFieldType(reflect.TypeOf(sql.NullInt64{}))
+	assert.Equal(t, reflect.Int64, valueType.Kind())
+	assert.True(t, nullable)
+}
@@ -0,0 +1,196 @@
+package define
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Span attribute keys set by gom, following the OpenTelemetry database conventions
+const (
+	AttrDBSystem      = "db.system"            // Dialect, e.g. mysql or postgres
+	AttrDBOperation   = "db.operation"         // Statement verb, e.g. SELECT
+	AttrDBTable       = "db.sql.table"         // Table the statement targets
+	AttrDBStatement   = "db.statement"         // SQL text without argument values
+	AttrDBRows        = "db.rows_affected"     // Rows returned or affected
+	AttrTxID          = "gom.tx_id"            // Transaction id
+	AttrTxOutcome     = "gom.tx.outcome"       // commit or rollback
+	AttrBatchChunk    = "gom.batch.chunk"      // Chunk number within a batch operation
+	AttrBatchSize     = "gom.batch.size"       // Rows in a batch or chunk
+	AttrBatchParallel = "gom.batch.concurrent" // Whether chunks run concurrently
+)
+
+// Attribute is a key/value pair attached to a span
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr creates an Attribute
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is a unit of traced work
+type Span interface {
+	// SetAttributes adds attributes to the span
+	SetAttributes(attrs ...Attribute)
+	// RecordError marks the span as failed; nil errors are ignored
+	RecordError(err error)
+	// End finishes the span
+	End()
+}
+
+// Tracer starts spans
+// The parent span, if any, is taken from ctx and the returned context carries the new span.
+// The interface mirrors OpenTelemetry's trace.Tracer, so an adapter is a thin wrapper
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// SpanData is a finished span produced by SimpleTracer
+type SpanData struct {
+	TraceID    uint64
+	SpanID     uint64
+	ParentID   uint64 // 0 for root spans
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]any
+	Err        error
+}
+
+// SpanExporter receives spans finished by SimpleTracer
+type SpanExporter interface {
+	ExportSpan(span *SpanData)
+}
+
+// SimpleTracer is a dependency-free Tracer that hands finished spans to an exporter
+type SimpleTracer struct {
+	exporter SpanExporter
+	nextID   uint64
+}
+
+// NewSimpleTracer creates a SimpleTracer exporting to exporter
+func NewSimpleTracer(exporter SpanExporter) *SimpleTracer {
+	return &SimpleTracer{exporter: exporter}
+}
+
+type simpleSpanKey struct{}
+
+// Start implements Tracer
+func (t *SimpleTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	span := &simpleSpan{
+		tracer: t,
+		data: SpanData{
+			SpanID:     atomic.AddUint64(&t.nextID, 1),
+			Name:       name,
+			Start:      time.Now(),
+			Attributes: make(map[string]any, len(attrs)),
+		},
+	}
+	if parent, ok := ctx.Value(simpleSpanKey{}).(*simpleSpan); ok && parent.tracer == t {
+		span.data.TraceID = parent.data.TraceID
+		span.data.ParentID = parent.data.SpanID
+	} else {
+		span.data.TraceID = span.data.SpanID
+	}
+	span.SetAttributes(attrs...)
+	return context.WithValue(ctx, simpleSpanKey{}, span), span
+}
+
+// simpleSpan is the Span implementation of SimpleTracer
+type simpleSpan struct {
+	mu     sync.Mutex
+	tracer *SimpleTracer
+	data   SpanData
+	ended  bool
+}
+
+// SetAttributes implements Span
+func (s *simpleSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attr := range attrs {
+		s.data.Attributes[attr.Key] = attr.Value
+	}
+}
+
+// RecordError implements Span
+func (s *simpleSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err
+}
+
+// End implements Span; only the first call exports the span
+func (s *simpleSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Attributes = make(map[string]any, len(s.data.Attributes))
+	for k, v := range s.data.Attributes {
+		data.Attributes[k] = v
+	}
+	s.mu.Unlock()
+
+	if s.tracer.exporter != nil {
+		s.tracer.exporter.ExportSpan(&data)
+	}
+}
+
+// InMemoryExporter keeps finished spans in memory, mainly for tests
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+// NewInMemoryExporter creates an empty InMemoryExporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan implements SpanExporter
+func (e *InMemoryExporter) ExportSpan(span *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the exported spans in the order they finished
+func (e *InMemoryExporter) Spans() []*SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	spans := make([]*SpanData, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// Reset discards the exported spans
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// noopSpan is used when tracing is disabled
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// NoopSpan is a Span that does nothing
+var NoopSpan Span = noopSpan{}
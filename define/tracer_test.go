@@ -0,0 +1,40 @@
+package define
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleTracerNesting(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewSimpleTracer(exporter)
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, child := tracer.Start(ctx, "child", Attr(AttrDBTable, "users"))
+	child.RecordError(errors.New("boom"))
+	child.End()
+	child.End()
+	parent.End()
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentID)
+	assert.Equal(t, spans[1].TraceID, spans[0].TraceID)
+	assert.Equal(t, uint64(0), spans[1].ParentID)
+	assert.Equal(t, "users", spans[0].Attributes[AttrDBTable])
+	assert.EqualError(t, spans[0].Err, "boom")
+
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestSqlProtoOperation(t *testing.T) {
+	assert.Equal(t, "SELECT", (&SqlProto{Sql: "  select * from users"}).Operation())
+	assert.Equal(t, "INSERT", (&SqlProto{Sql: "INSERT INTO users"}).Operation())
+	assert.Equal(t, "SELECT", (&SqlProto{Sql: "(SELECT 1)"}).Operation())
+	assert.Equal(t, "", (&SqlProto{}).Operation())
+}
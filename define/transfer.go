@@ -2,6 +2,7 @@ package define
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -109,7 +110,7 @@ func GetTransfer(model interface{}) *Transfer {
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
 		tag := field.Tag.Get("gom")
-		if tag == "" {
+		if tag == "" || tag == "-" {
 			continue
 		}
 
@@ -230,6 +231,75 @@ func (t *Transfer) ToMap(model interface{}, isUpdate ...bool) map[string]interfa
 	return result
 }
 
+// ToUpsertMap converts a struct to a map of every column for an upsert
+// Unlike ToMap, zero values are kept so that the update clause resets them, and nil pointers become NULL.
+// Auto-increment fields are included only when set; zero times are left out so the column keeps its value
+func (t *Transfer) ToUpsertMap(model interface{}) map[string]interface{} {
+	if model == nil {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	modelValue := reflect.ValueOf(model)
+	if modelValue.Kind() == reflect.Ptr {
+		if modelValue.IsNil() {
+			return nil
+		}
+		modelValue = modelValue.Elem()
+	}
+
+	result := make(map[string]interface{})
+	for _, columnName := range t.FieldOrder {
+		fieldInfo := t.Fields[columnName]
+		if fieldInfo == nil {
+			continue
+		}
+		fieldValue := modelValue.Field(fieldInfo.Index)
+		if !fieldValue.IsValid() || (fieldInfo.IsAuto && fieldValue.IsZero()) {
+			continue
+		}
+		if value := t.convertFieldToDBValue(fieldValue); value != nil {
+			result[columnName] = value
+		} else if value, ok := zeroDBValue(fieldValue); ok {
+			result[columnName] = value
+		}
+	}
+	return result
+}
+
+// zeroDBValue returns the database value of a field that convertFieldToDBValue leaves out,
+// and false when the column should be left out, as for zero times
+func zeroDBValue(field reflect.Value) (interface{}, bool) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil, true
+		}
+		return zeroDBValue(field.Elem())
+	}
+	if field.CanInterface() {
+		if valuer, ok := field.Interface().(driver.Valuer); ok {
+			return valuer, true
+		}
+	}
+
+	switch field.Kind() {
+	case reflect.Bool:
+		return int(0), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(0), true
+	case reflect.Float32, reflect.Float64:
+		return float64(0), true
+	case reflect.String:
+		return "", true
+	case reflect.Slice, reflect.Map, reflect.Interface:
+		return nil, true
+	}
+	return nil, false
+}
+
 // convertFieldToDBValue converts a field value to its database representation
 func (t *Transfer) convertFieldToDBValue(field reflect.Value) interface{} {
 	if !field.IsValid() {
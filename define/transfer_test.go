@@ -1,6 +1,7 @@
 package define
 
 import (
+	"database/sql"
 	"testing"
 	"time"
 
@@ -219,6 +220,38 @@ func TestFieldConversion(t *testing.T) {
 	})
 }
 
+// Model with relation fields ignored by gom
+type TestModelWithRelations struct {
+	ID     int64                   `gom:"id,@"`
+	Orders []TestModelNoTable      `gom:"-" relation:"has_many,foreignKey:user_id,references:id"`
+	Parent *TestModelWithRelations `gom:"-" relation:"belongs_to,foreignKey:parent_id,references:id"`
+}
+
+func TestGetTransferIgnoredFields(t *testing.T) {
+	transfer := GetTransfer(&TestModelWithRelations{})
+	assert.Equal(t, []string{"id"}, transfer.GetFieldNames())
+	assert.Equal(t, map[string]interface{}{"id": int64(1)}, transfer.ToMap(&TestModelWithRelations{ID: 1}))
+}
+
+func TestToUpsertMapKeepsZeroValues(t *testing.T) {
+	type upsertModel struct {
+		ID        int64           `gom:"id,@"`
+		Name      string          `gom:"name"`
+		IsAdmin   bool            `gom:"is_admin"`
+		Score     float64         `gom:"score"`
+		Nickname  *sql.NullString `gom:"nickname"`
+		Count     *int            `gom:"count"`
+		CreatedAt time.Time       `gom:"created_at"`
+	}
+	transfer := GetTransfer(&upsertModel{})
+	zero := 0
+	assert.Equal(t, map[string]interface{}{
+		"name": "", "is_admin": 0, "score": float64(0), "nickname": nil, "count": int64(0),
+	}, transfer.ToUpsertMap(&upsertModel{Count: &zero}), "auto-increment and zero time fields are left out")
+	assert.Equal(t, int64(7), transfer.ToUpsertMap(&upsertModel{ID: 7})["id"])
+	assert.Nil(t, transfer.ToUpsertMap(nil))
+}
+
 func TestNilModel(t *testing.T) {
 	t.Run("GetTransfer", func(t *testing.T) {
 		assert.Nil(t, GetTransfer(nil))
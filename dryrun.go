@@ -0,0 +1,184 @@
+package gom
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// dryRunRecorder collects the statements a dry-run chain would execute
+type dryRunRecorder struct {
+	mu     sync.Mutex
+	protos []*define.SqlProto
+}
+
+// record stores a copy of proto so later changes by the caller do not leak in
+func (r *dryRunRecorder) record(proto *define.SqlProto) {
+	copied := *proto
+	copied.Args = append([]interface{}(nil), proto.Args...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.protos = append(r.protos, &copied)
+}
+
+// statements returns the recorded statements in execution order
+func (r *dryRunRecorder) statements() []*define.SqlProto {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*define.SqlProto(nil), r.protos...)
+}
+
+// DryRun makes the chain record statements instead of executing them
+// Statements are built exactly as they would run, including sensitive-field processing,
+// encryption and bool conversion. Interceptors, hooks and the result cache are skipped,
+// no transaction is opened and reads return empty results
+func (c *Chain) DryRun() *Chain {
+	if c.dryRun == nil {
+		c.dryRun = &dryRunRecorder{}
+	}
+	return c
+}
+
+// Statements returns the statements recorded since DryRun was called
+func (c *Chain) Statements() []*define.SqlProto {
+	if c.dryRun == nil {
+		return nil
+	}
+	return c.dryRun.statements()
+}
+
+// ToSQL returns the SELECT the chain would run for List
+func (c *Chain) ToSQL() ([]*define.SqlProto, error) {
+	return c.toSQL(func(dc *Chain) error {
+		return dc.List().Error
+	})
+}
+
+// InsertSQL returns the statements Insert would execute for model
+func (c *Chain) InsertSQL(model interface{}) ([]*define.SqlProto, error) {
+	return c.toSQL(func(dc *Chain) error {
+		return dc.Insert(copyModel(model)).Error
+	})
+}
+
+// UpsertSQL returns the statements Upsert would execute for model
+func (c *Chain) UpsertSQL(model interface{}, conflictColumns ...string) ([]*define.SqlProto, error) {
+	return c.toSQL(func(dc *Chain) error {
+		return dc.Upsert(copyModel(model), conflictColumns...).Error
+	})
+}
+
+// UpdateSQL returns the statements Update would execute
+func (c *Chain) UpdateSQL(models ...interface{}) ([]*define.SqlProto, error) {
+	return c.toSQL(func(dc *Chain) error {
+		return dc.Update(copyModels(models)...).Error
+	})
+}
+
+// DeleteSQL returns the statements Delete would execute
+func (c *Chain) DeleteSQL(models ...interface{}) ([]*define.SqlProto, error) {
+	return c.toSQL(func(dc *Chain) error {
+		return dc.Delete(copyModels(models)...).Error
+	})
+}
+
+// BatchInsertSQL returns the statements BatchInsert would execute, one per batch
+func (c *Chain) BatchInsertSQL(batchSize int) ([]*define.SqlProto, error) {
+	return c.toSQL(func(dc *Chain) error {
+		_, err := dc.BatchInsert(batchSize, false)
+		return err
+	})
+}
+
+// CountSQL returns the statement Count would execute
+func (c *Chain) CountSQL() ([]*define.SqlProto, error) {
+	return c.toSQL(func(dc *Chain) error {
+		_, err := dc.Count()
+		return err
+	})
+}
+
+// PageInfoSQL returns the COUNT and page SELECT statements PageInfo would execute
+func (c *Chain) PageInfoSQL(models ...interface{}) ([]*define.SqlProto, error) {
+	return c.toSQL(func(dc *Chain) error {
+		_, err := dc.PageInfo(models...)
+		return err
+	})
+}
+
+// toSQL runs op on a dry-run copy of the chain and returns the recorded statements
+// The copy owns its field and condition state, so c can still be executed afterwards
+func (c *Chain) toSQL(op func(dc *Chain) error) ([]*define.SqlProto, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.factory == nil {
+		return nil, errors.New("SQL factory is not initialized")
+	}
+	dc := c.dryRunCopy()
+	if err := op(dc); err != nil {
+		return nil, err
+	}
+	return dc.Statements(), nil
+}
+
+// dryRunCopy returns a dry-run chain with copies of the mutable state of c
+func (c *Chain) dryRunCopy() *Chain {
+	dc := c.clone()
+	dc.rawSQL = c.rawSQL
+	dc.args = c.args
+	dc.model = c.model
+	dc.encryptionConfig = c.encryptionConfig
+	dc.conds = append([]*define.Condition(nil), c.conds...)
+	dc.fieldList = append([]string(nil), c.fieldList...)
+	dc.orderByExprs = append([]define.OrderBy(nil), c.orderByExprs...)
+	dc.fieldOrder = append([]string(nil), c.fieldOrder...)
+	if c.fieldMap != nil {
+		dc.fieldMap = copyFields(c.fieldMap)
+	}
+	if c.batchValues != nil {
+		dc.batchValues = make([]map[string]interface{}, len(c.batchValues))
+		for i, values := range c.batchValues {
+			dc.batchValues[i] = copyFields(values)
+		}
+	}
+	dc.dryRun = &dryRunRecorder{}
+	return dc
+}
+
+// copyFields returns a shallow copy of fields
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+	return copied
+}
+
+// copyModel copies a struct pointer so encryption during a dry run leaves the caller's model untouched
+func copyModel(model interface{}) interface{} {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return model
+	}
+	copied := reflect.New(v.Elem().Type())
+	copied.Elem().Set(v.Elem())
+	return copied.Interface()
+}
+
+// copyModels applies copyModel to each model
+func copyModels(models []interface{}) []interface{} {
+	copied := make([]interface{}, len(models))
+	for i, model := range models {
+		copied[i] = copyModel(model)
+	}
+	return copied
+}
+
+// Interpolate renders proto with its arguments inlined using the DB's dialect
+// Use it to log or review statements returned by DryRun and the *SQL methods
+func (db *DB) Interpolate(proto *define.SqlProto) string {
+	return define.Interpolate(db.Factory.GetType(), proto)
+}
@@ -0,0 +1,166 @@
+package gom
+
+import (
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/factory/mysql"
+	"github.com/kmlixh/gom/v4/factory/postgres"
+	"github.com/stretchr/testify/assert"
+)
+
+type dryRunUser struct {
+	ID     int64  `gom:"id,@"`
+	Name   string `gom:"name"`
+	Email  string `gom:"email"`
+	Active bool   `gom:"active"`
+}
+
+func (dryRunUser) TableName() string { return "users" }
+
+// newDryRunDB returns a DB without a connection, so executing any statement panics
+func newDryRunDB(t *testing.T, factory define.SQLFactory) *DB {
+	return &DB{Factory: factory, plugins: &plugins{}}
+}
+
+func TestInsertSQLOrdersColumnsAndConvertsBools(t *testing.T) {
+	db := newDryRunDB(t, &mysql.Factory{})
+	user := &dryRunUser{Name: "ann", Email: "ann@example.com", Active: true}
+
+	protos, err := db.Chain().InsertSQL(user)
+	assert.NoError(t, err)
+	assert.Len(t, protos, 1)
+	assert.Equal(t, "INSERT INTO `users` (`name`, `email`, `active`) VALUES (?, ?, ?)", protos[0].Sql)
+	assert.Equal(t, []interface{}{"ann", "ann@example.com", 1}, protos[0].Args)
+	assert.Equal(t, "users", protos[0].Table)
+}
+
+func TestDryRunRecordsWithoutExecuting(t *testing.T) {
+	db := newDryRunDB(t, &mysql.Factory{})
+	chain := db.Chain().DryRun()
+
+	assert.NoError(t, chain.Table("users").Where("id", define.OpEq, 7).Delete().Error)
+	count, err := db.Chain().DryRun().Table("users").Count()
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	statements := chain.Statements()
+	assert.Len(t, statements, 1)
+	assert.Equal(t, "DELETE FROM `users` WHERE `id` = ?", statements[0].Sql)
+}
+
+func TestEncryptedInsertSQLLeavesModelUntouched(t *testing.T) {
+	type secretUser struct {
+		ID     int64  `gom:"id,@"`
+		Secret string `gom:"secret,encrypt"`
+	}
+	db := newDryRunDB(t, &mysql.Factory{})
+	user := &secretUser{Secret: "plain"}
+
+	t.Setenv("GOM_DRY_RUN_KEY", "0123456789abcdef0123456789abcdef")
+
+	protos, err := db.Chain().Table("secrets").SetEncryptionConfig(&EncryptionConfig{
+		Algorithm:       AES256,
+		KeySource:       KeySourceEnv,
+		KeySourceConfig: map[string]string{"key_name": "GOM_DRY_RUN_KEY"},
+	}).InsertSQL(user)
+	assert.NoError(t, err)
+	assert.Len(t, protos, 1)
+	assert.NotEqual(t, "plain", protos[0].Args[0])
+	assert.Equal(t, "plain", user.Secret)
+}
+
+func TestUpdateAndDeleteSQLDoNotConsumeChain(t *testing.T) {
+	db := newDryRunDB(t, &mysql.Factory{})
+	chain := db.Chain().Table("users").Where("id", define.OpEq, 1).Set("name", "bob")
+
+	update, err := chain.UpdateSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE `users` SET `name` = ? WHERE `id` = ?", update[0].Sql)
+	assert.Equal(t, []interface{}{"bob", 1}, update[0].Args)
+
+	del, err := chain.DeleteSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM `users` WHERE `id` = ?", del[0].Sql)
+	assert.Equal(t, map[string]interface{}{"name": "bob"}, chain.fieldMap)
+}
+
+func TestFromSkipsIgnoredFields(t *testing.T) {
+	type order struct {
+		ID     int64 `gom:"id,@"`
+		UserID int64 `gom:"user_id"`
+	}
+	type userWithOrders struct {
+		ID     int64    `gom:"id,!"`
+		Name   string   `gom:"name"`
+		Orders []*order `gom:"-" relation:"has_many,foreign_key=user_id"`
+		Owner  *order   `gom:"-" relation:"belongs_to,foreign_key=id"`
+	}
+	db := newDryRunDB(t, &mysql.Factory{})
+	user := &userWithOrders{ID: 1, Name: "ann", Orders: []*order{{ID: 2, UserID: 1}}}
+
+	update, err := db.Chain().Table("users").From(user).Where("id", define.OpEq, 1).UpdateSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE `users` SET `id` = ?, `name` = ? WHERE `id` = ?", update[0].Sql)
+	assert.NotContains(t, update[0].Sql, "`-`")
+}
+
+func TestBatchInsertSQLRecordsEachBatchInOrder(t *testing.T) {
+	db := newDryRunDB(t, &mysql.Factory{})
+	chain := db.Chain().Table("users").BatchValues([]map[string]interface{}{
+		{"name": "a", "age": 1},
+		{"name": "b", "age": 2},
+		{"name": "c", "age": 3},
+	})
+
+	protos, err := chain.BatchInsertSQL(2)
+	assert.NoError(t, err)
+	assert.Len(t, protos, 2)
+	assert.Equal(t, "INSERT INTO `users` (`age`, `name`) VALUES (?, ?), (?, ?)", protos[0].Sql)
+	assert.Equal(t, []interface{}{3, "c"}, protos[1].Args)
+}
+
+func TestUpsertSQL(t *testing.T) {
+	user := &dryRunUser{ID: 3, Name: "ann", Email: "ann@example.com", Active: true}
+
+	mysqlProtos, err := newDryRunDB(t, &mysql.Factory{}).Chain().UpsertSQL(user)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO `users` (`id`, `name`, `email`, `active`) VALUES (?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `email` = VALUES(`email`), `active` = VALUES(`active`)", mysqlProtos[0].Sql)
+
+	pgProtos, err := newDryRunDB(t, &postgres.Factory{}).Chain().UpsertSQL(user, "email")
+	assert.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "users" ("id", "name", "email", "active") VALUES ($1, $2, $3, $4) `+
+		`ON CONFLICT ("email") DO UPDATE SET "id" = EXCLUDED."id", "name" = EXCLUDED."name", "active" = EXCLUDED."active" RETURNING *`, pgProtos[0].Sql)
+
+	// 零值同样写入，已有行会被重置
+	reset, err := newDryRunDB(t, &mysql.Factory{}).Chain().UpsertSQL(&dryRunUser{ID: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO `users` (`id`, `name`, `email`, `active`) VALUES (?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `email` = VALUES(`email`), `active` = VALUES(`active`)", reset[0].Sql)
+	assert.Equal(t, []interface{}{int64(3), "", "", 0}, reset[0].Args)
+}
+
+func TestPageInfoSQL(t *testing.T) {
+	db := newDryRunDB(t, &postgres.Factory{})
+
+	protos, err := db.Chain().Table("users").Where("active", define.OpEq, true).Page(2, 20).PageInfoSQL()
+	assert.NoError(t, err)
+	assert.Len(t, protos, 2)
+	assert.Contains(t, protos[0].Sql, "COUNT(*)")
+	assert.Contains(t, protos[1].Sql, "LIMIT 20 OFFSET 20")
+	assert.Equal(t, `SELECT COUNT(*) as count FROM "users" WHERE "active" = TRUE`, db.Interpolate(protos[0]))
+}
+
+func TestApplyListOptions(t *testing.T) {
+	db := newDryRunDB(t, &mysql.Factory{})
+	proto := db.Chain().Table("users").Eq("active", true).ApplyListOptions(ListOptions{
+		Where:   []*define.Condition{define.Like("name", "a%")},
+		OrderBy: []define.OrderBy{{Field: "id", Type: define.OrderDesc}},
+		Limit:   10,
+		Offset:  20,
+	}).BuildSelect()
+	assert.NoError(t, proto.Error)
+	assert.Equal(t, "SELECT * FROM `users` WHERE `active` = ? AND `name` LIKE ? ORDER BY `id` DESC LIMIT 10 OFFSET 20", proto.Sql)
+	assert.Equal(t, []interface{}{true, "a%"}, proto.Args)
+}
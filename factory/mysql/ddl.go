@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// ddlProto wraps a DDL statement on table, failing when the table name is empty
+func ddlProto(table, query string) *define.SqlProto {
+	if table == "" {
+		return &define.SqlProto{SqlType: define.Exec, Error: define.ErrEmptyTableName}
+	}
+	return &define.SqlProto{SqlType: define.Exec, Sql: query, Table: table}
+}
+
+// BuildDropTable builds a DROP TABLE query for MySQL
+func (f *Factory) BuildDropTable(table string, ifExists bool) *define.SqlProto {
+	query := "DROP TABLE "
+	if ifExists {
+		query += "IF EXISTS "
+	}
+	return ddlProto(table, query+"`"+table+"`")
+}
+
+// BuildTruncateTable builds a TRUNCATE TABLE query for MySQL, which always resets AUTO_INCREMENT
+func (f *Factory) BuildTruncateTable(table string) *define.SqlProto {
+	return ddlProto(table, fmt.Sprintf("TRUNCATE TABLE `%s`", table))
+}
+
+// BuildRenameTable builds a RENAME TABLE query for MySQL
+func (f *Factory) BuildRenameTable(table, newName string) *define.SqlProto {
+	if newName == "" {
+		return &define.SqlProto{SqlType: define.Exec, Error: define.ErrEmptyTableName}
+	}
+	return ddlProto(table, fmt.Sprintf("RENAME TABLE `%s` TO `%s`", table, newName))
+}
+
+// BuildAddColumn builds an ALTER TABLE ... ADD COLUMN query for MySQL
+func (f *Factory) BuildAddColumn(table string, col *define.ColumnDef) *define.SqlProto {
+	if col.Type == "" {
+		return &define.SqlProto{SqlType: define.Exec, Error: fmt.Errorf("column %s has no type", col.Name)}
+	}
+	return ddlProto(table, fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN %s", table, columnSQL(col, false)))
+}
+
+// BuildDropColumn builds an ALTER TABLE ... DROP COLUMN query for MySQL
+func (f *Factory) BuildDropColumn(table, column string) *define.SqlProto {
+	return ddlProto(table, fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`", table, column))
+}
+
+// BuildRenameColumn builds an ALTER TABLE ... RENAME COLUMN query, supported since MySQL 8.0
+func (f *Factory) BuildRenameColumn(table, column, newName string) *define.SqlProto {
+	return ddlProto(table, fmt.Sprintf("ALTER TABLE `%s` RENAME COLUMN `%s` TO `%s`", table, column, newName))
+}
+
+// BuildAlterColumnType builds an ALTER TABLE ... MODIFY COLUMN query for MySQL
+// MODIFY restates the whole column, so the nullability, default and comment of col apply as well
+func (f *Factory) BuildAlterColumnType(table string, col *define.ColumnDef) *define.SqlProto {
+	if col.Type == "" {
+		return &define.SqlProto{SqlType: define.Exec, Error: fmt.Errorf("column %s has no type", col.Name)}
+	}
+	return ddlProto(table, fmt.Sprintf("ALTER TABLE `%s` MODIFY COLUMN %s", table, columnSQL(col, false)))
+}
+
+// BuildCreateIndex builds a CREATE INDEX query for MySQL
+// concurrently is ignored, InnoDB builds secondary indexes online
+func (f *Factory) BuildCreateIndex(table string, index *define.IndexDef, concurrently bool) *define.SqlProto {
+	if len(index.Columns) == 0 {
+		return &define.SqlProto{SqlType: define.Exec, Error: fmt.Errorf("index %s has no columns", index.Name)}
+	}
+	return ddlProto(table, createIndexSQL(table, index))
+}
+
+// BuildDropIndex builds a DROP INDEX query for MySQL; concurrently is ignored
+func (f *Factory) BuildDropIndex(table, index string, concurrently bool) *define.SqlProto {
+	return ddlProto(table, fmt.Sprintf("DROP INDEX `%s` ON `%s`", index, table))
+}
+
+// BuildRefreshMaterializedView fails, MySQL has no materialized views
+func (f *Factory) BuildRefreshMaterializedView(view string, concurrently bool) *define.SqlProto {
+	return &define.SqlProto{SqlType: define.Exec, Error: errors.New("mysql does not support materialized views")}
+}
+
+// HasTable reports whether table exists in the current database
+func (f *Factory) HasTable(db define.Queryer, table string) (bool, error) {
+	return exists(db, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", table)
+}
+
+// HasColumn reports whether table has column
+func (f *Factory) HasColumn(db define.Queryer, table, column string) (bool, error) {
+	return exists(db, "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?", table, column)
+}
+
+// HasIndex reports whether table has an index called index; PRIMARY is the primary key
+func (f *Factory) HasIndex(db define.Queryer, table, index string) (bool, error) {
+	return exists(db, "SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?", table, index)
+}
+
+// exists runs a COUNT query and reports whether it counted any rows
+func exists(db define.Queryer, query string, args ...interface{}) (bool, error) {
+	if db == nil {
+		return false, errors.New("database connection is nil")
+	}
+	var count int64
+	if err := db.QueryRowContext(context.Background(), query, args...).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -11,7 +12,7 @@ import (
 	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/kmlixh/gom/v4/define"
 )
 
@@ -437,6 +438,40 @@ func (f *Factory) BuildBatchInsert(table string, values []map[string]interface{}
 	}
 }
 
+// BuildUpsert builds an INSERT ... ON DUPLICATE KEY UPDATE query for MySQL
+// MySQL resolves conflicts against every unique key, so conflictColumns only keep those columns out of the update list
+func (f *Factory) BuildUpsert(table string, fields map[string]interface{}, fieldOrder []string, conflictColumns []string, updateColumns []string) *define.SqlProto {
+	proto := f.BuildInsert(table, fields, fieldOrder)
+	if proto.Error != nil {
+		return proto
+	}
+
+	var sets []string
+	for _, col := range updateColumns {
+		sets = append(sets, fmt.Sprintf("`%s` = VALUES(`%s`)", col, col))
+	}
+	if len(sets) == 0 {
+		// Nothing to update: assign a column to itself so the statement still succeeds on duplicates
+		col := ""
+		switch {
+		case len(conflictColumns) > 0:
+			col = conflictColumns[0]
+		case len(fieldOrder) > 0:
+			col = fieldOrder[0]
+		default:
+			for field := range fields {
+				if col == "" || field < col {
+					col = field
+				}
+			}
+		}
+		sets = append(sets, fmt.Sprintf("`%s` = `%s`", col, col))
+	}
+
+	proto.Sql += " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	return proto
+}
+
 // BuildDelete builds a DELETE query for MySQL
 func (f *Factory) BuildDelete(table string, conditions []*define.Condition) *define.SqlProto {
 	query := fmt.Sprintf("DELETE FROM `%s`", table)
@@ -471,114 +506,46 @@ func (f *Factory) BuildDelete(table string, conditions []*define.Condition) *def
 
 // BuildCreateTable builds a CREATE TABLE query for MySQL
 func (f *Factory) BuildCreateTable(table string, modelType reflect.Type) *define.SqlProto {
-	if modelType.Kind() == reflect.Ptr {
-		modelType = modelType.Elem()
-	}
-
-	var fields []string
-	for i := 0; i < modelType.NumField(); i++ {
-		field := modelType.Field(i)
-		sqlTag := field.Tag.Get("sql")
-		if sqlTag == "-" {
-			continue
-		}
-
-		// Get field name from tag or use field name
-		fieldName := field.Tag.Get("gom")
-		if fieldName == "" {
-			fieldName = field.Name
-		}
-		if strings.Contains(fieldName, ",") {
-			fieldName = strings.Split(fieldName, ",")[0]
-		}
-		if fieldName == "-" {
-			continue
-		}
-
-		fieldDef := fmt.Sprintf("`%s`", fieldName)
-
-		// Handle special fields
-		switch fieldName {
-		case "id":
-			fields = append(fields, "`id` BIGINT AUTO_INCREMENT PRIMARY KEY")
-			continue
-		case "created_at":
-			fields = append(fields, "`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP")
-			continue
-		case "updated_at":
-			fields = append(fields, "`updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP")
-			continue
-		case "deleted_at":
-			fields = append(fields, "`deleted_at` TIMESTAMP")
-			continue
-		}
-
-		// Handle normal fields
-		switch field.Type.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
-			fieldDef += " INTEGER"
-		case reflect.Int64:
-			fieldDef += " BIGINT"
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
-			fieldDef += " INTEGER UNSIGNED"
-		case reflect.Uint64:
-			fieldDef += " BIGINT UNSIGNED"
-		case reflect.Float32:
-			fieldDef += " FLOAT"
-		case reflect.Float64:
-			fieldDef += " DOUBLE"
-		case reflect.Bool:
-			fieldDef += " BOOLEAN"
-		case reflect.String:
-			fieldDef += " VARCHAR(255)"
-		default:
-			if field.Type == reflect.TypeOf(time.Time{}) {
-				fieldDef += " TIMESTAMP"
-			} else {
-				fieldDef += " TEXT"
-			}
-		}
-
-		// Add NOT NULL if field is not a pointer
-		if field.Type.Kind() != reflect.Ptr {
-			fieldDef += " NOT NULL"
-		}
-
-		// Add default value if specified in tag
-		if sqlTag != "" {
-			fieldDef += " " + sqlTag
-		}
-
-		fields = append(fields, fieldDef)
-	}
-
-	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` (\n", table)
-	query += strings.Join(fields, ",\n")
-	query += "\n)"
-
-	return &define.SqlProto{Sql: query}
+	return &define.SqlProto{Sql: createTableSQL(f.DescribeModel(table, modelType))}
 }
 
 // GetTableInfo retrieves table information from MySQL
-func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo, error) {
+func (f *Factory) GetTableInfo(db define.Queryer, tableName string) (*define.TableInfo, error) {
 	if db == nil {
 		return nil, errors.New("database connection is nil")
 	}
 
-	// Get table comment
+	// Get table comment and options
 	var tableComment string
-	row := db.QueryRow(`
-		SELECT table_comment 
+	var engine, collation, tableType sql.NullString
+	row := db.QueryRowContext(context.Background(), `
+		SELECT table_comment, engine, table_collation, table_type
 		FROM information_schema.tables 
 		WHERE table_schema = DATABASE() 
 		AND table_name = ?
 	`, tableName)
-	if err := row.Scan(&tableComment); err != nil && err != sql.ErrNoRows {
+	if err := row.Scan(&tableComment, &engine, &collation, &tableType); err != nil && err != sql.ErrNoRows {
 		return nil, fmt.Errorf("failed to get table comment: %v", err)
 	}
 
+	// Views have the fixed comment VIEW and keep their query in information_schema.views
+	isView := tableType.String == "VIEW"
+	var viewDefinition string
+	if isView {
+		tableComment = ""
+		row = db.QueryRowContext(context.Background(), `
+			SELECT view_definition
+			FROM information_schema.views
+			WHERE table_schema = DATABASE()
+			AND table_name = ?
+		`, tableName)
+		if err := row.Scan(&viewDefinition); err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get view definition: %v", err)
+		}
+	}
+
 	// Get column information
-	rows, err := db.Query(`
+	rows, err := db.QueryContext(context.Background(), `
 		SELECT 
 			column_name,
 			data_type,
@@ -589,7 +556,8 @@ func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo,
 			column_key,
 			extra,
 			column_default,
-			column_comment
+			column_comment,
+			column_type
 		FROM information_schema.columns
 		WHERE table_schema = DATABASE()
 		AND table_name = ?
@@ -611,7 +579,7 @@ func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo,
 		var col define.ColumnInfo
 		var isNullable, columnKey, extra, comment string
 		var maxLength, numericPrecision, numericScale sql.NullInt64
-		var defaultValue sql.NullString
+		var defaultValue, columnType sql.NullString
 
 		err := rows.Scan(
 			&col.Name,
@@ -624,15 +592,20 @@ func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo,
 			&extra,
 			&defaultValue,
 			&comment,
+			&columnType,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan column info: %v", err)
 		}
 
 		// Set standard SQL data type
-		col.DataType = getSQLDataType(col.TypeName)
+		col.DataType = SQLDataType(col.TypeName)
 
 		// Set other fields
+		col.ColumnType = columnType.String
+		if strings.EqualFold(col.TypeName, "enum") {
+			col.EnumValues = parseEnumValues(col.ColumnType)
+		}
 		col.Length = maxLength.Int64
 		if numericPrecision.Valid {
 			col.Precision = int(numericPrecision.Int64)
@@ -671,20 +644,242 @@ func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo,
 		return nil, fmt.Errorf("error iterating rows: %v", err)
 	}
 
+	indexes, err := getIndexes(db, tableName)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := getForeignKeys(db, tableName)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := getCheckConstraints(db, tableName)
+	if err != nil {
+		return nil, err
+	}
+	partition, err := getPartition(db, tableName)
+	if err != nil {
+		return nil, err
+	}
+
 	return &define.TableInfo{
-		TableName:    tableName,
-		TableComment: tableComment,
-		PrimaryKeys:  primaryKeys,
-		Columns:      columns,
-		HasDecimal:   hasDecimal,
-		HasUUID:      hasUUID,
-		HasIP:        hasIP,
-		HasTime:      hasTime,
+		TableName:        tableName,
+		TableComment:     tableComment,
+		PrimaryKeys:      primaryKeys,
+		Columns:          columns,
+		Indexes:          indexes,
+		ForeignKeys:      foreignKeys,
+		CheckConstraints: checks,
+		Engine:           engine.String,
+		Collation:        collation.String,
+		Partition:        partition,
+		IsView:           isView,
+		ViewDefinition:   viewDefinition,
+		HasDecimal:       hasDecimal,
+		HasUUID:          hasUUID,
+		HasIP:            hasIP,
+		HasTime:          hasTime,
 	}, nil
 }
 
-// getSQLDataType returns the standard SQL data type for a given MySQL type
-func getSQLDataType(mysqlType string) string {
+// getIndexes reads the indexes of a table from information_schema.statistics
+func getIndexes(db define.Queryer, tableName string) ([]define.IndexInfo, error) {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT index_name, column_name, non_unique, index_type
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE()
+		AND table_name = ?
+		ORDER BY index_name, seq_in_index
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index information: %v", err)
+	}
+	defer rows.Close()
+
+	var indexes []define.IndexInfo
+	for rows.Next() {
+		var name, method string
+		var column sql.NullString
+		var nonUnique int
+		if err := rows.Scan(&name, &column, &nonUnique, &method); err != nil {
+			return nil, fmt.Errorf("failed to scan index info: %v", err)
+		}
+		// Functional index parts have no column name
+		if !column.Valid {
+			continue
+		}
+		if n := len(indexes); n > 0 && indexes[n-1].Name == name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, column.String)
+			continue
+		}
+		indexes = append(indexes, define.IndexInfo{
+			Name:    name,
+			Columns: []string{column.String},
+			Unique:  nonUnique == 0,
+			Primary: name == "PRIMARY",
+			Method:  method,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+	return indexes, nil
+}
+
+// getForeignKeys reads the foreign keys of a table from information_schema.key_column_usage,
+// the referential actions come from information_schema.referential_constraints
+func getForeignKeys(db define.Queryer, tableName string) ([]define.ForeignKeyInfo, error) {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT k.constraint_name, k.column_name, k.referenced_table_schema, k.referenced_table_name, k.referenced_column_name,
+			k.referenced_table_schema = DATABASE(), rc.update_rule, rc.delete_rule
+		FROM information_schema.key_column_usage k
+		JOIN information_schema.referential_constraints rc
+			ON rc.constraint_schema = k.constraint_schema AND rc.constraint_name = k.constraint_name
+		WHERE k.table_schema = DATABASE()
+		AND k.table_name = ?
+		AND k.referenced_table_name IS NOT NULL
+		ORDER BY k.constraint_name, k.ordinal_position
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign key information: %v", err)
+	}
+	defer rows.Close()
+
+	var foreignKeys []define.ForeignKeyInfo
+	for rows.Next() {
+		var name, column, refSchema, refTable, refColumn, onUpdate, onDelete string
+		var sameSchema bool
+		if err := rows.Scan(&name, &column, &refSchema, &refTable, &refColumn, &sameSchema, &onUpdate, &onDelete); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key info: %v", err)
+		}
+		if n := len(foreignKeys); n > 0 && foreignKeys[n-1].Name == name {
+			foreignKeys[n-1].Columns = append(foreignKeys[n-1].Columns, column)
+			foreignKeys[n-1].RefColumns = append(foreignKeys[n-1].RefColumns, refColumn)
+			continue
+		}
+		if !sameSchema {
+			refTable = refSchema + "." + refTable
+		}
+		foreignKeys = append(foreignKeys, define.ForeignKeyInfo{
+			Name:       name,
+			Columns:    []string{column},
+			RefTable:   refTable,
+			RefColumns: []string{refColumn},
+			OnUpdate:   onUpdate,
+			OnDelete:   onDelete,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+	return foreignKeys, nil
+}
+
+// getCheckConstraints reads the check constraints of a table from information_schema.check_constraints;
+// servers without that table (MySQL before 8.0.16) do not enforce check constraints and return none
+func getCheckConstraints(db define.Queryer, tableName string) ([]define.CheckConstraintInfo, error) {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT tc.constraint_name, cc.check_clause
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.check_constraints cc
+			ON cc.constraint_schema = tc.constraint_schema AND cc.constraint_name = tc.constraint_name
+		WHERE tc.table_schema = DATABASE()
+		AND tc.table_name = ?
+		AND tc.constraint_type = 'CHECK'
+		ORDER BY tc.constraint_name
+	`, tableName)
+	if err != nil {
+		var mysqlErr *mysqldriver.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1109 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get check constraint information: %v", err)
+	}
+	defer rows.Close()
+
+	var checks []define.CheckConstraintInfo
+	for rows.Next() {
+		var check define.CheckConstraintInfo
+		if err := rows.Scan(&check.Name, &check.Expression); err != nil {
+			return nil, fmt.Errorf("failed to scan check constraint info: %v", err)
+		}
+		checks = append(checks, check)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+	return checks, nil
+}
+
+// getPartition reads the partitioning of a table from information_schema.partitions, nil when the table is not partitioned
+func getPartition(db define.Queryer, tableName string) (*define.PartitionInfo, error) {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT DISTINCT partition_ordinal_position, partition_name, partition_method, partition_expression
+		FROM information_schema.partitions
+		WHERE table_schema = DATABASE()
+		AND table_name = ?
+		AND partition_name IS NOT NULL
+		ORDER BY partition_ordinal_position
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition information: %v", err)
+	}
+	defer rows.Close()
+
+	var partition *define.PartitionInfo
+	for rows.Next() {
+		var position int
+		var name, method string
+		var expression sql.NullString
+		if err := rows.Scan(&position, &name, &method, &expression); err != nil {
+			return nil, fmt.Errorf("failed to scan partition info: %v", err)
+		}
+		if partition == nil {
+			partition = &define.PartitionInfo{Method: method, Expression: expression.String}
+		}
+		partition.Partitions = append(partition.Partitions, name)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+	return partition, nil
+}
+
+// parseEnumValues returns the values of a column type such as enum('a','b'), quotes in values are doubled or escaped with a backslash
+func parseEnumValues(columnType string) []string {
+	open, end := strings.IndexByte(columnType, '('), strings.LastIndexByte(columnType, ')')
+	if open < 0 || end < open {
+		return nil
+	}
+	var values []string
+	var value strings.Builder
+	quoted := false
+	body := columnType[open+1 : end]
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case !quoted:
+			if c == '\'' {
+				quoted = true
+				value.Reset()
+			}
+		case c == '\\' && i+1 < len(body):
+			i++
+			value.WriteByte(body[i])
+		case c == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			i++
+			value.WriteByte(c)
+		case c == '\'':
+			quoted = false
+			values = append(values, value.String())
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return values
+}
+
+// SQLDataType returns the Go type of a MySQL type name such as varchar, used as ColumnInfo.DataType
+func SQLDataType(mysqlType string) string {
 	switch strings.ToLower(mysqlType) {
 	case "tinyint", "smallint", "mediumint", "int", "integer":
 		return "int32"
@@ -705,49 +900,79 @@ func getSQLDataType(mysqlType string) string {
 	}
 }
 
-// GetTables 获取符合模式的所有表
-func (f *Factory) GetTables(db *sql.DB, pattern string) ([]string, error) {
+// GetTables 获取符合模式的所有表，不包括视图
+func (f *Factory) GetTables(db define.Queryer, pattern string) ([]string, error) {
+	return listNames(db, "SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'", "TABLE_NAME", pattern)
+}
+
+// GetViews 获取符合模式的所有视图
+func (f *Factory) GetViews(db define.Queryer, pattern string) ([]string, error) {
+	return listNames(db, "SELECT TABLE_NAME FROM information_schema.VIEWS WHERE TABLE_SCHEMA = ?", "TABLE_NAME", pattern)
+}
+
+// GetMaterializedViews MySQL 没有物化视图，总是返回空
+func (f *Factory) GetMaterializedViews(db define.Queryer, pattern string) ([]string, error) {
+	return nil, nil
+}
+
+// ListSchemas 获取所有用户数据库，不包括 mysql、sys 等系统数据库
+func (f *Factory) ListSchemas(db define.Queryer) ([]string, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT SCHEMA_NAME
+		FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')
+		ORDER BY SCHEMA_NAME`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schemas: %v", err)
+	}
+	return scanNames(rows)
+}
+
+// listNames 在当前数据库中执行名称查询，pattern 不为空、* 或 % 时按 column LIKE pattern 过滤
+func listNames(db define.Queryer, query, column, pattern string) ([]string, error) {
 	if db == nil {
 		return nil, errors.New("database connection is nil")
 	}
 
 	// 获取当前数据库名
 	var dbName string
-	err := db.QueryRow("SELECT DATABASE()").Scan(&dbName)
+	err := db.QueryRowContext(context.Background(), "SELECT DATABASE()").Scan(&dbName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current database: %v", err)
 	}
 
-	// 构建查询
-	query := "SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ?"
 	args := []interface{}{dbName}
-
 	if pattern != "" && pattern != "*" && pattern != "%" {
-		query += " AND TABLE_NAME LIKE ?"
+		query += " AND " + column + " LIKE ?"
 		args = append(args, pattern)
 	}
+	query += " ORDER BY " + column
 
-	// 执行查询
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(context.Background(), query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tables: %v", err)
 	}
-	defer rows.Close()
+	return scanNames(rows)
+}
 
-	var tables []string
+// scanNames 读取只有一列的查询结果并关闭 rows
+func scanNames(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+	var names []string
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			return nil, fmt.Errorf("failed to scan table name: %v", err)
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan name: %v", err)
 		}
-		tables = append(tables, tableName)
+		names = append(names, name)
 	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating table rows: %v", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
 	}
-
-	return tables, nil
+	return names, nil
 }
 
 // BuildOrderBy builds the ORDER BY clause for MySQL
@@ -0,0 +1,345 @@
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// DescribeModel describes the table modelType maps to with MySQL column types
+// Only fields with a gom tag are columns, the same fields Insert and Update write
+func (f *Factory) DescribeModel(table string, modelType reflect.Type) *define.TableDef {
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+
+	def := &define.TableDef{Name: table, Options: define.ModelTableOptions(modelType)}
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		sqlTag := field.Tag.Get("sql")
+		if sqlTag == "-" {
+			continue
+		}
+
+		tag := field.Tag.Get("gom")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		// Get field name from tag or use field name
+		parts := define.SplitTag(tag)
+		fieldName := strings.TrimSpace(parts[0])
+		if fieldName == "" {
+			fieldName = strings.ToLower(field.Name)
+		}
+
+		fieldType, nullable := define.UnwrapFieldType(field.Type)
+		// Add default value if specified in tag
+		col := &define.ColumnDef{Name: fieldName, Nullable: nullable, Extra: sqlTag}
+		for _, opt := range parts[1:] {
+			switch strings.TrimSpace(opt) {
+			case "@", "auto":
+				col.PrimaryKey = true
+				col.AutoIncrement = true
+			case "!", "pk":
+				col.PrimaryKey = true
+			}
+		}
+
+		// Handle special fields
+		switch fieldName {
+		case "created_at":
+			col.Type = "TIMESTAMP"
+			col.Nullable = false
+			col.Default = "CURRENT_TIMESTAMP"
+		case "updated_at":
+			col.Type = "TIMESTAMP"
+			col.Nullable = false
+			col.Default = "CURRENT_TIMESTAMP"
+			col.Extra = "ON UPDATE CURRENT_TIMESTAMP"
+		case "deleted_at":
+			col.Type = "TIMESTAMP"
+			col.Nullable = true
+		}
+
+		def.ApplyTagOptions(col, parts[1:])
+		if col.Type == "" {
+			col.Type = columnType(fieldType, col)
+		}
+		if col.AutoIncrement && !integerTypes[baseTypeName(col.Type)] {
+			col.AutoIncrement = false
+		}
+		if col.PrimaryKey {
+			col.Nullable = false
+			def.PrimaryKeys = append(def.PrimaryKeys, col.Name)
+		}
+		def.Columns = append(def.Columns, col)
+	}
+	return def
+}
+
+// integerTypes are the column types that can be AUTO_INCREMENT
+var integerTypes = map[string]bool{"tinyint": true, "smallint": true, "mediumint": true, "int": true, "integer": true, "bigint": true}
+
+// baseTypeName returns the lower-cased base type of a column type, e.g. int for INT(11) UNSIGNED
+func baseTypeName(columnType string) string {
+	base, _ := define.SplitColumnType(columnType)
+	if idx := strings.IndexByte(base, ' '); idx >= 0 {
+		return base[:idx]
+	}
+	return base
+}
+
+// maxVarcharSize is the longest VARCHAR that fits a utf8mb4 row; longer strings use TEXT types
+const maxVarcharSize = 16383
+
+// columnType maps a Go type to a MySQL column type, shaped by the size, precision and enum of col
+func columnType(t reflect.Type, col *define.ColumnDef) string {
+	if len(col.Enum) > 0 {
+		values := make([]string, len(col.Enum))
+		for i, v := range col.Enum {
+			values[i] = quoteString(v)
+		}
+		return "ENUM(" + strings.Join(values, ",") + ")"
+	}
+
+	switch define.FieldTypeKind(t) {
+	case define.FieldDecimal:
+		return decimalType(col, 20, 6)
+	case define.FieldUUID:
+		return "CHAR(36)"
+	case define.FieldTime:
+		if col.Precision > 0 {
+			return fmt.Sprintf("TIMESTAMP(%d)", col.Precision)
+		}
+		return "TIMESTAMP"
+	case define.FieldBytes:
+		if col.Size > 0 && col.Size <= 65535 {
+			return fmt.Sprintf("VARBINARY(%d)", col.Size)
+		}
+		return "BLOB"
+	case define.FieldJSON:
+		return "JSON"
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return "INTEGER"
+	case reflect.Int64:
+		return "BIGINT"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "INTEGER UNSIGNED"
+	case reflect.Uint64:
+		return "BIGINT UNSIGNED"
+	case reflect.Float32, reflect.Float64:
+		if col.Precision > 0 {
+			return decimalType(col, 0, 0)
+		}
+		if t.Kind() == reflect.Float32 {
+			return "FLOAT"
+		}
+		return "DOUBLE"
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.String:
+		switch {
+		case col.Size <= 0:
+			return "VARCHAR(255)"
+		case col.Size <= maxVarcharSize:
+			return fmt.Sprintf("VARCHAR(%d)", col.Size)
+		case col.Size <= 16777215:
+			return "MEDIUMTEXT"
+		default:
+			return "LONGTEXT"
+		}
+	}
+	return "TEXT"
+}
+
+// decimalType renders DECIMAL with the precision and scale of col or the given defaults
+func decimalType(col *define.ColumnDef, precision, scale int) string {
+	if col.Precision > 0 {
+		precision, scale = col.Precision, col.Scale
+	}
+	return fmt.Sprintf("DECIMAL(%d,%d)", precision, scale)
+}
+
+// columnSQL renders a column definition; inlinePK adds PRIMARY KEY to a single-column key
+func columnSQL(col *define.ColumnDef, inlinePK bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "`%s` %s", col.Name, col.Type)
+	if col.AutoIncrement {
+		sb.WriteString(" AUTO_INCREMENT")
+	}
+	if col.PrimaryKey && inlinePK {
+		sb.WriteString(" PRIMARY KEY")
+	} else if !col.Nullable {
+		sb.WriteString(" NOT NULL")
+	}
+	if col.Default != "" {
+		sb.WriteString(" DEFAULT " + col.Default)
+	}
+	if col.Extra != "" {
+		sb.WriteString(" " + col.Extra)
+	}
+	if col.Unique && !col.PrimaryKey {
+		sb.WriteString(" UNIQUE")
+	}
+	if col.Comment != "" {
+		sb.WriteString(" COMMENT " + quoteString(col.Comment))
+	}
+	return sb.String()
+}
+
+// createTableSQL renders the CREATE TABLE statement of def
+func createTableSQL(def *define.TableDef) string {
+	inlinePK := len(def.PrimaryKeys) == 1
+	var fields []string
+	for _, col := range def.Columns {
+		fields = append(fields, columnSQL(col, inlinePK))
+	}
+	if len(def.PrimaryKeys) > 1 {
+		fields = append(fields, "PRIMARY KEY ("+quoteColumns(def.PrimaryKeys)+")")
+	}
+	for _, index := range def.Indexes {
+		unique := ""
+		if index.Unique {
+			unique = "UNIQUE "
+		}
+		fields = append(fields, fmt.Sprintf("%sINDEX `%s` (%s)", unique, index.Name, quoteColumns(index.Columns)))
+	}
+	for _, fk := range def.ForeignKeys {
+		fields = append(fields, foreignKeySQL(fk))
+	}
+
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` (\n", def.Name)
+	query += strings.Join(fields, ",\n")
+	query += "\n)"
+	if def.Options.Engine != "" {
+		query += " ENGINE=" + def.Options.Engine
+	}
+	if def.Options.Charset != "" {
+		query += " DEFAULT CHARSET=" + def.Options.Charset
+	}
+	if def.Options.Collation != "" {
+		query += " COLLATE=" + def.Options.Collation
+	}
+	if def.Options.Comment != "" {
+		query += " COMMENT=" + quoteString(def.Options.Comment)
+	}
+	return query
+}
+
+// ColumnTypeMatches reports whether a live column already has the type of a declared column
+func (f *Factory) ColumnTypeMatches(def *define.ColumnDef, col *define.ColumnInfo) bool {
+	base, args := define.SplitColumnType(def.Type)
+	base = strings.TrimSuffix(base, " unsigned")
+	switch base {
+	case "integer":
+		base = "int"
+	case "boolean", "bool":
+		base = "tinyint"
+	case "serial":
+		base = "bigint"
+	case "real", "double precision":
+		base = "double"
+	case "numeric":
+		base = "decimal"
+	case "character varying":
+		base = "varchar"
+	}
+	if base != strings.ToLower(col.TypeName) {
+		return false
+	}
+
+	switch base {
+	case "char", "varchar", "binary", "varbinary":
+		return len(args) == 0 || int64(args[0]) == col.Length
+	case "decimal":
+		precision, scale := 10, 0
+		if len(args) > 0 {
+			precision = args[0]
+		}
+		if len(args) > 1 {
+			scale = args[1]
+		}
+		return precision == col.Precision && scale == col.Scale
+	}
+	return true
+}
+
+// BuildSchemaChange renders a schema change as MySQL DDL
+// MySQL commits DDL implicitly, so the statements are not meant to run in a transaction
+func (f *Factory) BuildSchemaChange(change *define.SchemaChange) []*define.SqlProto {
+	var statements []string
+	switch change.Kind {
+	case define.ChangeCreateTable:
+		// Indexes and foreign keys are part of the CREATE TABLE statement
+		statements = append(statements, createTableSQL(change.TableDef))
+	case define.ChangeAddColumn:
+		proto := f.BuildAddColumn(change.Table, change.Column)
+		if proto.Error != nil {
+			return []*define.SqlProto{proto}
+		}
+		statements = append(statements, proto.Sql)
+	case define.ChangeAlterColumn:
+		// MODIFY restates the whole column, so type, nullability and default change together
+		statements = append(statements, fmt.Sprintf("ALTER TABLE `%s` MODIFY COLUMN %s", change.Table, columnSQL(change.Column, false)))
+	case define.ChangeDropColumn:
+		statements = append(statements, fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`", change.Table, change.Current.Name))
+	case define.ChangeCreateIndex:
+		statements = append(statements, createIndexSQL(change.Table, change.Index))
+	case define.ChangeDropIndex:
+		statements = append(statements, fmt.Sprintf("DROP INDEX `%s` ON `%s`", change.Index.Name, change.Table))
+	case define.ChangeAddForeignKey:
+		statements = append(statements, fmt.Sprintf("ALTER TABLE `%s` ADD %s", change.Table, foreignKeySQL(change.ForeignKey)))
+	case define.ChangeDropForeignKey:
+		statements = append(statements, fmt.Sprintf("ALTER TABLE `%s` DROP FOREIGN KEY `%s`", change.Table, change.ForeignKey.Name))
+	default:
+		return []*define.SqlProto{{SqlType: define.Exec, Error: fmt.Errorf("unsupported schema change %q", change.Kind)}}
+	}
+
+	protos := make([]*define.SqlProto, len(statements))
+	for i, stmt := range statements {
+		protos[i] = &define.SqlProto{SqlType: define.Exec, Sql: stmt, Table: change.Table}
+	}
+	return protos
+}
+
+// createIndexSQL renders a CREATE INDEX statement
+func createIndexSQL(table string, index *define.IndexDef) string {
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX `%s` ON `%s` (%s)", unique, index.Name, table, quoteColumns(index.Columns))
+}
+
+// foreignKeySQL renders a foreign key constraint
+func foreignKeySQL(fk *define.ForeignKeyDef) string {
+	constraint := fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s` (%s)",
+		fk.Name, quoteColumns(fk.Columns), fk.RefTable, quoteColumns(fk.RefColumns))
+	if fk.OnDelete != "" {
+		constraint += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		constraint += " ON UPDATE " + fk.OnUpdate
+	}
+	return constraint
+}
+
+// quoteColumns quotes and joins column names
+func quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = "`" + col + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteString quotes a string literal
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(s) + "'"
+}
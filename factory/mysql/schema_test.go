@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaMembership struct {
+	TeamID  int64   `gom:"team_id,!"`
+	UserID  int64   `gom:"user_id,pk"`
+	Role    *string `gom:"role,notnull"`
+	Note    string
+	Ignored string `gom:"-"`
+}
+
+func TestFactory_DescribeModelKeys(t *testing.T) {
+	f := &Factory{}
+	def := f.DescribeModel("memberships", reflect.TypeOf(schemaMembership{}))
+
+	// ! 与 pk 都是主键，notnull 控制可空性，没有 gom 标签的字段不是列
+	assert.Equal(t, []string{"team_id", "user_id"}, def.PrimaryKeys)
+	require.Len(t, def.Columns, 3)
+	assert.False(t, def.Column("team_id").AutoIncrement)
+	assert.False(t, def.Column("role").Nullable)
+}
+
+type UUID [16]byte
+
+type schemaDocument struct {
+	ID    UUID   `gom:"id,@"`
+	Title string `gom:"title"`
+}
+
+type schemaRevision struct {
+	ID       int64  `gom:"id,pk"`
+	Revision int    `gom:"revision,pk"`
+	Slug     string `gom:"slug,type:varchar(64),autoIncrement"`
+}
+
+func TestFactory_DescribeModelID(t *testing.T) {
+	f := &Factory{}
+
+	// id 的类型和主键只由字段类型和标签决定
+	def := f.DescribeModel("documents", reflect.TypeOf(schemaDocument{}))
+	id := def.Column("id")
+	assert.Equal(t, "CHAR(36)", id.Type)
+	assert.True(t, id.PrimaryKey)
+	assert.False(t, id.AutoIncrement, "only integer columns are AUTO_INCREMENT")
+	assert.Equal(t, []string{"id"}, def.PrimaryKeys)
+
+	def = f.DescribeModel("revisions", reflect.TypeOf(schemaRevision{}))
+	assert.Equal(t, []string{"id", "revision"}, def.PrimaryKeys)
+	assert.Equal(t, "BIGINT", def.Column("id").Type)
+	assert.False(t, def.Column("id").AutoIncrement)
+	assert.False(t, def.Column("slug").AutoIncrement)
+
+	def = f.DescribeModel("notes", reflect.TypeOf(struct {
+		ID   string `gom:"id"`
+		Body string `gom:"body"`
+	}{}))
+	assert.Empty(t, def.PrimaryKeys)
+	assert.Equal(t, "VARCHAR(255)", def.Column("id").Type)
+}
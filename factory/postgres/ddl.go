@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// ddlProto wraps a DDL statement on table, failing when the table name is empty
+func ddlProto(table, query string) *define.SqlProto {
+	if table == "" {
+		return &define.SqlProto{SqlType: define.Exec, Error: define.ErrEmptyTableName}
+	}
+	return &define.SqlProto{SqlType: define.Exec, Sql: query, Table: table}
+}
+
+// splitTable splits schema.table; the schema is empty for unqualified names
+func splitTable(table string) (string, string) {
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		return table[:i], table[i+1:]
+	}
+	return "", table
+}
+
+// BuildDropTable builds a DROP TABLE query for PostgreSQL
+func (f *Factory) BuildDropTable(table string, ifExists bool) *define.SqlProto {
+	query := "DROP TABLE "
+	if ifExists {
+		query += "IF EXISTS "
+	}
+	return ddlProto(table, query+f.quoteIdentifier(table))
+}
+
+// BuildTruncateTable builds a TRUNCATE TABLE ... RESTART IDENTITY query for PostgreSQL
+func (f *Factory) BuildTruncateTable(table string) *define.SqlProto {
+	return ddlProto(table, fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY", f.quoteIdentifier(table)))
+}
+
+// BuildRenameTable builds an ALTER TABLE ... RENAME TO query for PostgreSQL
+// The table keeps its schema, so a schema in newName is ignored
+func (f *Factory) BuildRenameTable(table, newName string) *define.SqlProto {
+	_, newName = splitTable(newName)
+	if newName == "" {
+		return &define.SqlProto{SqlType: define.Exec, Error: define.ErrEmptyTableName}
+	}
+	return ddlProto(table, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", f.quoteIdentifier(table), f.quoteIdentifier(newName)))
+}
+
+// BuildAddColumn builds an ALTER TABLE ... ADD COLUMN query for PostgreSQL
+// Column comments need a separate COMMENT ON COLUMN statement, see BuildSchemaChange
+func (f *Factory) BuildAddColumn(table string, col *define.ColumnDef) *define.SqlProto {
+	if col.Type == "" {
+		return &define.SqlProto{SqlType: define.Exec, Error: fmt.Errorf("column %s has no type", col.Name)}
+	}
+	return ddlProto(table, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", f.quoteIdentifier(table), f.columnSQL(col, false)))
+}
+
+// BuildDropColumn builds an ALTER TABLE ... DROP COLUMN query for PostgreSQL
+func (f *Factory) BuildDropColumn(table, column string) *define.SqlProto {
+	return ddlProto(table, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", f.quoteIdentifier(table), f.quoteIdentifier(column)))
+}
+
+// BuildRenameColumn builds an ALTER TABLE ... RENAME COLUMN query for PostgreSQL
+func (f *Factory) BuildRenameColumn(table, column, newName string) *define.SqlProto {
+	return ddlProto(table, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", f.quoteIdentifier(table),
+		f.quoteIdentifier(column), f.quoteIdentifier(newName)))
+}
+
+// BuildAlterColumnType builds an ALTER TABLE ... ALTER COLUMN ... TYPE query for PostgreSQL
+// Existing values are cast with USING; nullability and default are left unchanged
+func (f *Factory) BuildAlterColumnType(table string, col *define.ColumnDef) *define.SqlProto {
+	if col.Type == "" {
+		return &define.SqlProto{SqlType: define.Exec, Error: fmt.Errorf("column %s has no type", col.Name)}
+	}
+	colType := alterType(col.Type)
+	column := f.quoteIdentifier(col.Name)
+	return ddlProto(table, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s",
+		f.quoteIdentifier(table), column, colType, column, colType))
+}
+
+// BuildCreateIndex builds a CREATE INDEX query for PostgreSQL
+// A concurrent build does not block writes but cannot run inside a transaction
+func (f *Factory) BuildCreateIndex(table string, index *define.IndexDef, concurrently bool) *define.SqlProto {
+	if len(index.Columns) == 0 {
+		return &define.SqlProto{SqlType: define.Exec, Error: fmt.Errorf("index %s has no columns", index.Name)}
+	}
+	query := f.createIndexSQL(table, index)
+	if concurrently {
+		query = strings.Replace(query, "INDEX IF NOT EXISTS", "INDEX CONCURRENTLY IF NOT EXISTS", 1)
+	}
+	return ddlProto(table, query)
+}
+
+// BuildDropIndex builds a DROP INDEX IF EXISTS query for PostgreSQL
+// Indexes live in the schema of their table, so an unqualified index takes the schema of table
+func (f *Factory) BuildDropIndex(table, index string, concurrently bool) *define.SqlProto {
+	if schema, _ := splitTable(table); schema != "" && !strings.Contains(index, ".") {
+		index = schema + "." + index
+	}
+	query := "DROP INDEX "
+	if concurrently {
+		query += "CONCURRENTLY "
+	}
+	return ddlProto(table, query+"IF EXISTS "+f.quoteIdentifier(index))
+}
+
+// BuildRefreshMaterializedView builds a REFRESH MATERIALIZED VIEW query for PostgreSQL
+// A concurrent refresh does not block reads but needs a unique index on the view
+func (f *Factory) BuildRefreshMaterializedView(view string, concurrently bool) *define.SqlProto {
+	query := "REFRESH MATERIALIZED VIEW "
+	if concurrently {
+		query += "CONCURRENTLY "
+	}
+	return ddlProto(view, query+f.quoteIdentifier(view))
+}
+
+// HasTable reports whether table exists; unqualified names are looked up in the current schema
+func (f *Factory) HasTable(db define.Queryer, table string) (bool, error) {
+	schema, name := splitTable(table)
+	return exists(db, `SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA) AND table_name = $2`, schema, name)
+}
+
+// HasColumn reports whether table has column
+func (f *Factory) HasColumn(db define.Queryer, table, column string) (bool, error) {
+	schema, name := splitTable(table)
+	return exists(db, `SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA) AND table_name = $2 AND column_name = $3`, schema, name, column)
+}
+
+// HasIndex reports whether table has an index called index
+func (f *Factory) HasIndex(db define.Queryer, table, index string) (bool, error) {
+	schema, name := splitTable(table)
+	_, index = splitTable(index)
+	return exists(db, `SELECT COUNT(*) FROM pg_indexes
+		WHERE schemaname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA) AND tablename = $2 AND indexname = $3`, schema, name, index)
+}
+
+// exists runs a COUNT query and reports whether it counted any rows
+func exists(db define.Queryer, query string, args ...interface{}) (bool, error) {
+	if db == nil {
+		return false, errors.New("database connection is nil")
+	}
+	var count int64
+	if err := db.QueryRowContext(context.Background(), query, args...).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
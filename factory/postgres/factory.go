@@ -1,13 +1,13 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
 	"sort"
 	"strings"
-	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/kmlixh/gom/v4/define"
@@ -83,18 +83,17 @@ func (f *Factory) buildCondition(cond *define.Condition, paramIndex *int) (strin
 		return "", nil
 	}
 
-	// First build the current condition
+	// Build the condition itself, then join its sub-conditions
+	var whereConditions []string
+	var args []interface{}
 	if cond.Field != "" {
 		sql, arg := f.buildSimpleCondition(cond, paramIndex)
 		if sql != "" {
-			return sql, arg
+			whereConditions = append(whereConditions, sql)
+			args = append(args, arg...)
 		}
 	}
-
-	// Then build sub-conditions
 	if len(cond.SubConds) > 0 {
-		var whereConditions []string
-		var args []interface{}
 		var hasOr bool
 		for _, subCond := range cond.SubConds {
 			subStr, subArg := f.buildCondition(subCond, paramIndex)
@@ -112,28 +111,14 @@ func (f *Factory) buildCondition(cond *define.Condition, paramIndex *int) (strin
 				args = append(args, subArg...)
 			}
 		}
-		if len(whereConditions) > 0 {
-			if hasOr {
-				return "(" + strings.Join(whereConditions, " ") + ")", args
-			}
-			return strings.Join(whereConditions, " "), args
+		if hasOr {
+			return "(" + strings.Join(whereConditions, " ") + ")", args
 		}
 	}
-
-	if cond.IsSubGroup {
-		var subConditions []string
-		var args []interface{}
-		for _, subCond := range cond.SubConds {
-			subStr, subArg := f.buildCondition(subCond, paramIndex)
-			if subStr != "" {
-				subConditions = append(subConditions, subStr)
-				args = append(args, subArg...)
-			}
-		}
-		if len(subConditions) > 0 {
-			return "(" + strings.Join(subConditions, " AND ") + ")", args
-		}
+	if len(whereConditions) > 0 {
+		return strings.Join(whereConditions, " "), args
 	}
+
 	return "", nil
 }
 
@@ -350,7 +335,6 @@ func (f *Factory) BuildUpdate(table string, fields map[string]interface{}, field
 				}
 				condStrings = append(condStrings, condStr)
 				args = append(args, condArgs...)
-				currentParamIndex += len(condArgs)
 			}
 		}
 		if len(condStrings) > 0 {
@@ -422,6 +406,38 @@ func (f *Factory) quoteIdentifiers(identifiers []string) []string {
 	return quoted
 }
 
+// BuildUpsert builds an INSERT ... ON CONFLICT query for PostgreSQL
+func (f *Factory) BuildUpsert(table string, fields map[string]interface{}, fieldOrder []string, conflictColumns []string, updateColumns []string) *define.SqlProto {
+	if len(conflictColumns) == 0 {
+		return &define.SqlProto{
+			Error: fmt.Errorf("upsert requires conflict columns"),
+		}
+	}
+	proto := f.BuildInsert(table, fields, fieldOrder)
+	if proto.Error != nil {
+		return proto
+	}
+
+	quotedConflicts := make([]string, len(conflictColumns))
+	for i, col := range conflictColumns {
+		quotedConflicts[i] = f.quoteIdentifier(col)
+	}
+	action := "DO NOTHING"
+	if len(updateColumns) > 0 {
+		sets := make([]string, len(updateColumns))
+		for i, col := range updateColumns {
+			sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", f.quoteIdentifier(col), f.quoteIdentifier(col))
+		}
+		action = "DO UPDATE SET " + strings.Join(sets, ", ")
+	}
+
+	proto.Sql = fmt.Sprintf("%s ON CONFLICT (%s) %s RETURNING *",
+		strings.TrimSuffix(proto.Sql, " RETURNING *"),
+		strings.Join(quotedConflicts, ", "),
+		action)
+	return proto
+}
+
 // BuildBatchInsert builds a batch INSERT query for PostgreSQL
 func (f *Factory) BuildBatchInsert(table string, batchFields []map[string]interface{}) *define.SqlProto {
 	if len(batchFields) == 0 {
@@ -500,7 +516,6 @@ func (f *Factory) BuildDelete(table string, conditions []*define.Condition) *def
 				}
 				condStrings = append(condStrings, condStr)
 				args = append(args, condArgs...)
-				currentParamIndex += len(condArgs)
 			}
 		}
 		if len(condStrings) > 0 {
@@ -518,131 +533,48 @@ func (f *Factory) BuildDelete(table string, conditions []*define.Condition) *def
 
 // BuildCreateTable builds a CREATE TABLE query for PostgreSQL
 func (f *Factory) BuildCreateTable(table string, modelType reflect.Type) *define.SqlProto {
-	var fieldDefs []string
-
-	for i := 0; i < modelType.NumField(); i++ {
-		field := modelType.Field(i)
-		tag := field.Tag.Get("gom")
-		if tag == "" || tag == "-" {
-			continue
-		}
-
-		parts := strings.Split(tag, ",")
-		columnName := parts[0]
-		columnConstraints := parts[1:]
-
-		var columnType string
-		switch field.Type.Kind() {
-		case reflect.Int, reflect.Int32:
-			columnType = "INTEGER"
-		case reflect.Int8, reflect.Int16:
-			columnType = "SMALLINT"
-		case reflect.Int64:
-			if columnName == "id" {
-				columnType = "BIGSERIAL"
-			} else {
-				columnType = "BIGINT"
-			}
-		case reflect.Uint, reflect.Uint32:
-			columnType = "INTEGER"
-		case reflect.Uint8, reflect.Uint16:
-			columnType = "SMALLINT"
-		case reflect.Uint64:
-			columnType = "BIGINT"
-		case reflect.Float32:
-			columnType = "REAL"
-		case reflect.Float64:
-			columnType = "DOUBLE PRECISION"
-		case reflect.Bool:
-			columnType = "BOOLEAN"
-		case reflect.String:
-			columnType = "VARCHAR(255)"
-		case reflect.Struct:
-			if field.Type == reflect.TypeOf(time.Time{}) {
-				columnType = "TIMESTAMP"
-			}
-		case reflect.Ptr:
-			if field.Type.Elem() == reflect.TypeOf(time.Time{}) {
-				columnType = "TIMESTAMP"
-			}
-		}
-
-		columnDef := fmt.Sprintf(`"%s" %s`, columnName, columnType)
-
-		// Add constraints
-		isNull := field.Type.Kind() == reflect.Ptr // 指针类型默认可为空
-		for _, constraint := range columnConstraints {
-			switch constraint {
-			case "@":
-				if columnName == "id" {
-					columnDef += " PRIMARY KEY"
-				}
-			case "notnull", "!":
-				isNull = false
-				columnDef += " NOT NULL"
-			case "null":
-				isNull = true
-			case "unique", "~":
-				columnDef += " UNIQUE"
-			case "default":
-				if field.Type == reflect.TypeOf(time.Time{}) {
-					columnDef += " DEFAULT CURRENT_TIMESTAMP"
-				}
-			}
-		}
-
-		if !isNull && !strings.Contains(columnDef, "NOT NULL") {
-			columnDef += " NOT NULL"
-		}
-
-		fieldDefs = append(fieldDefs, columnDef)
-	}
-
-	// 将字段定义组合成 SQL 语句
-	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s" (`, table)
-	query += strings.Join(fieldDefs, ", ")
-	query += ")"
-
-	// 去除多余的空白字符
-	query = strings.ReplaceAll(query, "\n", " ")
-	query = strings.ReplaceAll(query, "\t", " ")
-	query = strings.ReplaceAll(query, "  ", " ")
-
 	return &define.SqlProto{
 		SqlType: define.Exec,
-		Sql:     query,
+		Sql:     f.createTableSQL(f.DescribeModel(table, modelType)),
 		Args:    nil,
 	}
 }
 
 // GetTableInfo 获取表信息
-func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo, error) {
+func (f *Factory) GetTableInfo(db define.Queryer, tableName string) (*define.TableInfo, error) {
 	if db == nil {
 		return nil, errors.New("database connection is nil")
 	}
 
 	// Get current schema
 	var schema string
-	row := db.QueryRow("SELECT CURRENT_SCHEMA")
+	row := db.QueryRowContext(context.Background(), "SELECT CURRENT_SCHEMA")
 	if err := row.Scan(&schema); err != nil {
 		return nil, fmt.Errorf("failed to get current schema: %v", err)
 	}
+	// schema.table 格式的表名使用指定的 schema
+	relName := tableName
+	if i := strings.LastIndexByte(tableName, '.'); i >= 0 {
+		schema, relName = tableName[:i], tableName[i+1:]
+	}
 
-	// Get table comment
-	var tableComment string
-	row = db.QueryRow(`
-		SELECT COALESCE(obj_description(c.oid), '') 
+	// Get table comment, kind and view definition
+	var tableComment, relKind, viewDefinition string
+	row = db.QueryRowContext(context.Background(), `
+		SELECT COALESCE(obj_description(c.oid), ''),
+			c.relkind::text,
+			CASE WHEN c.relkind IN ('v', 'm') THEN COALESCE(pg_get_viewdef(c.oid, true), '') ELSE '' END
 		FROM pg_class c 
 		JOIN pg_namespace n ON n.oid = c.relnamespace 
 		WHERE n.nspname = $1 
 		AND c.relname = $2
-	`, schema, tableName)
-	if err := row.Scan(&tableComment); err != nil && err != sql.ErrNoRows {
+	`, schema, relName)
+	if err := row.Scan(&tableComment, &relKind, &viewDefinition); err != nil && err != sql.ErrNoRows {
 		return nil, fmt.Errorf("failed to get table comment: %v", err)
 	}
 
 	// Get column information
-	rows, err := db.Query(`
+	rows, err := db.QueryContext(context.Background(), `
 		SELECT 
 			a.attname AS column_name,
 			t.typname AS data_type,
@@ -652,7 +584,8 @@ func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo,
 			pg_get_expr(d.adbin, d.adrelid) AS default_value,
 			col_description(c.oid, a.attnum) AS column_comment,
 			a.attidentity AS identity,
-			CASE WHEN pk.contype = 'p' THEN true ELSE false END AS is_primary
+			CASE WHEN pk.contype = 'p' THEN true ELSE false END AS is_primary,
+			format_type(a.atttypid, a.atttypmod) AS column_type
 		FROM pg_attribute a
 		JOIN pg_class c ON c.oid = a.attrelid
 		JOIN pg_namespace n ON n.oid = c.relnamespace
@@ -664,7 +597,7 @@ func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo,
 		AND a.attnum > 0
 		AND NOT a.attisdropped
 		ORDER BY a.attnum
-	`, schema, tableName)
+	`, schema, relName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get column information: %v", err)
 	}
@@ -680,7 +613,7 @@ func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo,
 		var col define.ColumnInfo
 		var maxLength, typeModifier int
 		var notNull bool
-		var defaultValue, comment, identity sql.NullString
+		var defaultValue, comment, identity, columnType sql.NullString
 		var isPrimary bool
 
 		err := rows.Scan(
@@ -693,13 +626,15 @@ func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo,
 			&comment,
 			&identity,
 			&isPrimary,
+			&columnType,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan column info: %v", err)
 		}
 
 		// Set standard SQL data type
-		col.DataType = getSQLDataType(col.TypeName)
+		col.DataType = SQLDataType(col.TypeName)
+		col.ColumnType = columnType.String
 
 		// Set other fields
 		if typeModifier > 4 {
@@ -758,19 +693,259 @@ func (f *Factory) GetTableInfo(db *sql.DB, tableName string) (*define.TableInfo,
 		return nil, fmt.Errorf("error iterating rows: %v", err)
 	}
 
+	if err := setEnumValues(db, schema, relName, columns); err != nil {
+		return nil, err
+	}
+
+	indexes, err := getIndexes(db, schema, relName)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := getForeignKeys(db, schema, relName)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := getCheckConstraints(db, schema, relName)
+	if err != nil {
+		return nil, err
+	}
+	partition, err := getPartition(db, schema, relName)
+	if err != nil {
+		return nil, err
+	}
+
 	return &define.TableInfo{
-		TableName:    tableName,
-		TableComment: tableComment,
-		PrimaryKeys:  primaryKeys,
-		Columns:      columns,
-		HasDecimal:   hasDecimal,
-		HasUUID:      hasUUID,
-		HasIP:        hasIP,
+		TableName:        tableName,
+		TableComment:     tableComment,
+		PrimaryKeys:      primaryKeys,
+		Columns:          columns,
+		Indexes:          indexes,
+		ForeignKeys:      foreignKeys,
+		CheckConstraints: checks,
+		Partition:        partition,
+		IsView:           relKind == "v" || relKind == "m",
+		IsMaterialized:   relKind == "m",
+		ViewDefinition:   viewDefinition,
+		HasDecimal:       hasDecimal,
+		HasUUID:          hasUUID,
+		HasIP:            hasIP,
 	}, nil
 }
 
-// getSQLDataType returns the standard SQL data type for a given PostgreSQL type
-func getSQLDataType(pgType string) string {
+// setEnumValues reads the labels of the enum columns of a table from pg_enum
+func setEnumValues(db define.Queryer, schema, relName string, columns []define.ColumnInfo) error {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT a.attname, e.enumlabel
+		FROM pg_enum e
+		JOIN pg_attribute a ON a.atttypid = e.enumtypid
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+		AND c.relname = $2
+		AND a.attnum > 0
+		AND NOT a.attisdropped
+		ORDER BY a.attnum, e.enumsortorder
+	`, schema, relName)
+	if err != nil {
+		return fmt.Errorf("failed to get enum values: %v", err)
+	}
+	defer rows.Close()
+
+	byName := make(map[string]*define.ColumnInfo, len(columns))
+	for i := range columns {
+		byName[columns[i].Name] = &columns[i]
+	}
+	for rows.Next() {
+		var column, label string
+		if err := rows.Scan(&column, &label); err != nil {
+			return fmt.Errorf("failed to scan enum value: %v", err)
+		}
+		if col, ok := byName[column]; ok {
+			col.EnumValues = append(col.EnumValues, label)
+		}
+	}
+	return rows.Err()
+}
+
+// getIndexes reads the indexes of a table from pg_index; expression columns are left out
+func getIndexes(db define.Queryer, schema, relName string) ([]define.IndexInfo, error) {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT i.relname, a.attname, ix.indisunique, ix.indisprimary, am.amname
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_am am ON am.oid = i.relam
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = $1
+		AND t.relname = $2
+		ORDER BY i.relname, k.ord
+	`, schema, relName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index information: %v", err)
+	}
+	defer rows.Close()
+
+	var indexes []define.IndexInfo
+	for rows.Next() {
+		var name, column, method string
+		var unique, primary bool
+		if err := rows.Scan(&name, &column, &unique, &primary, &method); err != nil {
+			return nil, fmt.Errorf("failed to scan index info: %v", err)
+		}
+		if n := len(indexes); n > 0 && indexes[n-1].Name == name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, column)
+			continue
+		}
+		indexes = append(indexes, define.IndexInfo{Name: name, Columns: []string{column}, Unique: unique, Primary: primary, Method: method})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+	return indexes, nil
+}
+
+// getForeignKeys reads the foreign keys of a table from pg_constraint
+func getForeignKeys(db define.Queryer, schema, relName string) ([]define.ForeignKeyInfo, error) {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT con.conname, a.attname, rn.nspname, r.relname, ra.attname, con.confupdtype::text, con.confdeltype::text
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_class r ON r.oid = con.confrelid
+		JOIN pg_namespace rn ON rn.oid = r.relnamespace
+		JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		JOIN pg_attribute ra ON ra.attrelid = r.oid AND ra.attnum = k.refnum
+		WHERE con.contype = 'f'
+		AND n.nspname = $1
+		AND t.relname = $2
+		ORDER BY con.conname, k.ord
+	`, schema, relName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign key information: %v", err)
+	}
+	defer rows.Close()
+
+	var foreignKeys []define.ForeignKeyInfo
+	for rows.Next() {
+		var name, column, refSchema, refTable, refColumn, onUpdate, onDelete string
+		if err := rows.Scan(&name, &column, &refSchema, &refTable, &refColumn, &onUpdate, &onDelete); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key info: %v", err)
+		}
+		if n := len(foreignKeys); n > 0 && foreignKeys[n-1].Name == name {
+			foreignKeys[n-1].Columns = append(foreignKeys[n-1].Columns, column)
+			foreignKeys[n-1].RefColumns = append(foreignKeys[n-1].RefColumns, refColumn)
+			continue
+		}
+		if refSchema != schema {
+			refTable = refSchema + "." + refTable
+		}
+		foreignKeys = append(foreignKeys, define.ForeignKeyInfo{
+			Name:       name,
+			Columns:    []string{column},
+			RefTable:   refTable,
+			RefColumns: []string{refColumn},
+			OnUpdate:   referentialActions[onUpdate],
+			OnDelete:   referentialActions[onDelete],
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+	return foreignKeys, nil
+}
+
+// referentialActions maps pg_constraint.confupdtype and confdeltype to the SQL actions
+var referentialActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// getCheckConstraints reads the check constraints of a table from pg_constraint
+func getCheckConstraints(db define.Queryer, schema, relName string) ([]define.CheckConstraintInfo, error) {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT ck.conname, pg_get_expr(ck.conbin, ck.conrelid)
+		FROM pg_constraint ck
+		JOIN pg_class t ON t.oid = ck.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE ck.contype = 'c'
+		AND n.nspname = $1
+		AND t.relname = $2
+		ORDER BY ck.conname
+	`, schema, relName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check constraint information: %v", err)
+	}
+	defer rows.Close()
+
+	var checks []define.CheckConstraintInfo
+	for rows.Next() {
+		var check define.CheckConstraintInfo
+		if err := rows.Scan(&check.Name, &check.Expression); err != nil {
+			return nil, fmt.Errorf("failed to scan check constraint info: %v", err)
+		}
+		checks = append(checks, check)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+	return checks, nil
+}
+
+// getPartition reads the partition key of a table and its partitions, nil when the table is not partitioned
+func getPartition(db define.Queryer, schema, relName string) (*define.PartitionInfo, error) {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT pg_get_partkeydef(t.oid), COALESCE(pn.nspname, ''), COALESCE(p.relname, '')
+		FROM pg_partitioned_table pt
+		JOIN pg_class t ON t.oid = pt.partrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		LEFT JOIN pg_inherits inh ON inh.inhparent = t.oid
+		LEFT JOIN pg_class p ON p.oid = inh.inhrelid
+		LEFT JOIN pg_namespace pn ON pn.oid = p.relnamespace
+		WHERE n.nspname = $1
+		AND t.relname = $2
+		ORDER BY pn.nspname, p.relname
+	`, schema, relName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition information: %v", err)
+	}
+	defer rows.Close()
+
+	var partition *define.PartitionInfo
+	for rows.Next() {
+		var key, partSchema, partName string
+		if err := rows.Scan(&key, &partSchema, &partName); err != nil {
+			return nil, fmt.Errorf("failed to scan partition info: %v", err)
+		}
+		if partition == nil {
+			// pg_get_partkeydef 的格式为 RANGE (created_at)
+			partition = &define.PartitionInfo{Method: key}
+			if open := strings.IndexByte(key, '('); open > 0 && strings.HasSuffix(key, ")") {
+				partition.Method = strings.TrimSpace(key[:open])
+				partition.Expression = key[open+1 : len(key)-1]
+			}
+		}
+		if partName == "" {
+			continue
+		}
+		if partSchema != schema {
+			partName = partSchema + "." + partName
+		}
+		partition.Partitions = append(partition.Partitions, partName)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+	return partition, nil
+}
+
+// SQLDataType returns the Go type of a PostgreSQL type name such as int4, used as ColumnInfo.DataType
+func SQLDataType(pgType string) string {
 	switch strings.ToLower(pgType) {
 	case "int2", "smallint":
 		return "int32"
@@ -804,51 +979,90 @@ func getSQLDataType(pgType string) string {
 }
 
 // GetTables 获取符合模式的所有表
-func (f *Factory) GetTables(db *sql.DB, pattern string) ([]string, error) {
-	var tables []string
-	var schema, tablePattern string
-
-	// 解析模式
-	parts := strings.Split(pattern, ".")
-	if len(parts) == 2 {
-		// 格式为 schema.table
-		schema = parts[0]
-		tablePattern = parts[1]
-	} else if pattern == "*" {
-		// 默认查询 public schema
-		schema = "public"
-		tablePattern = "*"
-	} else {
-		// 只有表名模式，使用 public schema
-		schema = "public"
-		tablePattern = pattern
-	}
-
-	// 将 * 转换为 SQL LIKE 模式
-	tablePattern = strings.ReplaceAll(tablePattern, "*", "%")
-
-	query := `
+// pattern 为 schema.table 格式，没有 schema 时为 public，* 匹配任意字符，如 *.* 匹配所有用户 schema 中的表
+func (f *Factory) GetTables(db define.Queryer, pattern string) ([]string, error) {
+	return listRelations(db, `
 		SELECT schemaname || '.' || tablename
 		FROM pg_catalog.pg_tables
-		WHERE schemaname = $1
+		WHERE schemaname LIKE $1
 		AND tablename LIKE $2
-		ORDER BY schemaname, tablename`
+		AND schemaname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY schemaname, tablename`, pattern)
+}
 
-	rows, err := db.Query(query, schema, tablePattern)
+// GetViews 获取符合模式的所有视图，pattern 的规则与 GetTables 相同
+func (f *Factory) GetViews(db define.Queryer, pattern string) ([]string, error) {
+	return listRelations(db, `
+		SELECT schemaname || '.' || viewname
+		FROM pg_catalog.pg_views
+		WHERE schemaname LIKE $1
+		AND viewname LIKE $2
+		AND schemaname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY schemaname, viewname`, pattern)
+}
+
+// GetMaterializedViews 获取符合模式的所有物化视图，pattern 的规则与 GetTables 相同
+func (f *Factory) GetMaterializedViews(db define.Queryer, pattern string) ([]string, error) {
+	return listRelations(db, `
+		SELECT schemaname || '.' || matviewname
+		FROM pg_catalog.pg_matviews
+		WHERE schemaname LIKE $1
+		AND matviewname LIKE $2
+		AND schemaname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY schemaname, matviewname`, pattern)
+}
+
+// ListSchemas 获取所有用户 schema，不包括 pg_ 开头的系统 schema 和 information_schema
+func (f *Factory) ListSchemas(db define.Queryer) ([]string, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT nspname
+		FROM pg_catalog.pg_namespace
+		WHERE LEFT(nspname, 3) <> 'pg_'
+		AND nspname <> 'information_schema'
+		ORDER BY nspname`)
+	if err != nil {
+		return nil, fmt.Errorf("查询 schema 列表失败: %v", err)
+	}
+	return scanNames(rows)
+}
+
+// splitPattern 把 schema.table 格式的匹配模式转换为 schema 和名称的 LIKE 模式，没有 schema 时为 public
+func splitPattern(pattern string) (string, string) {
+	schema, name := "public", pattern
+	if parts := strings.Split(pattern, "."); len(parts) == 2 {
+		schema, name = parts[0], parts[1]
+	}
+	return strings.ReplaceAll(schema, "*", "%"), strings.ReplaceAll(name, "*", "%")
+}
+
+// listRelations 执行以 schema 和名称的 LIKE 模式为参数的查询，返回 schema.name 格式的名称
+func listRelations(db define.Queryer, query, pattern string) ([]string, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+	schema, name := splitPattern(pattern)
+	rows, err := db.QueryContext(context.Background(), query, schema, name)
 	if err != nil {
 		return nil, fmt.Errorf("查询表列表失败: %v", err)
 	}
-	defer rows.Close()
+	return scanNames(rows)
+}
 
+// scanNames 读取只有一列的查询结果并关闭 rows
+func scanNames(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+	var names []string
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			return nil, fmt.Errorf("扫描表名失败: %v", err)
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("扫描名称失败: %v", err)
 		}
-		tables = append(tables, tableName)
+		names = append(names, name)
 	}
-
-	return tables, nil
+	return names, rows.Err()
 }
 
 // BuildOrderBy 构建排序语句
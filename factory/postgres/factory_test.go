@@ -108,3 +108,66 @@ func TestFactory_BuildSelect_WithIN(t *testing.T) {
 	assert.Equal(t, expectedSQL, proto.Sql)
 	assert.Equal(t, expectedArgs, proto.Args)
 }
+
+func TestFactory_BuildUpsert(t *testing.T) {
+	f := &Factory{}
+	fields := map[string]interface{}{"id": 1, "name": "ann"}
+
+	proto := f.BuildUpsert("users", fields, []string{"id", "name"}, []string{"id"}, []string{"name"})
+	assert.NoError(t, proto.Error)
+	assert.Equal(t, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name" RETURNING *`, proto.Sql)
+	assert.Equal(t, []interface{}{1, "ann"}, proto.Args)
+
+	proto = f.BuildUpsert("users", fields, []string{"id", "name"}, []string{"id", "name"}, nil)
+	assert.Contains(t, proto.Sql, `ON CONFLICT ("id", "name") DO NOTHING RETURNING *`)
+
+	proto = f.BuildUpsert("users", fields, []string{"id", "name"}, nil, []string{"name"})
+	assert.Error(t, proto.Error)
+}
+
+func TestFactory_BuildConditionWithSubConditions(t *testing.T) {
+	f := &Factory{}
+	cond := define.Eq("user_id", 1).And(define.Eq("role_id", 2))
+	cond.Or(define.Eq("user_id", 3).And(define.Eq("role_id", 4)))
+
+	proto := f.BuildSelect("user_roles", nil, []*define.Condition{define.Eq("active", true), cond}, "", 0, 0)
+	assert.NoError(t, proto.Error)
+	assert.Equal(t, `SELECT * FROM "user_roles" WHERE "active" = $1 AND ("user_id" = $2 AND "role_id" = $3 OR "user_id" = $4 AND "role_id" = $5)`, proto.Sql)
+	assert.Equal(t, []interface{}{true, 1, 2, 3, 4}, proto.Args)
+}
+
+func TestFactory_BuildUpdateAndDeleteWithSeveralConditions(t *testing.T) {
+	f := &Factory{}
+	conds := []*define.Condition{define.Eq("user_id", 1), define.Eq("role_id", 2)}
+
+	proto := f.BuildUpdate("user_roles", map[string]interface{}{"note": "x"}, []string{"note"}, conds)
+	assert.NoError(t, proto.Error)
+	assert.Equal(t, `UPDATE "user_roles" SET "note" = $1 WHERE "user_id" = $2 AND "role_id" = $3`, proto.Sql)
+	assert.Equal(t, []interface{}{"x", 1, 2}, proto.Args)
+
+	proto = f.BuildDelete("user_roles", conds)
+	assert.NoError(t, proto.Error)
+	assert.Equal(t, `DELETE FROM "user_roles" WHERE "user_id" = $1 AND "role_id" = $2 RETURNING *`, proto.Sql)
+
+	// 多值条件之后的占位符继续顺延
+	conds = []*define.Condition{define.In("user_id", 1, 2, 3), define.Eq("role_id", 4), define.Gt("level", 5)}
+	proto = f.BuildUpdate("user_roles", map[string]interface{}{"note": "x"}, []string{"note"}, conds)
+	assert.NoError(t, proto.Error)
+	assert.Equal(t, `UPDATE "user_roles" SET "note" = $1 WHERE "user_id" IN ($2, $3, $4) AND "role_id" = $5 AND "level" > $6`, proto.Sql)
+	assert.Equal(t, []interface{}{"x", 1, 2, 3, 4, 5}, proto.Args)
+
+	proto = f.BuildDelete("user_roles", conds)
+	assert.NoError(t, proto.Error)
+	assert.Equal(t, `DELETE FROM "user_roles" WHERE "user_id" IN ($1, $2, $3) AND "role_id" = $4 AND "level" > $5 RETURNING *`, proto.Sql)
+	assert.Equal(t, []interface{}{1, 2, 3, 4, 5}, proto.Args)
+}
+
+func TestFactory_BuildConditionKeepsSubConditionsOfAField(t *testing.T) {
+	f := &Factory{}
+	cond := define.Eq("tenant_id", 1).And(define.Gt("level", 2))
+
+	proto := f.BuildDelete("user_roles", []*define.Condition{cond})
+	assert.NoError(t, proto.Error)
+	assert.Equal(t, `DELETE FROM "user_roles" WHERE "tenant_id" = $1 AND "level" > $2 RETURNING *`, proto.Sql)
+	assert.Equal(t, []interface{}{1, 2}, proto.Args)
+}
@@ -0,0 +1,332 @@
+package postgres
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// DescribeModel describes the table modelType maps to with PostgreSQL column types
+// Only fields with a gom tag are columns, the same fields Insert and Update write
+func (f *Factory) DescribeModel(table string, modelType reflect.Type) *define.TableDef {
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+
+	def := &define.TableDef{Name: table, Options: define.ModelTableOptions(modelType)}
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		tag := field.Tag.Get("gom")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		parts := define.SplitTag(tag)
+		name := strings.TrimSpace(parts[0])
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fieldType, nullable := define.UnwrapFieldType(field.Type) // 指针和 sql.Null* 类型默认可为空
+		col := &define.ColumnDef{Name: name, Nullable: nullable}
+		for _, constraint := range parts[1:] {
+			switch strings.TrimSpace(constraint) {
+			case "@", "auto":
+				col.PrimaryKey = true
+				col.AutoIncrement = true
+			case "!", "pk":
+				col.PrimaryKey = true
+			case "~":
+				col.Unique = true
+			case "default":
+				if fieldType == reflect.TypeOf(time.Time{}) {
+					col.Default = "CURRENT_TIMESTAMP"
+				}
+			}
+		}
+		def.ApplyTagOptions(col, parts[1:])
+		if col.Type == "" {
+			col.Type = columnType(fieldType, col)
+		}
+		if base, _ := define.SplitColumnType(col.Type); col.AutoIncrement && !identityTypes[base] {
+			col.AutoIncrement = false
+		}
+		if col.PrimaryKey {
+			col.Nullable = false
+			def.PrimaryKeys = append(def.PrimaryKeys, col.Name)
+		}
+		def.Columns = append(def.Columns, col)
+	}
+	return def
+}
+
+// maxVarcharSize is the longest VARCHAR PostgreSQL accepts; longer strings use TEXT
+const maxVarcharSize = 10485760
+
+// columnType maps a Go type to a PostgreSQL column type, shaped by the size and precision of col
+// Auto-increment integers become identity columns in columnSQL
+func columnType(t reflect.Type, col *define.ColumnDef) string {
+	switch define.FieldTypeKind(t) {
+	case define.FieldDecimal:
+		if col.Precision > 0 {
+			return fmt.Sprintf("NUMERIC(%d,%d)", col.Precision, col.Scale)
+		}
+		return "NUMERIC"
+	case define.FieldUUID:
+		return "UUID"
+	case define.FieldTime:
+		if col.Precision > 0 {
+			return fmt.Sprintf("TIMESTAMP(%d)", col.Precision)
+		}
+		return "TIMESTAMP"
+	case define.FieldBytes:
+		return "BYTEA"
+	case define.FieldJSON:
+		return "JSONB"
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Uint, reflect.Uint32:
+		return "INTEGER"
+	case reflect.Int8, reflect.Int16, reflect.Uint8, reflect.Uint16:
+		return "SMALLINT"
+	case reflect.Int64, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Float32, reflect.Float64:
+		if col.Precision > 0 {
+			return fmt.Sprintf("NUMERIC(%d,%d)", col.Precision, col.Scale)
+		}
+		if t.Kind() == reflect.Float32 {
+			return "REAL"
+		}
+		return "DOUBLE PRECISION"
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.String:
+		switch {
+		case col.Size <= 0:
+			return "VARCHAR(255)"
+		case col.Size <= maxVarcharSize:
+			return fmt.Sprintf("VARCHAR(%d)", col.Size)
+		default:
+			return "TEXT"
+		}
+	}
+	return "TEXT"
+}
+
+// identityTypes are the column types that can be identity columns
+var identityTypes = map[string]bool{"smallint": true, "integer": true, "int": true, "bigint": true, "int2": true, "int4": true, "int8": true}
+
+// columnSQL renders a column definition; inlinePK adds PRIMARY KEY to a single-column key
+func (f *Factory) columnSQL(col *define.ColumnDef, inlinePK bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s", f.quoteIdentifier(col.Name), col.Type)
+	if base, _ := define.SplitColumnType(col.Type); col.AutoIncrement && identityTypes[base] {
+		sb.WriteString(" GENERATED BY DEFAULT AS IDENTITY")
+	}
+	if col.PrimaryKey && inlinePK {
+		sb.WriteString(" PRIMARY KEY")
+	} else if !col.Nullable {
+		sb.WriteString(" NOT NULL")
+	}
+	if col.Unique && !col.PrimaryKey {
+		sb.WriteString(" UNIQUE")
+	}
+	if col.Default != "" {
+		sb.WriteString(" DEFAULT " + col.Default)
+	}
+	if len(col.Enum) > 0 {
+		values := make([]string, len(col.Enum))
+		for i, v := range col.Enum {
+			values[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		}
+		fmt.Fprintf(&sb, " CHECK (%s IN (%s))", f.quoteIdentifier(col.Name), strings.Join(values, ", "))
+	}
+	if col.Extra != "" {
+		sb.WriteString(" " + col.Extra)
+	}
+	return sb.String()
+}
+
+// createTableSQL renders the CREATE TABLE statement of def
+func (f *Factory) createTableSQL(def *define.TableDef) string {
+	inlinePK := len(def.PrimaryKeys) == 1
+	var fieldDefs []string
+	for _, col := range def.Columns {
+		fieldDefs = append(fieldDefs, f.columnSQL(col, inlinePK))
+	}
+	if len(def.PrimaryKeys) > 1 {
+		fieldDefs = append(fieldDefs, "PRIMARY KEY ("+strings.Join(f.quoteIdentifiers(def.PrimaryKeys), ", ")+")")
+	}
+	for _, fk := range def.ForeignKeys {
+		fieldDefs = append(fieldDefs, f.foreignKeySQL(fk))
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", f.quoteIdentifier(def.Name), strings.Join(fieldDefs, ", "))
+}
+
+// pgTypeNames maps declared type names to pg_type names
+var pgTypeNames = map[string]string{
+	"integer":                     "int4",
+	"int":                         "int4",
+	"serial":                      "int4",
+	"bigint":                      "int8",
+	"bigserial":                   "int8",
+	"smallint":                    "int2",
+	"smallserial":                 "int2",
+	"real":                        "float4",
+	"double precision":            "float8",
+	"boolean":                     "bool",
+	"character varying":           "varchar",
+	"character":                   "bpchar",
+	"char":                        "bpchar",
+	"decimal":                     "numeric",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamptz",
+	"time without time zone":      "time",
+}
+
+// ColumnTypeMatches reports whether a live column already has the type of a declared column
+func (f *Factory) ColumnTypeMatches(def *define.ColumnDef, col *define.ColumnInfo) bool {
+	base, args := define.SplitColumnType(def.Type)
+	if name, ok := pgTypeNames[base]; ok {
+		base = name
+	}
+	if base != strings.ToLower(col.TypeName) {
+		return false
+	}
+
+	switch base {
+	case "varchar", "bpchar":
+		return len(args) == 0 || int64(args[0]) == col.Length
+	case "numeric":
+		if len(args) == 0 {
+			return true
+		}
+		scale := 0
+		if len(args) > 1 {
+			scale = args[1]
+		}
+		return args[0] == col.Precision && scale == col.Scale
+	}
+	return true
+}
+
+// BuildSchemaChange renders a schema change as PostgreSQL DDL
+// PostgreSQL DDL is transactional, so a plan can run in one transaction
+func (f *Factory) BuildSchemaChange(change *define.SchemaChange) []*define.SqlProto {
+	table := f.quoteIdentifier(change.Table)
+	var statements []string
+	switch change.Kind {
+	case define.ChangeCreateTable:
+		statements = append(statements, f.createTableSQL(change.TableDef))
+		if comment := change.TableDef.Options.Comment; comment != "" {
+			statements = append(statements, fmt.Sprintf("COMMENT ON TABLE %s IS '%s'", table, strings.ReplaceAll(comment, "'", "''")))
+		}
+		for _, col := range change.TableDef.Columns {
+			if col.Comment != "" {
+				statements = append(statements, f.commentSQL(change.Table, col))
+			}
+		}
+		for _, index := range change.TableDef.Indexes {
+			statements = append(statements, f.createIndexSQL(change.Table, index))
+		}
+	case define.ChangeAddColumn:
+		proto := f.BuildAddColumn(change.Table, change.Column)
+		if proto.Error != nil {
+			return []*define.SqlProto{proto}
+		}
+		statements = append(statements, proto.Sql)
+		if change.Column.Comment != "" {
+			statements = append(statements, f.commentSQL(change.Table, change.Column))
+		}
+	case define.ChangeAlterColumn:
+		col := change.Column
+		prefix := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s ", table, f.quoteIdentifier(col.Name))
+		if change.TypeChanged {
+			colType := alterType(col.Type)
+			statements = append(statements, fmt.Sprintf("%sTYPE %s USING %s::%s", prefix, colType, f.quoteIdentifier(col.Name), colType))
+		}
+		if change.NullableChanged {
+			if col.Nullable {
+				statements = append(statements, prefix+"DROP NOT NULL")
+			} else {
+				statements = append(statements, prefix+"SET NOT NULL")
+			}
+		}
+		if change.DefaultChanged {
+			if col.Default == "" {
+				statements = append(statements, prefix+"DROP DEFAULT")
+			} else {
+				statements = append(statements, prefix+"SET DEFAULT "+col.Default)
+			}
+		}
+	case define.ChangeDropColumn:
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, f.quoteIdentifier(change.Current.Name)))
+	case define.ChangeCreateIndex:
+		statements = append(statements, f.createIndexSQL(change.Table, change.Index))
+	case define.ChangeDropIndex:
+		// Indexes live in the schema of their table
+		index := change.Index.Name
+		if idx := strings.LastIndexByte(change.Table, '.'); idx >= 0 {
+			index = change.Table[:idx+1] + index
+		}
+		statements = append(statements, "DROP INDEX IF EXISTS "+f.quoteIdentifier(index))
+	case define.ChangeAddForeignKey:
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s", table, f.foreignKeySQL(change.ForeignKey)))
+	case define.ChangeDropForeignKey:
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, f.quoteIdentifier(change.ForeignKey.Name)))
+	default:
+		return []*define.SqlProto{{SqlType: define.Exec, Error: fmt.Errorf("unsupported schema change %q", change.Kind)}}
+	}
+
+	protos := make([]*define.SqlProto, len(statements))
+	for i, stmt := range statements {
+		protos[i] = &define.SqlProto{SqlType: define.Exec, Sql: stmt, Table: change.Table}
+	}
+	return protos
+}
+
+// alterType returns the type to use in ALTER COLUMN TYPE, where serial pseudo-types are not allowed
+func alterType(columnType string) string {
+	switch strings.ToUpper(columnType) {
+	case "SERIAL":
+		return "INTEGER"
+	case "BIGSERIAL":
+		return "BIGINT"
+	case "SMALLSERIAL":
+		return "SMALLINT"
+	}
+	return columnType
+}
+
+// createIndexSQL renders a CREATE INDEX statement
+func (f *Factory) createIndexSQL(table string, index *define.IndexDef) string {
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s)", unique, f.quoteIdentifier(index.Name),
+		f.quoteIdentifier(table), strings.Join(f.quoteIdentifiers(index.Columns), ", "))
+}
+
+// foreignKeySQL renders a foreign key constraint
+func (f *Factory) foreignKeySQL(fk *define.ForeignKeyDef) string {
+	constraint := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)", f.quoteIdentifier(fk.Name),
+		strings.Join(f.quoteIdentifiers(fk.Columns), ", "), f.quoteIdentifier(fk.RefTable), strings.Join(f.quoteIdentifiers(fk.RefColumns), ", "))
+	if fk.OnDelete != "" {
+		constraint += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		constraint += " ON UPDATE " + fk.OnUpdate
+	}
+	return constraint
+}
+
+// commentSQL renders a COMMENT ON COLUMN statement
+func (f *Factory) commentSQL(table string, col *define.ColumnDef) string {
+	return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS '%s'", f.quoteIdentifier(table), f.quoteIdentifier(col.Name),
+		strings.ReplaceAll(col.Comment, "'", "''"))
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaOrder struct {
+	ID       int64   `gom:"id,@"`
+	UserID   int64   `gom:"user_id,notnull,index:idx_user_status,references:users(id),onDelete:cascade"`
+	Status   string  `gom:"status,notnull,index:idx_user_status,priority:1"`
+	Code     string  `gom:"code,unique,comment:'order code, shown to users'"`
+	Note     *string `gom:"note,index"`
+	internal string
+}
+
+func TestFactory_CreateTableWithIndexesAndForeignKeys(t *testing.T) {
+	f := &Factory{}
+	def := f.DescribeModel("orders", reflect.TypeOf(schemaOrder{}))
+
+	require.Len(t, def.Indexes, 2)
+	assert.Equal(t, []string{"status", "user_id"}, def.Indexes[0].Columns)
+	assert.Equal(t, "idx_orders_note", def.Indexes[1].Name)
+	require.Len(t, def.ForeignKeys, 1)
+	assert.Equal(t, "CASCADE", def.ForeignKeys[0].OnDelete)
+
+	var sqls []string
+	for _, proto := range f.BuildSchemaChange(&define.SchemaChange{Kind: define.ChangeCreateTable, Table: "orders", TableDef: def}) {
+		sqls = append(sqls, proto.Sql)
+	}
+	assert.Equal(t, []string{
+		`CREATE TABLE IF NOT EXISTS "orders" ("id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, "user_id" BIGINT NOT NULL, "status" VARCHAR(255) NOT NULL, ` +
+			`"code" VARCHAR(255) NOT NULL UNIQUE, "note" VARCHAR(255), ` +
+			`CONSTRAINT "fk_orders_user_id" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE)`,
+		`COMMENT ON COLUMN "orders"."code" IS 'order code, shown to users'`,
+		`CREATE INDEX IF NOT EXISTS "idx_user_status" ON "orders" ("status", "user_id")`,
+		`CREATE INDEX IF NOT EXISTS "idx_orders_note" ON "orders" ("note")`,
+	}, sqls)
+}
+
+type Decimal struct{ value string }
+
+type schemaProduct struct {
+	ID       int64                  `gom:"id,@"`
+	Code     [16]byte               `gom:"code,type:uuid"`
+	Name     string                 `gom:"name,size:64,default:'unnamed'"`
+	Price    Decimal                `gom:"price,precision:12,scale:2"`
+	Weight   float64                `gom:"weight,null"`
+	Status   string                 `gom:"status,enum:draft|published"`
+	Attrs    map[string]interface{} `gom:"attrs"`
+	Tags     []string               `gom:"tags"`
+	Image    []byte                 `gom:"image"`
+	Stock    sql.NullInt32          `gom:"stock"`
+	Body     string                 `gom:"body,size:20000000"`
+	Archived *time.Time             `gom:"archived_at,precision:3"`
+}
+
+func (p *schemaProduct) TableOptions() define.TableOptions {
+	return define.TableOptions{Comment: "products on sale"}
+}
+
+func TestFactory_CreateTableColumnTypes(t *testing.T) {
+	f := &Factory{}
+	def := f.DescribeModel("products", reflect.TypeOf(&schemaProduct{}))
+
+	var sqls []string
+	for _, proto := range f.BuildSchemaChange(&define.SchemaChange{Kind: define.ChangeCreateTable, Table: "products", TableDef: def}) {
+		sqls = append(sqls, proto.Sql)
+	}
+	assert.Equal(t, []string{
+		`CREATE TABLE IF NOT EXISTS "products" ("id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, "code" uuid NOT NULL, ` +
+			`"name" VARCHAR(64) NOT NULL DEFAULT 'unnamed', "price" NUMERIC(12,2) NOT NULL, "weight" DOUBLE PRECISION, ` +
+			`"status" VARCHAR(255) NOT NULL CHECK ("status" IN ('draft', 'published')), "attrs" JSONB NOT NULL, "tags" JSONB NOT NULL, ` +
+			`"image" BYTEA NOT NULL, "stock" INTEGER, "body" TEXT NOT NULL, "archived_at" TIMESTAMP(3))`,
+		`COMMENT ON TABLE "products" IS 'products on sale'`,
+	}, sqls)
+
+	assert.True(t, f.ColumnTypeMatches(def.Column("attrs"), &define.ColumnInfo{TypeName: "jsonb"}))
+	assert.True(t, f.ColumnTypeMatches(def.Column("price"), &define.ColumnInfo{TypeName: "numeric", Precision: 12, Scale: 2}))
+}
+
+type schemaMembership struct {
+	TeamID  int64   `gom:"team_id,!"`
+	UserID  int64   `gom:"user_id,pk"`
+	Role    *string `gom:"role,notnull"`
+	Note    string
+	Ignored string `gom:"-"`
+}
+
+func TestFactory_DescribeModelKeys(t *testing.T) {
+	f := &Factory{}
+	def := f.DescribeModel("memberships", reflect.TypeOf(schemaMembership{}))
+
+	// ! 与 pk 都是主键，notnull 控制可空性，没有 gom 标签的字段不是列
+	assert.Equal(t, []string{"team_id", "user_id"}, def.PrimaryKeys)
+	require.Len(t, def.Columns, 3)
+	assert.False(t, def.Column("team_id").AutoIncrement)
+	assert.False(t, def.Column("role").Nullable)
+}
+
+type UUID [16]byte
+
+type schemaRevision struct {
+	ID       UUID `gom:"id,pk"`
+	Revision int  `gom:"revision,pk"`
+}
+
+func TestFactory_DescribeModelID(t *testing.T) {
+	f := &Factory{}
+	def := f.DescribeModel("revisions", reflect.TypeOf(schemaRevision{}))
+	assert.Equal(t, []string{"id", "revision"}, def.PrimaryKeys)
+	assert.Equal(t, "UUID", def.Column("id").Type)
+	assert.False(t, def.Column("id").AutoIncrement)
+
+	def = f.DescribeModel("events", reflect.TypeOf(struct {
+		ID int64 `gom:"id"`
+	}{}))
+	assert.Empty(t, def.PrimaryKeys)
+	assert.False(t, def.Column("id").AutoIncrement, "id is not special")
+}
+
+func TestFactory_BuildSchemaChangeDrops(t *testing.T) {
+	f := &Factory{}
+	var sqls []string
+	for _, change := range []*define.SchemaChange{
+		{Kind: define.ChangeDropIndex, Table: "report.orders", Index: &define.IndexDef{Name: "idx_orders_note"}},
+		{Kind: define.ChangeDropIndex, Table: "orders", Index: &define.IndexDef{Name: "idx_orders_note"}},
+		{Kind: define.ChangeDropForeignKey, Table: "orders", ForeignKey: &define.ForeignKeyDef{Name: "fk_orders_user_id"}},
+	} {
+		for _, proto := range f.BuildSchemaChange(change) {
+			sqls = append(sqls, proto.Sql)
+		}
+	}
+	assert.Equal(t, []string{
+		`DROP INDEX IF EXISTS "report"."idx_orders_note"`,
+		`DROP INDEX IF EXISTS "idx_orders_note"`,
+		`ALTER TABLE "orders" DROP CONSTRAINT "fk_orders_user_id"`,
+	}, sqls)
+}
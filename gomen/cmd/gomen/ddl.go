@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kmlixh/gom/v4/gomen"
+)
+
+// runDDL 执行 gomen ddl 子命令：解析模型包，再用 go run 运行引用这些模型的临时程序生成脚本
+func runDDL(args []string) int {
+	fs := flag.NewFlagSet("ddl", flag.ContinueOnError)
+	pkgDir := fs.String("package", ".", "模型所在的包目录")
+	dialect := fs.String("dialect", "", "数据库类型 (mysql/postgres)")
+	url := fs.String("url", "", "数据库连接URL，设置时只生成与该库的差异")
+	migrations := fs.String("migrations", "", "迁移文件目录，设置时把脚本写入新编号的迁移文件")
+	name := fs.String("name", "models", "迁移文件名中的名称")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法:")
+		fmt.Fprintln(fs.Output(), "  gomen ddl -package <dir> -dialect <mysql|postgres> [选项]")
+		fmt.Fprintln(fs.Output(), "\n选项:")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\n示例:")
+		fmt.Fprintln(fs.Output(), "  gomen ddl -package ./models -dialect postgres > schema.sql")
+		fmt.Fprintln(fs.Output(), "  gomen ddl -package ./models -dialect mysql -url \"user:pass@tcp(localhost:3306)/app\" -migrations migrations -name add_orders")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *dialect == "" {
+		fs.Usage()
+		return 2
+	}
+
+	pkg, err := gomen.ScanModels(*pkgDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取模型失败: %v\n", err)
+		return 1
+	}
+	if len(pkg.Models) == 0 {
+		fmt.Fprintf(os.Stderr, "%s 中没有带 gom 标签或 TableName() 方法的结构体\n", *pkgDir)
+		return 1
+	}
+
+	// 临时程序运行在模型包目录下，相对路径需要先转换
+	runArgs := []string{"-dialect", *dialect, "-url", *url, "-name", *name}
+	if *migrations != "" {
+		dir, err := filepath.Abs(*migrations)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "无效的迁移文件目录: %v\n", err)
+			return 2
+		}
+		runArgs = append(runArgs, "-migrations", dir)
+	}
+
+	code, err := runModelProgram(pkg, runArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "生成表结构失败: %v\n", err)
+		return 1
+	}
+	return code
+}
+
+// runModelProgram 在模型包的模块中生成并运行临时程序，返回其退出码
+func runModelProgram(pkg *gomen.ModelPackage, args []string) (int, error) {
+	list := exec.Command("go", "list", "-f", "{{.ImportPath}}", ".")
+	list.Dir = pkg.Dir
+	list.Stderr = os.Stderr
+	out, err := list.Output()
+	if err != nil {
+		return 0, fmt.Errorf("获取 %s 的导入路径失败: %v", pkg.Dir, err)
+	}
+
+	source, err := gomen.DDLProgram(strings.TrimSpace(string(out)), pkg)
+	if err != nil {
+		return 0, err
+	}
+	// 以 . 开头的目录不会被 ./... 匹配到
+	tmp, err := os.MkdirTemp(pkg.Dir, ".gomen-ddl-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.WriteFile(filepath.Join(tmp, "main.go"), source, 0644); err != nil {
+		return 0, err
+	}
+
+	run := exec.Command("go", append([]string{"run", "./" + filepath.Base(tmp)}, args...)...)
+	run.Dir = pkg.Dir
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	err = run.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kmlixh/gom/v4"
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/gomen"
+)
+
+// runDiff 执行 gomen diff 子命令：表结构一致时返回 0，存在差异时返回 1，出错时返回 2
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	driver := fs.String("type", "", "数据库类型 (mysql/postgres)")
+	from := fs.String("from", "", "源数据库连接URL")
+	to := fs.String("to", "", "目标数据库连接URL")
+	pattern := fs.String("pattern", "*", "表名匹配模式 (PostgreSQL 可用 schema.table* 格式)")
+	format := fs.String("format", "text", "输出格式 (text/json/sql)，sql 为把源库变为与目标库一致的脚本")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法:")
+		fmt.Fprintln(fs.Output(), "  gomen diff -type <mysql|postgres> -from <url> -to <url> [选项]")
+		fmt.Fprintln(fs.Output(), "\n选项:")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\n示例:")
+		fmt.Fprintln(fs.Output(), "  gomen diff -type postgres -from \"postgres://.../staging\" -to \"postgres://.../prod\" -pattern \"public.*\"")
+		fmt.Fprintln(fs.Output(), "  gomen diff -type mysql -from \"user:pass@tcp(staging:3306)/app\" -to \"user:pass@tcp(prod:3306)/app\" -format sql")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *driver == "" || *from == "" || *to == "" {
+		fs.Usage()
+		return 2
+	}
+	if *format != "text" && *format != "json" && *format != "sql" {
+		fmt.Fprintf(os.Stderr, "不支持的输出格式: %s\n", *format)
+		return 2
+	}
+
+	source, fromSchema, err := loadSchema(*driver, *from, *pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取源库失败: %v\n", err)
+		return 2
+	}
+	defer source.Close()
+	target, toSchema, err := loadSchema(*driver, *to, *pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取目标库失败: %v\n", err)
+		return 2
+	}
+	defer target.Close()
+
+	diff := gomen.DiffSchemas(fromSchema, toSchema)
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(diff)
+	case "sql":
+		var script string
+		if script, err = diff.SQL(source.Factory); err == nil {
+			_, err = fmt.Print(script)
+		}
+	default:
+		diff.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "输出差异失败: %v\n", err)
+		return 2
+	}
+	if diff.Empty() {
+		return 0
+	}
+	return 1
+}
+
+// loadSchema 连接数据库并读取匹配 pattern 的表结构
+func loadSchema(driver, url, pattern string) (*gom.DB, map[string]*define.TableInfo, error) {
+	db, err := gom.Open(driver, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, err := gomen.LoadSchema(db, pattern)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return db, schema, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/kmlixh/gom/v4"
+	_ "github.com/kmlixh/gom/v4/factory/mysql"
+	_ "github.com/kmlixh/gom/v4/factory/postgres"
+	"github.com/kmlixh/gom/v4/migrate"
+)
+
+// runMigrate 执行 gomen migrate 子命令，返回进程退出码
+func runMigrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	driver := fs.String("type", "", "数据库类型 (mysql/postgres)")
+	url := fs.String("url", "", "数据库连接URL")
+	dir := fs.String("dir", "migrations", "迁移文件目录")
+	table := fs.String("table", migrate.DefaultTable, "迁移记录表")
+	dryRun := fs.Bool("dry-run", false, "只输出将要执行的SQL，不修改数据库")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法:")
+		fmt.Fprintln(fs.Output(), "  gomen migrate [选项] up | down [n] | to <version> | status")
+		fmt.Fprintln(fs.Output(), "\n选项:")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\n示例:")
+		fmt.Fprintln(fs.Output(), "  gomen migrate -type mysql -url \"user:password@tcp(localhost:3306)/dbname\" up")
+		fmt.Fprintln(fs.Output(), "  gomen migrate -type postgres -url \"postgres://...\" -dry-run down 1")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 || *driver == "" || *url == "" {
+		fs.Usage()
+		return 2
+	}
+
+	db, err := gom.Open(*driver, *url, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "连接数据库失败: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	m := migrate.New(db, migrate.Options{Table: *table, DryRun: *dryRun, Out: os.Stdout})
+	if err := m.AddFS(os.DirFS(*dir), "."); err != nil {
+		fmt.Fprintf(os.Stderr, "读取迁移文件失败: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	command, rest := fs.Arg(0), fs.Args()[1:]
+	switch command {
+	case "up":
+		_, err = m.Up(ctx)
+	case "down":
+		n := 1
+		if len(rest) > 0 {
+			if n, err = strconv.Atoi(rest[0]); err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "无效的回滚数量: %s\n", rest[0])
+				return 2
+			}
+		}
+		_, err = m.Down(ctx, n)
+	case "to":
+		if len(rest) != 1 {
+			fs.Usage()
+			return 2
+		}
+		version, perr := strconv.ParseInt(rest[0], 10, 64)
+		if perr != nil {
+			fmt.Fprintf(os.Stderr, "无效的版本号: %s\n", rest[0])
+			return 2
+		}
+		_, err = m.To(ctx, version)
+	case "status":
+		var statuses []migrate.Status
+		if statuses, err = m.Status(ctx); err == nil {
+			printStatus(statuses)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "未知的迁移命令: %s\n", command)
+		fs.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "迁移失败: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printStatus 输出迁移状态表
+func printStatus(statuses []migrate.Status) {
+	for _, s := range statuses {
+		state := "pending"
+		switch {
+		case s.Missing:
+			state = "missing"
+		case s.Changed:
+			state = "changed"
+		case s.Applied:
+			state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%-20d %-40s %s\n", s.Version, s.Name, state)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kmlixh/gom/v4"
+	"github.com/kmlixh/gom/v4/gomen"
+)
+
+// runSnapshot 执行 gomen snapshot 子命令：把匹配的表结构写入 JSON 快照，供离线生成代码
+func runSnapshot(args []string) int {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	driver := fs.String("type", "", "数据库类型 (mysql/postgres)")
+	url := fs.String("url", "", "数据库连接URL")
+	pattern := fs.String("pattern", "*", "表名匹配模式 (PostgreSQL 可用 schema.table* 格式)")
+	out := fs.String("out", "", "快照文件，为空时输出到标准输出")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法:")
+		fmt.Fprintln(fs.Output(), "  gomen snapshot -type <mysql|postgres> -url <url> [选项]")
+		fmt.Fprintln(fs.Output(), "\n选项:")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\n示例:")
+		fmt.Fprintln(fs.Output(), "  gomen snapshot -type postgres -url \"postgres://...\" -pattern \"public.*\" -out schema.json")
+		fmt.Fprintln(fs.Output(), "  gomen -snapshot schema.json -out models -package models")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *driver == "" || *url == "" {
+		fs.Usage()
+		return 2
+	}
+
+	db, err := gom.Open(*driver, *url, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "连接数据库失败: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	snapshot, err := gomen.TakeSnapshot(db, *driver, *pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取表结构失败: %v\n", err)
+		return 1
+	}
+
+	if *out == "" {
+		err = snapshot.Write(os.Stdout)
+	} else {
+		err = writeSnapshot(*out, snapshot)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "写入快照失败: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writeSnapshot 把快照写入文件
+func writeSnapshot(name string, snapshot *gomen.Snapshot) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,230 @@
+package gomen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kmlixh/gom/v4/define"
+	"gopkg.in/yaml.v3"
+)
+
+// Config gomen 配置文件，用于覆盖类型映射、命名规则和生成内容
+//
+//	{
+//	  "type_map": {"tinyint(1)": "bool", "numeric": "github.com/shopspring/decimal.Decimal"},
+//	  "initialisms": ["ID", "URL", "HTTP"],
+//	  "include": ["user*"],
+//	  "exclude": ["*_bak"],
+//	  "tags": ["json"],
+//	  "tables": {
+//	    "t_user": {
+//	      "struct_name": "Account",
+//	      "columns": {"uid": {"field_name": "UserID", "tags": {"validate": "required"}}},
+//	      "relations": {"Orders": {"field_name": "PlacedOrders"}, "AuditLogs": {"skip": true}}
+//	    }
+//	  }
+//	}
+type Config struct {
+	// TypeMap 把数据库类型映射为 Go 类型，键为完整列类型（如 tinyint(1)）或类型名（如 numeric），不区分大小写
+	// 值为带导入路径的类型时（如 github.com/shopspring/decimal.Decimal）自动导入对应的包
+	TypeMap map[string]string `json:"type_map" yaml:"type_map"`
+
+	// Initialisms 生成名称时整体大写的缩写，如 ID 使 user_id 生成 UserID
+	Initialisms []string `json:"initialisms" yaml:"initialisms"`
+
+	// TrimPrefix 和 TrimSuffix 生成结构体名时去掉的表名前缀和后缀，设置后覆盖命令行参数
+	TrimPrefix string `json:"trim_prefix" yaml:"trim_prefix"`
+	TrimSuffix string `json:"trim_suffix" yaml:"trim_suffix"`
+
+	// Include 和 Exclude 按表名过滤，支持 * 通配符；Include 为空时包含所有表
+	Include []string `json:"include" yaml:"include"`
+	Exclude []string `json:"exclude" yaml:"exclude"`
+
+	// Tags 为每个字段额外生成的标签，值为列名，如 json、yaml
+	Tags []string `json:"tags" yaml:"tags"`
+
+	// Hooks 是否生成 BeforeCreate/BeforeUpdate 钩子，默认生成
+	Hooks *bool `json:"hooks" yaml:"hooks"`
+
+	// Tables 按表名设置的覆盖项
+	Tables map[string]*TableConfig `json:"tables" yaml:"tables"`
+}
+
+// TableConfig 单个表的覆盖项
+type TableConfig struct {
+	StructName string                     `json:"struct_name" yaml:"struct_name"` // 结构体名
+	Hooks      *bool                      `json:"hooks" yaml:"hooks"`             // 是否生成钩子，覆盖全局设置
+	Columns    map[string]*ColumnConfig   `json:"columns" yaml:"columns"`         // 按列名设置的覆盖项
+	Relations  map[string]*RelationConfig `json:"relations" yaml:"relations"`     // 按默认关联字段名设置的覆盖项
+}
+
+// ColumnConfig 单个列的覆盖项
+type ColumnConfig struct {
+	FieldName string            `json:"field_name" yaml:"field_name"` // 字段名
+	Type      string            `json:"type" yaml:"type"`             // Go 类型，规则同 Config.TypeMap 的值
+	Tags      map[string]string `json:"tags" yaml:"tags"`             // 额外的标签，如 {"validate": "required,email"}
+	Skip      bool              `json:"skip" yaml:"skip"`             // 不生成该字段
+}
+
+// RelationConfig 单个关联字段的覆盖项
+type RelationConfig struct {
+	FieldName string `json:"field_name" yaml:"field_name"` // 字段名
+	Skip      bool   `json:"skip" yaml:"skip"`             // 不生成该关联
+}
+
+// LoadConfig 读取配置文件，扩展名为 .yaml 或 .yml 时按 YAML 解析，否则按 JSON 解析
+func LoadConfig(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &config)
+	default:
+		err = json.Unmarshal(data, &config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %v", file, err)
+	}
+	return &config, nil
+}
+
+// includeTable 表是否需要生成；schema.table 格式的表名同时按完整名称和表名匹配
+func (c *Config) includeTable(table string) bool {
+	if c == nil {
+		return true
+	}
+	name := table
+	if idx := strings.LastIndex(table, "."); idx >= 0 {
+		name = table[idx+1:]
+	}
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, table); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(c.Include) > 0 && !matches(c.Include) {
+		return false
+	}
+	return !matches(c.Exclude)
+}
+
+// table 返回表的覆盖项，也按去掉 schema 的表名查找
+func (c *Config) table(table string) *TableConfig {
+	if c == nil {
+		return nil
+	}
+	if t, ok := c.Tables[table]; ok {
+		return t
+	}
+	if idx := strings.LastIndex(table, "."); idx >= 0 {
+		return c.Tables[table[idx+1:]]
+	}
+	return nil
+}
+
+// column 返回列的覆盖项
+func (c *Config) column(table, column string) *ColumnConfig {
+	if t := c.table(table); t != nil {
+		return t.Columns[column]
+	}
+	return nil
+}
+
+// relation 返回关联字段的覆盖项，field 为默认生成的字段名
+func (c *Config) relation(table, field string) *RelationConfig {
+	if t := c.table(table); t != nil {
+		return t.Relations[field]
+	}
+	return nil
+}
+
+// hooks 表是否生成钩子
+func (c *Config) hooks(table string) bool {
+	if t := c.table(table); t != nil && t.Hooks != nil {
+		return *t.Hooks
+	}
+	if c != nil && c.Hooks != nil {
+		return *c.Hooks
+	}
+	return true
+}
+
+// mappedType 返回配置中列的 Go 类型，未配置时返回空字符串
+// 完整列类型优先于类型名；同一键先精确匹配，再按排序后的键不区分大小写匹配，保证结果确定
+func (c *Config) mappedType(table string, col *define.ColumnInfo) string {
+	if override := c.column(table, col.Name); override != nil && override.Type != "" {
+		return override.Type
+	}
+	if c == nil {
+		return ""
+	}
+	for _, key := range []string{col.ColumnType, col.TypeName} {
+		if key == "" {
+			continue
+		}
+		if goType, ok := c.TypeMap[key]; ok {
+			return goType
+		}
+		dbTypes := make([]string, 0, len(c.TypeMap))
+		for dbType := range c.TypeMap {
+			dbTypes = append(dbTypes, dbType)
+		}
+		sort.Strings(dbTypes)
+		for _, dbType := range dbTypes {
+			if strings.EqualFold(dbType, key) {
+				return c.TypeMap[dbType]
+			}
+		}
+	}
+	return ""
+}
+
+// goName 把下划线名称转换为 Go 名称，并按 Initialisms 整体大写缩写
+func (c *Config) goName(name string) string {
+	if c == nil || len(c.Initialisms) == 0 {
+		return toGoName(name)
+	}
+	initialisms := make(map[string]string, len(c.Initialisms))
+	for _, word := range c.Initialisms {
+		initialisms[strings.ToLower(word)] = word
+	}
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if word, ok := initialisms[strings.ToLower(part)]; ok {
+			parts[i] = word
+		} else {
+			parts[i] = strings.Title(part)
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// splitGoType 把带导入路径的类型拆分为导入路径和类型，如
+// github.com/shopspring/decimal.Decimal 拆分为 github.com/shopspring/decimal 和 decimal.Decimal
+func splitGoType(goType string) (string, string) {
+	prefix := strings.TrimLeft(goType, "*[]")
+	slash := strings.LastIndex(prefix, "/")
+	if slash < 0 {
+		return "", goType
+	}
+	dot := strings.Index(prefix[slash:], ".")
+	if dot < 0 {
+		return "", goType
+	}
+	importPath := prefix[:slash+dot]
+	return importPath, goType[:len(goType)-len(prefix)] + prefix[slash+1:]
+}
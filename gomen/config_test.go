@@ -0,0 +1,119 @@
+package gomen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `{
+  "type_map": {"tinyint(1)": "bool", "NUMERIC": "github.com/shopspring/decimal.Decimal"},
+  "initialisms": ["ID", "URL"],
+  "exclude": ["*_bak"],
+  "tags": ["json"],
+  "hooks": false,
+  "tables": {
+    "t_user": {
+      "struct_name": "Account",
+      "columns": {
+        "uid": {"field_name": "UserID", "tags": {"validate": "required"}},
+        "secret": {"skip": true},
+        "avatar_url": {"type": "example.com/app/types.Link"}
+      }
+    }
+  }
+}`
+
+func TestLoadConfig(t *testing.T) {
+	config, err := loadTestConfig(t)
+	require.NoError(t, err)
+	assert.Equal(t, "Account", config.Tables["t_user"].StructName)
+
+	file := filepath.Join(t.TempDir(), "gomen.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`type_map:
+  tinyint(1): bool
+initialisms: [ID, URL]
+hooks: false
+tables:
+  t_user:
+    struct_name: Account
+    columns:
+      uid: {field_name: UserID, tags: {validate: required}}
+`), 0o644))
+	yamlConfig, err := LoadConfig(file)
+	require.NoError(t, err)
+	assert.Equal(t, "Account", yamlConfig.Tables["t_user"].StructName)
+	assert.Equal(t, "UserID", yamlConfig.column("t_user", "uid").FieldName)
+	assert.Equal(t, map[string]string{"validate": "required"}, yamlConfig.column("t_user", "uid").Tags)
+	assert.Equal(t, []string{"ID", "URL"}, yamlConfig.Initialisms)
+	assert.False(t, yamlConfig.hooks("t_user"))
+
+	require.NoError(t, os.WriteFile(file, []byte("tables: [\n"), 0o644))
+	_, err = LoadConfig(file)
+	assert.ErrorContains(t, err, "gomen.yaml")
+}
+
+func TestMappedTypePriority(t *testing.T) {
+	config := &Config{TypeMap: map[string]string{"Numeric": "float64", "NUMERIC": "string", "numeric": "decimal.Decimal", "numeric(10,2)": "int64"}}
+	// 精确匹配优先，其次按排序后的键不区分大小写匹配
+	assert.Equal(t, "decimal.Decimal", config.mappedType("t", &define.ColumnInfo{TypeName: "numeric"}))
+	assert.Equal(t, "int64", config.mappedType("t", &define.ColumnInfo{TypeName: "numeric", ColumnType: "numeric(10,2)"}))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "string", config.mappedType("t", &define.ColumnInfo{TypeName: "NuMeric"}))
+	}
+}
+
+func TestGenerateWithConfig(t *testing.T) {
+	config, err := loadTestConfig(t)
+	require.NoError(t, err)
+
+	schema := fakeSchema{
+		"public.t_user": {TableName: "t_user", Columns: []define.ColumnInfo{
+			{Name: "uid", DataType: "int64"},
+			{Name: "secret", DataType: "string"},
+			{Name: "active", TypeName: "tinyint", ColumnType: "tinyint(1)", DataType: "int32"},
+			{Name: "balance", TypeName: "numeric", DataType: "float64", IsNullable: true},
+			{Name: "avatar_url", DataType: "string", IsNullable: true},
+			{Name: "created_at", DataType: "time.Time"},
+		}},
+		"t_user_bak": {TableName: "t_user_bak"},
+	}
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom", OutputDir: t.TempDir(), Config: config}, db: schema}
+
+	var buf bytes.Buffer
+	require.NoError(t, g.GenerateTableStruct("public.t_user", &buf))
+	code := compact(buf.String())
+	assert.Contains(t, code, "type Account struct")
+	assert.Contains(t, code, "UserID int64 `gom:\"uid\" json:\"uid\" validate:\"required\"`")
+	assert.NotContains(t, code, "Secret")
+	assert.Contains(t, code, "Active bool `gom:\"active\" json:\"active\"`")
+	assert.Contains(t, code, "Balance *decimal.Decimal")
+	assert.Contains(t, code, "AvatarURL *types.Link")
+	assert.Contains(t, code, `"example.com/app/types"`)
+	assert.Contains(t, code, "AvatarURL define.Column[types.Link]")
+	assert.NotContains(t, code, "BeforeCreate")
+
+	assert.True(t, config.includeTable("public.t_user"))
+	assert.False(t, config.includeTable("t_user_bak"))
+}
+
+func TestSplitGoType(t *testing.T) {
+	path, typ := splitGoType("*github.com/shopspring/decimal.Decimal")
+	assert.Equal(t, "github.com/shopspring/decimal", path)
+	assert.Equal(t, "*decimal.Decimal", typ)
+
+	path, typ = splitGoType("sql.NullString")
+	assert.Equal(t, "", path)
+	assert.Equal(t, "sql.NullString", typ)
+}
+
+func loadTestConfig(t *testing.T) (*Config, error) {
+	file := filepath.Join(t.TempDir(), "gomen.json")
+	require.NoError(t, os.WriteFile(file, []byte(testConfig), 0644))
+	return LoadConfig(file)
+}
@@ -0,0 +1,270 @@
+package gomen
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kmlixh/gom/v4"
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// ModelPackage Go 包中可以生成表结构的模型
+type ModelPackage struct {
+	Name   string   // 包名
+	Dir    string   // 包所在目录
+	Models []string // 模型结构体名，按文件名和声明顺序排列
+}
+
+// ScanModels 解析 dir 中的 Go 源文件（不含测试文件），找出带 gom 标签或 TableName() 方法的导出结构体
+func ScanModels(dir string) (*ModelPackage, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	pkg := &ModelPackage{Dir: dir}
+	var structs []string
+	tagged := make(map[string]bool)
+	named := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %v", file, err)
+		}
+		if pkg.Name == "" {
+			pkg.Name = f.Name.Name
+		} else if pkg.Name != f.Name.Name {
+			return nil, fmt.Errorf("%s 中包含多个包: %s, %s", dir, pkg.Name, f.Name.Name)
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					typeSpec, ok := spec.(*ast.TypeSpec)
+					if !ok || !typeSpec.Name.IsExported() || typeSpec.TypeParams != nil {
+						continue
+					}
+					if st, ok := typeSpec.Type.(*ast.StructType); ok {
+						structs = append(structs, typeSpec.Name.Name)
+						tagged[typeSpec.Name.Name] = hasGomTag(st)
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Name.Name == "TableName" && decl.Recv != nil && len(decl.Recv.List) == 1 {
+					if recv := receiverName(decl.Recv.List[0].Type); recv != "" {
+						named[recv] = true
+					}
+				}
+			}
+		}
+	}
+	if pkg.Name == "" {
+		return nil, fmt.Errorf("%s 中没有 Go 源文件", dir)
+	}
+	if pkg.Name == "main" {
+		return nil, fmt.Errorf("%s 是 main 包，无法被导入", dir)
+	}
+	for _, name := range structs {
+		if tagged[name] || named[name] {
+			pkg.Models = append(pkg.Models, name)
+		}
+	}
+	return pkg, nil
+}
+
+// hasGomTag 结构体是否有字段带 gom 标签
+func hasGomTag(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		if _, ok := reflect.StructTag(tag).Lookup("gom"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// receiverName 返回方法接收者的类型名，如 *User 返回 User
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// ddlProgramTemplate gomen ddl 临时程序的模板，程序把模型交给 RunDDL 处理
+var ddlProgramTemplate = template.Must(template.New("ddl").Parse(`{{.Header}}
+
+package main
+
+import (
+	"os"
+
+	"github.com/kmlixh/gom/v4/gomen"
+	models "{{.ImportPath}}"
+)
+
+func main() {
+	os.Exit(gomen.RunDDL(os.Args[1:],
+{{- range .Models}}
+		&models.{{.}}{},
+{{- end}}
+	))
+}
+`))
+
+// DDLProgram 生成 gomen ddl 临时程序的源码，importPath 为模型包的导入路径
+func DDLProgram(importPath string, pkg *ModelPackage) ([]byte, error) {
+	var buf bytes.Buffer
+	err := ddlProgramTemplate.Execute(&buf, map[string]interface{}{
+		"Header":     generatedHeader,
+		"ImportPath": importPath,
+		"Models":     pkg.Models,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// DDLOptions 从模型生成表结构的选项
+type DDLOptions struct {
+	Dialect       string // 数据库类型 (mysql/postgres)
+	URL           string // 数据库连接URL，设置时生成把该库变为与模型一致的脚本，否则生成完整的建表脚本
+	MigrationsDir string // 设置时把脚本写入该目录下新编号的迁移文件
+	Name          string // 迁移文件名中的名称
+}
+
+// ModelDDL 生成 models 的建表脚本；设置 URL 时生成与该库的差异，删除列和修改类型只以注释列出
+func ModelDDL(opts DDLOptions, models ...interface{}) (string, error) {
+	if opts.URL == "" {
+		factory, err := define.GetFactory(opts.Dialect)
+		if err != nil {
+			return "", err
+		}
+		plan, err := gom.PlanCreateTables(factory, models...)
+		if err != nil {
+			return "", err
+		}
+		return plan.SQL()
+	}
+
+	db, err := gom.Open(opts.Dialect, opts.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("连接数据库失败: %v", err)
+	}
+	defer db.Close()
+	plan, err := db.PlanAutoMigrate(define.AutoMigrateOptions{}, models...)
+	if err != nil {
+		return "", err
+	}
+	return plan.SQL()
+}
+
+// migrationVersionPattern 匹配迁移文件名开头的版本号
+var migrationVersionPattern = regexp.MustCompile(`^(\d+)_.+\.sql$`)
+
+// migrationNameInvalid 迁移文件名中不能使用的字符
+var migrationNameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// WriteMigration 把 script 写入 dir 下新编号的迁移文件，返回文件路径
+// 已有迁移使用 0001 这样的顺序编号时沿用顺序编号，否则使用 20240101120000 这样的时间戳
+func WriteMigration(dir, name, script string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var latest int64
+	var width int
+	for _, entry := range entries {
+		match := migrationVersionPattern.FindStringSubmatch(entry.Name())
+		if match == nil || entry.IsDir() {
+			continue
+		}
+		if version, err := strconv.ParseInt(match[1], 10, 64); err == nil && version >= latest {
+			latest, width = version, len(match[1])
+		}
+	}
+
+	version := now.Format("20060102150405")
+	if latest > 0 && width < len(version) {
+		version = fmt.Sprintf("%0*d", width, latest+1)
+	} else if stamp, _ := strconv.ParseInt(version, 10, 64); stamp <= latest {
+		version = strconv.FormatInt(latest+1, 10)
+	}
+
+	name = strings.Trim(migrationNameInvalid.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		name = "models"
+	}
+	file := filepath.Join(dir, version+"_"+name+".sql")
+	content := "-- +gom Up\n" + script
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// RunDDL 是 gomen ddl 临时程序的入口：输出 models 的建表脚本或迁移文件，返回进程退出码
+func RunDDL(args []string, models ...interface{}) int {
+	var opts DDLOptions
+	fs := flag.NewFlagSet("ddl", flag.ContinueOnError)
+	fs.StringVar(&opts.Dialect, "dialect", "", "数据库类型 (mysql/postgres)")
+	fs.StringVar(&opts.URL, "url", "", "数据库连接URL")
+	fs.StringVar(&opts.MigrationsDir, "migrations", "", "迁移文件目录")
+	fs.StringVar(&opts.Name, "name", "models", "迁移文件名中的名称")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	script, err := ModelDDL(opts, models...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "生成表结构失败: %v\n", err)
+		return 1
+	}
+	if opts.MigrationsDir == "" {
+		fmt.Print(script)
+		return 0
+	}
+	if script == "" {
+		fmt.Fprintln(os.Stderr, "数据库与模型一致，没有生成迁移文件")
+		return 0
+	}
+	file, err := WriteMigration(opts.MigrationsDir, opts.Name, script, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "写入迁移文件失败: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "已生成迁移文件 %s\n", file)
+	return 0
+}
@@ -0,0 +1,96 @@
+package gomen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const modelSource = `package models
+
+type User struct {
+	ID   int64  ` + "`gom:\"id,@\"`" + `
+	Name string ` + "`gom:\"name,size:64\"`" + `
+}
+
+type Order struct {
+	ID int64
+}
+
+func (o *Order) TableName() string { return "orders" }
+
+type Page[T any] struct {
+	Items []T ` + "`gom:\"items\"`" + `
+}
+
+type options struct {
+	Limit int ` + "`gom:\"limit\"`" + `
+}
+
+type Summary struct {
+	Total int
+}
+`
+
+type ddlUser struct {
+	ID   int64  `gom:"id,@"`
+	Name string `gom:"name,size:64"`
+}
+
+func (u *ddlUser) TableName() string { return "users" }
+
+func TestScanModels(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "models.go"), []byte(modelSource), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "models_test.go"), []byte("package models_test\n"), 0644))
+
+	pkg, err := ScanModels(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "models", pkg.Name)
+	assert.Equal(t, []string{"User", "Order"}, pkg.Models)
+
+	source, err := DDLProgram("example.com/app/models", pkg)
+	require.NoError(t, err)
+	assert.Contains(t, string(source), `models "example.com/app/models"`)
+	assert.Contains(t, string(source), "os.Exit(gomen.RunDDL(os.Args[1:],\n\t\t&models.User{},\n\t\t&models.Order{},\n\t))")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+	_, err = ScanModels(dir)
+	assert.Error(t, err)
+}
+
+func TestModelDDL(t *testing.T) {
+	sql, err := ModelDDL(DDLOptions{Dialect: "postgres"}, &ddlUser{})
+	require.NoError(t, err)
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS "users" ("id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, "name" VARCHAR(64) NOT NULL);`+"\n", sql)
+
+	_, err = ModelDDL(DDLOptions{Dialect: "oracle"}, &ddlUser{})
+	assert.Error(t, err)
+}
+
+func TestWriteMigration(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	dir := filepath.Join(t.TempDir(), "migrations")
+	file, err := WriteMigration(dir, "Add users", "CREATE TABLE users ();\n", now)
+	require.NoError(t, err)
+	assert.Equal(t, "20240506070809_add_users.sql", filepath.Base(file))
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "-- +gom Up\nCREATE TABLE users ();\n", string(content))
+
+	// 同一秒内再次生成时版本号递增
+	file, err = WriteMigration(dir, "", "SELECT 1;\n", now)
+	require.NoError(t, err)
+	assert.Equal(t, "20240506070810_models.sql", filepath.Base(file))
+
+	// 沿用已有的顺序编号
+	dir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0007_init.sql"), nil, 0644))
+	file, err = WriteMigration(dir, "orders", "SELECT 1;\n", now)
+	require.NoError(t, err)
+	assert.Equal(t, "0008_orders.sql", filepath.Base(file))
+}
@@ -0,0 +1,1437 @@
+package gomen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/factory/mysql"
+	"github.com/kmlixh/gom/v4/factory/postgres"
+)
+
+// ParseDDL 解析建表脚本，得到与连接数据库时 GetTableInfo 一致的表结构
+// 支持 CREATE TABLE、CREATE [UNIQUE] INDEX、ALTER TABLE 添加约束和列、COMMENT ON 以及 PostgreSQL 的 CREATE TYPE ... AS ENUM，
+// 可以直接解析 mysqldump --no-data 和 pg_dump --schema-only 的输出，其他语句被忽略
+// PostgreSQL 中没有 schema 的表名按 public 处理，表名为 schema.table 格式，与 GetTables 的结果一致
+func ParseDDL(dialect, script string) (*Snapshot, error) {
+	if dialect != "mysql" && dialect != "postgres" {
+		return nil, fmt.Errorf("unsupported driver: %s", dialect)
+	}
+	tokens, err := lexSQL(dialect, script)
+	if err != nil {
+		return nil, err
+	}
+	p := &ddlParser{dialect: dialect, tables: make(map[string]*ddlTable), enums: make(map[string][]string)}
+	start := 0
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) && !tokens[i].is(";") {
+			continue
+		}
+		if i > start {
+			p.tokens, p.pos = tokens[start:i], 0
+			if err := p.statement(); err != nil {
+				return nil, fmt.Errorf("第 %d 行: %v", tokens[start].line, err)
+			}
+		}
+		start = i + 1
+	}
+	return p.snapshot(), nil
+}
+
+// sqlToken 建表脚本中的词法单元
+type sqlToken struct {
+	kind tokenKind
+	text string // 标识符的名称（已去掉引号）、字符串的值或符号
+	line int
+}
+
+type tokenKind int
+
+const (
+	tokenIdent  tokenKind = iota // 未加引号的标识符或关键字
+	tokenQuoted                  // 加引号的标识符
+	tokenString                  // 字符串
+	tokenNumber                  // 数字
+	tokenSymbol                  // 符号
+)
+
+// is 是否为指定的符号
+func (t sqlToken) is(symbol string) bool {
+	return t.kind == tokenSymbol && t.text == symbol
+}
+
+// keyword 是否为指定的关键字，不区分大小写
+func (t sqlToken) keyword(word string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, word)
+}
+
+// name 是否可以作为名称
+func (t sqlToken) name() bool {
+	return t.kind == tokenIdent || t.kind == tokenQuoted
+}
+
+// lexSQL 把脚本拆分为词法单元，去掉注释
+// MySQL 中双引号表示字符串、# 开始注释，/*!50100 ... */ 中的内容与 MySQL 一样按语句处理；PostgreSQL 中双引号表示标识符
+func lexSQL(dialect, script string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	src := []rune(script)
+	line := 1
+	executable := false // 是否在 MySQL 的 /*! ... */ 中
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(src) && src[i+1] == '-', c == '#' && dialect == "mysql":
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case dialect == "mysql" && !executable && strings.HasPrefix(string(src[i:min(i+4, len(src))]), "/*!"),
+			dialect == "mysql" && !executable && strings.HasPrefix(string(src[i:min(i+4, len(src))]), "/*M!"):
+			// 跳过 /*! 或 /*M! 和版本号，其余内容按语句处理
+			for i += 2; i < len(src) && (src[i] == '!' || src[i] == 'M' || unicode.IsDigit(src[i])); i++ {
+			}
+			executable = true
+		case executable && c == '*' && i+1 < len(src) && src[i+1] == '/':
+			executable = false
+			i += 2
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			start := line
+			for i += 2; i+1 < len(src) && !(src[i] == '*' && src[i+1] == '/'); i++ {
+				if src[i] == '\n' {
+					line++
+				}
+			}
+			if i+1 >= len(src) {
+				return nil, fmt.Errorf("第 %d 行: 注释没有结束", start)
+			}
+			i += 2
+		case c == '\'' || c == '`' || c == '"':
+			kind := tokenQuoted
+			if c == '\'' || (c == '"' && dialect == "mysql") {
+				kind = tokenString
+			}
+			start := line
+			var text strings.Builder
+			j := i + 1
+			for ; j < len(src); j++ {
+				if src[j] == '\n' {
+					line++
+				}
+				if src[j] == '\\' && kind == tokenString && dialect == "mysql" && j+1 < len(src) {
+					j++
+					text.WriteRune(unescapeMySQL(src[j]))
+					continue
+				}
+				if src[j] == c {
+					if j+1 < len(src) && src[j+1] == c {
+						j++
+					} else {
+						break
+					}
+				}
+				text.WriteRune(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("第 %d 行: 引号没有结束", start)
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: text.String(), line: start})
+			i = j + 1
+		case c == '$' && dialect == "postgres" && dollarTag(src[i:]) != "":
+			// $$...$$ 或 $tag$...$tag$ 字符串，出现在函数体等被忽略的语句中
+			tag := []rune(dollarTag(src[i:]))
+			rest := string(src[i+len(tag):])
+			end := strings.Index(rest, string(tag))
+			if end < 0 {
+				return nil, fmt.Errorf("第 %d 行: %s 字符串没有结束", line, string(tag))
+			}
+			body := rest[:end]
+			tokens = append(tokens, sqlToken{kind: tokenString, text: body, line: line})
+			line += strings.Count(body, "\n")
+			i += len(tag)*2 + len([]rune(body))
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(src) && (unicode.IsDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenNumber, text: string(src[i:j]), line: line})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(src[j]) || unicode.IsDigit(src[j]) || src[j] == '_' || src[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenIdent, text: string(src[i:j]), line: line})
+			i = j
+		case operatorAt(src[i:]) != "":
+			op := operatorAt(src[i:])
+			tokens = append(tokens, sqlToken{kind: tokenSymbol, text: op, line: line})
+			i += len(op)
+		default:
+			tokens = append(tokens, sqlToken{kind: tokenSymbol, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// operators 由多个字符组成的符号，较长的在前
+var operators = []string{"->>", "#>>", "::", "->", "#>", "<=", ">=", "<>", "!=", "||", "&&", "@>", "<@"}
+
+// operatorAt 返回 src 开头由多个字符组成的符号，不是时返回空
+func operatorAt(src []rune) string {
+	for _, op := range operators {
+		if len(src) >= len(op) && string(src[:len(op)]) == op {
+			return op
+		}
+	}
+	return ""
+}
+
+// unescapeMySQL 返回 MySQL 字符串中反斜杠转义的字符
+func unescapeMySQL(c rune) rune {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	}
+	return c
+}
+
+// dollarTag 返回 src 开头的 $tag$，不是时返回空
+func dollarTag(src []rune) string {
+	for j := 1; j < len(src); j++ {
+		if src[j] == '$' {
+			return string(src[:j+1])
+		}
+		if !unicode.IsLetter(src[j]) && !unicode.IsDigit(src[j]) && src[j] != '_' {
+			return ""
+		}
+	}
+	return ""
+}
+
+// ddlTable 解析过程中的表
+type ddlTable struct {
+	info       *define.TableInfo
+	primary    []string // 主键列，按定义顺序
+	indexes    []define.IndexInfo
+	fks        []define.ForeignKeyInfo
+	checks     []*ddlCheck
+	partitions []string // PostgreSQL 的分区子表，表名为 schema.table
+	// 为未命名的约束生成名称时使用的序号
+	fkSeq int
+}
+
+// ddlCheck 检查约束，未命名的约束在 snapshot 中按数据库的规则命名
+type ddlCheck struct {
+	name  string
+	expr  string
+	names []string // 表达式中可能是列名的标识符
+}
+
+// column 返回名为 name 的列
+func (t *ddlTable) column(name string) *define.ColumnInfo {
+	for i := range t.info.Columns {
+		if strings.EqualFold(t.info.Columns[i].Name, name) {
+			return &t.info.Columns[i]
+		}
+	}
+	return nil
+}
+
+// ddlParser 逐条解析语句
+type ddlParser struct {
+	dialect string
+	tokens  []sqlToken
+	pos     int
+	tables  map[string]*ddlTable
+	order   []string            // 表的定义顺序
+	enums   map[string][]string // PostgreSQL 枚举类型的可选值，以类型名为键
+}
+
+func (p *ddlParser) peek() sqlToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return sqlToken{kind: tokenSymbol, line: -1}
+}
+
+func (p *ddlParser) next() sqlToken {
+	tok := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return tok
+}
+
+// accept 下一个词法单元是关键字序列 words 时跳过并返回 true
+func (p *ddlParser) accept(words ...string) bool {
+	for i, word := range words {
+		if p.pos+i >= len(p.tokens) || !p.tokens[p.pos+i].keyword(word) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+// acceptSymbol 下一个词法单元是符号 symbol 时跳过并返回 true
+func (p *ddlParser) acceptSymbol(symbol string) bool {
+	if p.peek().is(symbol) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ddlParser) expect(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return fmt.Errorf("缺少 %s，遇到 %q", symbol, p.peek().text)
+	}
+	return nil
+}
+
+// skipGroup 跳过从当前的 ( 开始到对应 ) 的内容
+func (p *ddlParser) skipGroup() {
+	depth := 0
+	for p.pos < len(p.tokens) {
+		tok := p.next()
+		switch {
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+			if depth <= 0 {
+				return
+			}
+		}
+	}
+}
+
+// skipUntilComma 跳过当前定义的其余部分，停在同一层的 , 或 ) 之前
+func (p *ddlParser) skipUntilComma() {
+	for p.pos < len(p.tokens) {
+		tok := p.peek()
+		switch {
+		case tok.is(","), tok.is(")"):
+			return
+		case tok.is("("):
+			p.skipGroup()
+		default:
+			p.pos++
+		}
+	}
+}
+
+// qualifiedName 读取 a.b.c 形式的名称
+func (p *ddlParser) qualifiedName() ([]string, error) {
+	tok := p.next()
+	if !tok.name() {
+		return nil, fmt.Errorf("缺少名称，遇到 %q", tok.text)
+	}
+	parts := []string{tok.text}
+	for p.peek().is(".") {
+		p.pos++
+		tok = p.next()
+		if !tok.name() {
+			return nil, fmt.Errorf("缺少名称，遇到 %q", tok.text)
+		}
+		parts = append(parts, tok.text)
+	}
+	return parts, nil
+}
+
+// tableKey 返回表名：MySQL 去掉库名，PostgreSQL 为 schema.table
+func (p *ddlParser) tableKey(parts []string) string {
+	name := parts[len(parts)-1]
+	if p.dialect == "mysql" {
+		return name
+	}
+	schema := "public"
+	if len(parts) > 1 {
+		schema = parts[len(parts)-2]
+	}
+	return schema + "." + name
+}
+
+// table 返回已定义的表
+func (p *ddlParser) table(parts []string) *ddlTable {
+	return p.tables[p.tableKey(parts)]
+}
+
+// statement 解析一条语句
+func (p *ddlParser) statement() error {
+	switch {
+	case p.accept("CREATE"):
+		p.accept("OR", "REPLACE")
+		for p.accept("TEMPORARY") || p.accept("TEMP") || p.accept("UNLOGGED") || p.accept("GLOBAL") || p.accept("LOCAL") {
+		}
+		switch {
+		case p.accept("TABLE"):
+			return p.createTable()
+		case p.accept("TYPE"):
+			return p.createType()
+		case p.accept("UNIQUE", "INDEX"):
+			return p.createIndex(true)
+		case p.accept("INDEX"):
+			return p.createIndex(false)
+		}
+	case p.accept("ALTER", "TABLE"):
+		return p.alterTable()
+	case p.accept("COMMENT", "ON"):
+		return p.commentOn()
+	}
+	return nil
+}
+
+// createTable 解析 CREATE TABLE
+func (p *ddlParser) createTable() error {
+	p.accept("IF", "NOT", "EXISTS")
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if p.accept("PARTITION", "OF") {
+		// PostgreSQL 的分区子表，只记录到父表的分区中
+		parent, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		if table := p.table(parent); table != nil {
+			table.partitions = append(table.partitions, p.tableKey(parts))
+		}
+		return nil
+	}
+	if !p.acceptSymbol("(") {
+		// CREATE TABLE ... AS 和 LIKE 无法得到列定义
+		return nil
+	}
+	key := p.tableKey(parts)
+	table := &ddlTable{info: &define.TableInfo{TableName: key}}
+	if _, ok := p.tables[key]; !ok {
+		p.order = append(p.order, key)
+	}
+	p.tables[key] = table
+
+	for !p.peek().is(")") {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("表 %s 的定义没有结束", key)
+		}
+		if err := p.tableElement(table); err != nil {
+			return fmt.Errorf("表 %s: %v", key, err)
+		}
+		if !p.acceptSymbol(",") && !p.peek().is(")") {
+			return fmt.Errorf("表 %s: 无法解析 %q", key, p.peek().text)
+		}
+	}
+	p.pos++
+	p.tableOptions(table)
+	return nil
+}
+
+// tableElement 解析表定义中的一项：约束、索引或列
+func (p *ddlParser) tableElement(table *ddlTable) error {
+	tok := p.peek()
+	if tok.kind == tokenIdent {
+		switch strings.ToUpper(tok.text) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "CHECK", "EXCLUDE", "LIKE":
+			return p.constraint(table)
+		}
+	}
+	return p.column(table)
+}
+
+// tableOptions 解析表选项：MySQL 的注释、存储引擎和排序规则，以及分区方式
+func (p *ddlParser) tableOptions(table *ddlTable) {
+	for p.pos < len(p.tokens) {
+		switch {
+		case p.accept("COMMENT"):
+			p.acceptSymbol("=")
+			if tok := p.next(); tok.kind == tokenString {
+				table.info.TableComment = tok.text
+			}
+		case p.accept("ENGINE"):
+			p.acceptSymbol("=")
+			table.info.Engine = p.next().text
+		case p.accept("COLLATE"):
+			p.acceptSymbol("=")
+			table.info.Collation = p.next().text
+		case p.accept("PARTITION", "BY"):
+			p.partitionBy(table)
+		case p.peek().is("("):
+			p.skipGroup()
+		default:
+			p.pos++
+		}
+	}
+}
+
+// partitionBy 解析 PARTITION BY 之后的分区方式、分区键和 MySQL 的分区定义
+func (p *ddlParser) partitionBy(table *ddlTable) {
+	var method []string
+	for p.peek().kind == tokenIdent {
+		if p.accept("ALGORITHM") {
+			// MySQL 的 KEY ALGORITHM=2
+			p.acceptSymbol("=")
+			p.pos++
+			continue
+		}
+		method = append(method, strings.ToUpper(p.next().text))
+	}
+	expr, _ := p.groupText()
+	expr = strings.TrimSuffix(strings.TrimPrefix(expr, "("), ")")
+	partition := &define.PartitionInfo{Method: strings.Join(method, " "), Expression: expr}
+	table.info.Partition = partition
+
+	count := 0
+options:
+	for p.pos < len(p.tokens) {
+		switch {
+		case p.accept("PARTITIONS"):
+			count, _ = strconv.Atoi(p.next().text)
+		case p.accept("SUBPARTITION", "BY"):
+			for p.peek().kind == tokenIdent {
+				p.pos++
+			}
+			p.skipGroup()
+		case p.accept("SUBPARTITIONS"):
+			p.pos++
+		case p.acceptSymbol("("):
+			for !p.acceptSymbol(")") && p.pos < len(p.tokens) {
+				p.accept("PARTITION")
+				partition.Partitions = append(partition.Partitions, p.next().text)
+				p.skipUntilComma()
+				p.acceptSymbol(",")
+			}
+		default:
+			// 分区之后的其他表选项
+			break options
+		}
+	}
+	if len(partition.Partitions) == 0 {
+		// PARTITIONS n 时分区名为 p0 到 pn-1
+		for i := 0; i < count; i++ {
+			partition.Partitions = append(partition.Partitions, fmt.Sprintf("p%d", i))
+		}
+	}
+}
+
+// constraint 解析表级约束和 MySQL 的索引定义
+func (p *ddlParser) constraint(table *ddlTable) error {
+	var name string
+	if p.accept("CONSTRAINT") {
+		if tok := p.peek(); tok.name() && !tok.keyword("PRIMARY") && !tok.keyword("UNIQUE") && !tok.keyword("FOREIGN") && !tok.keyword("CHECK") {
+			name = p.next().text
+		}
+	}
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		p.indexName()
+		columns, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		p.setPrimaryKey(table, name, columns)
+	case p.accept("UNIQUE"):
+		if !p.accept("KEY") {
+			p.accept("INDEX")
+		}
+		indexName, method := p.indexName()
+		if indexName != "" {
+			name = indexName
+		}
+		columns, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		p.addIndex(table, name, columns, true, p.indexMethod(method))
+	case p.accept("FOREIGN", "KEY"):
+		if indexName, _ := p.indexName(); name == "" {
+			name = indexName
+		}
+		columns, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		if err := p.references(table, name, columns); err != nil {
+			return err
+		}
+	case p.accept("CHECK"):
+		p.addCheck(table, name)
+	case p.peek().keyword("FULLTEXT"), p.peek().keyword("SPATIAL"):
+		method := strings.ToUpper(p.next().text)
+		p.accept("KEY")
+		p.accept("INDEX")
+		name, _ = p.indexName()
+		columns, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		p.addIndex(table, name, columns, false, method)
+	case p.accept("KEY"), p.accept("INDEX"):
+		name, method := p.indexName()
+		columns, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		p.addIndex(table, name, columns, false, p.indexMethod(method))
+	}
+	// EXCLUDE 和索引选项
+	p.skipUntilComma()
+	return nil
+}
+
+// indexName 读取 ( 之前的索引名，以及 USING 指定的索引方法
+func (p *ddlParser) indexName() (name, method string) {
+	if tok := p.peek(); tok.name() && !tok.keyword("USING") {
+		name = p.next().text
+	}
+	if p.accept("USING") {
+		method = p.next().text
+	}
+	return name, method
+}
+
+// indexMethod 返回索引方法：没有指定时为 B 树；MySQL 中为大写，PostgreSQL 中为小写，与 GetTableInfo 一致
+// MySQL 的 USING 也可以写在索引列之后
+func (p *ddlParser) indexMethod(method string) string {
+	if method == "" && p.peek().keyword("USING") {
+		p.pos++
+		method = p.next().text
+	}
+	if method == "" {
+		method = "btree"
+	}
+	if p.dialect == "mysql" {
+		return strings.ToUpper(method)
+	}
+	return strings.ToLower(method)
+}
+
+// addCheck 读取 CHECK 之后的表达式，添加检查约束
+func (p *ddlParser) addCheck(table *ddlTable, name string) {
+	if !p.peek().is("(") {
+		return
+	}
+	expr, names := p.groupText()
+	table.checks = append(table.checks, &ddlCheck{name: name, expr: expr, names: names})
+}
+
+// indexColumns 读取 (a, b(10) DESC, (lower(c))) 中的列名，表达式被忽略
+func (p *ddlParser) indexColumns() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var columns []string
+	for !p.acceptSymbol(")") {
+		if p.pos >= len(p.tokens) {
+			return nil, fmt.Errorf("索引列没有结束")
+		}
+		tok := p.peek()
+		if tok.name() {
+			p.pos++
+			// MySQL 中 name(10) 是前缀索引，PostgreSQL 中是函数调用
+			if next := p.peek(); !next.is("::") && (!next.is("(") || p.dialect == "mysql") {
+				columns = append(columns, tok.text)
+			}
+		}
+		p.skipUntilComma()
+		p.acceptSymbol(",")
+	}
+	return columns, nil
+}
+
+// references 解析 REFERENCES table (columns) 及其后的动作
+func (p *ddlParser) references(table *ddlTable, name string, columns []string) error {
+	if !p.accept("REFERENCES") {
+		return fmt.Errorf("外键缺少 REFERENCES")
+	}
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	var refColumns []string
+	if p.peek().is("(") {
+		if refColumns, err = p.indexColumns(); err != nil {
+			return err
+		}
+	}
+	// 没有指定动作时为 NO ACTION
+	onUpdate, onDelete := "NO ACTION", "NO ACTION"
+actions:
+	for {
+		switch {
+		case p.accept("MATCH"):
+			p.pos++
+		case p.accept("ON", "UPDATE"):
+			onUpdate = p.referentialAction()
+		case p.accept("ON", "DELETE"):
+			onDelete = p.referentialAction()
+		default:
+			break actions
+		}
+	}
+	if name == "" {
+		table.fkSeq++
+		if p.dialect == "mysql" {
+			name = fmt.Sprintf("%s_ibfk_%d", table.info.TableName, table.fkSeq)
+		} else {
+			name = fmt.Sprintf("%s_%s_fkey", p.relName(table), strings.Join(columns, "_"))
+		}
+	}
+	refTable := parts[len(parts)-1]
+	if p.dialect == "postgres" {
+		if key := p.tableKey(parts); !strings.HasPrefix(key, p.schemaOf(table)+".") {
+			refTable = key
+		}
+	} else if len(parts) > 1 {
+		refTable = parts[len(parts)-2] + "." + refTable
+	}
+	table.fks = append(table.fks, define.ForeignKeyInfo{
+		Name:       name,
+		Columns:    columns,
+		RefTable:   refTable,
+		RefColumns: refColumns,
+		OnUpdate:   onUpdate,
+		OnDelete:   onDelete,
+	})
+	return nil
+}
+
+// referentialAction 读取 ON UPDATE 或 ON DELETE 之后的动作，如 CASCADE、SET NULL
+func (p *ddlParser) referentialAction() string {
+	var action string
+	switch {
+	case p.accept("NO", "ACTION"):
+		action = "NO ACTION"
+	case p.accept("SET", "NULL"):
+		action = "SET NULL"
+	case p.accept("SET", "DEFAULT"):
+		action = "SET DEFAULT"
+	default:
+		action = strings.ToUpper(p.next().text)
+	}
+	if p.peek().is("(") {
+		// PostgreSQL 的 SET NULL (columns)
+		p.skipGroup()
+	}
+	return action
+}
+
+// schemaOf 返回 PostgreSQL 表的 schema
+func (p *ddlParser) schemaOf(table *ddlTable) string {
+	return table.info.TableName[:strings.LastIndexByte(table.info.TableName, '.')]
+}
+
+// relName 返回去掉 schema 的表名
+func (p *ddlParser) relName(table *ddlTable) string {
+	name := table.info.TableName
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+// setPrimaryKey 设置主键，主键列不可为空
+func (p *ddlParser) setPrimaryKey(table *ddlTable, name string, columns []string) {
+	table.primary = columns
+	switch {
+	case p.dialect == "mysql":
+		name = "PRIMARY"
+	case name == "":
+		name = p.relName(table) + "_pkey"
+	}
+	table.indexes = append(table.indexes, define.IndexInfo{Name: name, Columns: columns, Unique: true, Primary: true, Method: p.indexMethod("")})
+}
+
+// addIndex 添加索引，未命名时按数据库的规则生成名称
+func (p *ddlParser) addIndex(table *ddlTable, name string, columns []string, unique bool, method string) {
+	if len(columns) == 0 {
+		return
+	}
+	if name == "" {
+		if p.dialect == "mysql" {
+			name = columns[0]
+		} else if unique {
+			name = fmt.Sprintf("%s_%s_key", p.relName(table), strings.Join(columns, "_"))
+		} else {
+			name = fmt.Sprintf("%s_%s_idx", p.relName(table), strings.Join(columns, "_"))
+		}
+	}
+	table.indexes = append(table.indexes, define.IndexInfo{Name: name, Columns: columns, Unique: unique, Method: method})
+}
+
+// column 解析列定义
+func (p *ddlParser) column(table *ddlTable) error {
+	p.accept("COLUMN")
+	tok := p.next()
+	if !tok.name() {
+		return fmt.Errorf("缺少列名，遇到 %q", tok.text)
+	}
+	col := define.ColumnInfo{Name: tok.text, IsNullable: true}
+	if err := p.columnType(&col); err != nil {
+		return fmt.Errorf("列 %s: %v", col.Name, err)
+	}
+	if col.IsAutoIncrement && p.dialect == "postgres" {
+		// serial 列
+		col.IsNullable = false
+		col.DefaultValue = fmt.Sprintf("nextval('%s_%s_seq'::regclass)", p.relName(table), col.Name)
+	}
+	table.info.Columns = append(table.info.Columns, col)
+	return p.columnConstraints(table, &table.info.Columns[len(table.info.Columns)-1])
+}
+
+// columnType 读取列类型，设置 TypeName、ColumnType、DataType、长度、精度和枚举值
+func (p *ddlParser) columnType(col *define.ColumnInfo) error {
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	words := []string{strings.ToLower(parts[len(parts)-1])}
+	var args []sqlToken
+	var unsigned, array bool
+	for {
+		switch tok := p.peek(); {
+		case tok.is("("):
+			p.pos++
+			for !p.acceptSymbol(")") {
+				if p.pos >= len(p.tokens) {
+					return fmt.Errorf("类型参数没有结束")
+				}
+				if tok := p.next(); !tok.is(",") {
+					args = append(args, tok)
+				}
+			}
+			continue
+		case tok.is("["):
+			p.pos++
+			p.acceptSymbol("]")
+			array = true
+			continue
+		case tok.keyword("UNSIGNED"), tok.keyword("ZEROFILL"):
+			p.pos++
+			unsigned = unsigned || tok.keyword("UNSIGNED")
+			continue
+		case tok.keyword("SIGNED"):
+			p.pos++
+			continue
+		case tok.keyword("VARYING"), tok.keyword("PRECISION"), tok.keyword("ZONE"),
+			tok.keyword("WITH") && len(p.tokens) > p.pos+1 && p.tokens[p.pos+1].keyword("TIME"),
+			tok.keyword("WITHOUT"), tok.keyword("TIME") && len(words) > 1:
+			p.pos++
+			words = append(words, strings.ToLower(tok.text))
+			continue
+		}
+		break
+	}
+	if p.dialect == "mysql" {
+		p.mysqlType(col, strings.Join(words, " "), args, unsigned)
+		col.DataType = mysql.SQLDataType(col.TypeName)
+	} else {
+		p.postgresType(col, strings.Join(words, " "), args, array)
+		col.DataType = postgres.SQLDataType(col.TypeName)
+	}
+	return nil
+}
+
+// mysqlTypeAliases MySQL 类型的同义词，值为 information_schema 中的类型名
+var mysqlTypeAliases = map[string]string{
+	"integer": "int", "int4": "int", "int1": "tinyint", "int2": "smallint", "int3": "mediumint", "int8": "bigint",
+	"middleint": "mediumint", "dec": "decimal", "numeric": "decimal", "fixed": "decimal",
+	"real": "double", "double precision": "double", "float8": "double", "float4": "float",
+	"character": "char", "nchar": "char", "character varying": "varchar", "nvarchar": "varchar",
+}
+
+// mysqlType 按 information_schema.columns 的格式设置 MySQL 列类型
+func (p *ddlParser) mysqlType(col *define.ColumnInfo, typeName string, args []sqlToken, unsigned bool) {
+	if alias, ok := mysqlTypeAliases[typeName]; ok {
+		typeName = alias
+	}
+	var params []string
+	switch typeName {
+	case "bool", "boolean":
+		typeName, params = "tinyint", []string{"1"}
+	case "serial":
+		typeName, unsigned = "bigint", true
+		col.IsAutoIncrement = true
+		col.IsNullable = false
+	case "enum", "set":
+		for _, arg := range args {
+			params = append(params, "'"+strings.ReplaceAll(arg.text, "'", "''")+"'")
+			if typeName == "enum" {
+				col.EnumValues = append(col.EnumValues, arg.text)
+			}
+		}
+	default:
+		for _, arg := range args {
+			params = append(params, arg.text)
+		}
+	}
+	switch typeName {
+	case "decimal":
+		if len(params) == 0 {
+			params = []string{"10"}
+		}
+		if len(params) == 1 {
+			params = append(params, "0")
+		}
+		col.Precision, _ = strconv.Atoi(params[0])
+		col.Scale, _ = strconv.Atoi(params[1])
+	case "char", "varchar", "binary", "varbinary":
+		col.Length = 1
+		if len(params) > 0 {
+			col.Length, _ = strconv.ParseInt(params[0], 10, 64)
+		} else {
+			params = []string{"1"}
+		}
+	}
+	col.TypeName = typeName
+	col.ColumnType = typeName
+	if len(params) > 0 {
+		col.ColumnType += "(" + strings.Join(params, ",") + ")"
+	}
+	if unsigned {
+		col.ColumnType += " unsigned"
+	}
+}
+
+// postgresTypes PostgreSQL 类型的写法对应的 pg_type 类型名和 format_type 的结果
+var postgresTypes = map[string][2]string{
+	"smallint": {"int2", "smallint"}, "int2": {"int2", "smallint"},
+	"integer": {"int4", "integer"}, "int": {"int4", "integer"}, "int4": {"int4", "integer"},
+	"bigint": {"int8", "bigint"}, "int8": {"int8", "bigint"},
+	"smallserial": {"int2", "smallint"}, "serial2": {"int2", "smallint"},
+	"serial": {"int4", "integer"}, "serial4": {"int4", "integer"},
+	"bigserial": {"int8", "bigint"}, "serial8": {"int8", "bigint"},
+	"real": {"float4", "real"}, "float4": {"float4", "real"},
+	"double precision": {"float8", "double precision"}, "float8": {"float8", "double precision"}, "float": {"float8", "double precision"},
+	"numeric": {"numeric", "numeric"}, "decimal": {"numeric", "numeric"},
+	"boolean": {"bool", "boolean"}, "bool": {"bool", "boolean"},
+	"character varying": {"varchar", "character varying"}, "varchar": {"varchar", "character varying"},
+	"character": {"bpchar", "character"}, "char": {"bpchar", "character"}, "bpchar": {"bpchar", "character"},
+	"timestamp": {"timestamp", "timestamp without time zone"}, "timestamp without time zone": {"timestamp", "timestamp without time zone"},
+	"timestamptz": {"timestamptz", "timestamp with time zone"}, "timestamp with time zone": {"timestamptz", "timestamp with time zone"},
+	"time": {"time", "time without time zone"}, "time without time zone": {"time", "time without time zone"},
+	"timetz": {"timetz", "time with time zone"}, "time with time zone": {"timetz", "time with time zone"},
+}
+
+// postgresType 按 pg_type 和 format_type 的格式设置 PostgreSQL 列类型
+func (p *ddlParser) postgresType(col *define.ColumnInfo, typeName string, args []sqlToken, array bool) {
+	if strings.HasSuffix(typeName, "serial") || strings.HasPrefix(typeName, "serial") {
+		col.IsAutoIncrement = true
+	}
+	var params []string
+	for _, arg := range args {
+		params = append(params, arg.text)
+	}
+	if typeName == "float" && len(params) > 0 {
+		if n, _ := strconv.Atoi(params[0]); n <= 24 {
+			typeName = "real"
+		}
+		params = nil
+	}
+
+	names, known := postgresTypes[typeName]
+	if !known {
+		// 其他内置类型和自定义类型，如 text、jsonb 和枚举类型
+		names = [2]string{typeName, typeName}
+	}
+	col.TypeName, col.ColumnType = names[0], names[1]
+	switch col.TypeName {
+	case "varchar", "bpchar":
+		if len(params) > 0 {
+			col.Length, _ = strconv.ParseInt(params[0], 10, 64)
+		} else if col.TypeName == "bpchar" {
+			col.Length, params = 1, []string{"1"}
+		}
+	case "numeric":
+		if len(params) > 0 {
+			col.Precision, _ = strconv.Atoi(params[0])
+		}
+		if len(params) > 1 {
+			col.Scale, _ = strconv.Atoi(params[1])
+		} else if len(params) == 1 {
+			params = append(params, "0")
+		}
+	}
+	if len(params) > 0 {
+		modifier := "(" + strings.Join(params, ",") + ")"
+		if space := strings.IndexByte(col.ColumnType, ' '); space > 0 && strings.HasPrefix(col.ColumnType, "time") {
+			// timestamp(3) with time zone
+			col.ColumnType = col.ColumnType[:space] + modifier + col.ColumnType[space:]
+		} else {
+			col.ColumnType += modifier
+		}
+	}
+	if array {
+		col.TypeName = "_" + col.TypeName
+		col.ColumnType += "[]"
+		col.EnumValues = nil
+	}
+}
+
+// columnConstraints 解析列类型之后的属性和约束
+func (p *ddlParser) columnConstraints(table *ddlTable, col *define.ColumnInfo) error {
+	var constraintName string
+	for p.pos < len(p.tokens) {
+		tok := p.peek()
+		if tok.is(",") || tok.is(")") {
+			return nil
+		}
+		switch {
+		case p.accept("NOT", "NULL"):
+			col.IsNullable = false
+		case p.accept("NULL"):
+			col.IsNullable = true
+		case p.accept("CONSTRAINT"):
+			constraintName = p.next().text
+			continue
+		case p.accept("PRIMARY", "KEY"):
+			p.setPrimaryKey(table, constraintName, []string{col.Name})
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			p.addIndex(table, constraintName, []string{col.Name}, true, p.indexMethod(""))
+		case p.accept("KEY"):
+			// MySQL 中列后的 KEY 表示主键
+			p.setPrimaryKey(table, "", []string{col.Name})
+		case p.accept("AUTO_INCREMENT"), p.accept("AUTOINCREMENT"):
+			col.IsAutoIncrement = true
+		case p.accept("DEFAULT"):
+			col.DefaultValue = p.defaultValue()
+		case p.accept("ON", "UPDATE"):
+			p.expression()
+		case p.accept("COMMENT"):
+			if tok := p.next(); tok.kind == tokenString {
+				col.Comment = tok.text
+			}
+		case p.accept("COLLATE"), p.accept("CHARSET"), p.accept("CHARACTER", "SET"):
+			p.pos++
+		case p.accept("GENERATED"):
+			if p.accept("ALWAYS", "AS", "IDENTITY") || p.accept("BY", "DEFAULT", "AS", "IDENTITY") {
+				col.IsAutoIncrement = true
+				col.IsNullable = false
+				if p.peek().is("(") {
+					p.skipGroup()
+				}
+				continue
+			}
+			p.accept("ALWAYS")
+			p.accept("AS")
+			if p.peek().is("(") {
+				p.skipGroup()
+			}
+		case p.accept("AS"):
+			// MySQL 生成列
+			if p.peek().is("(") {
+				p.skipGroup()
+			}
+		case tok.keyword("REFERENCES") && p.dialect == "mysql":
+			// MySQL 忽略列定义中的 REFERENCES
+			p.pos++
+			p.skipReferences()
+		case tok.keyword("REFERENCES"):
+			if err := p.references(table, constraintName, []string{col.Name}); err != nil {
+				return err
+			}
+		case p.accept("CHECK"):
+			p.addCheck(table, constraintName)
+		default:
+			if tok.is("(") {
+				p.skipGroup()
+			} else {
+				p.pos++
+			}
+		}
+		constraintName = ""
+	}
+	return nil
+}
+
+// skipReferences 跳过 REFERENCES 之后的表名和列
+func (p *ddlParser) skipReferences() {
+	if _, err := p.qualifiedName(); err != nil {
+		return
+	}
+	if p.peek().is("(") {
+		p.skipGroup()
+	}
+}
+
+// columnStop 列属性的关键字，默认值表达式在这些关键字前结束
+var columnStop = map[string]bool{
+	"NOT": true, "NULL": true, "PRIMARY": true, "UNIQUE": true, "KEY": true, "AUTO_INCREMENT": true, "COMMENT": true,
+	"ON": true, "REFERENCES": true, "CHECK": true, "CONSTRAINT": true, "COLLATE": true, "GENERATED": true,
+	"CHARACTER": true, "CHARSET": true, "VISIBLE": true, "INVISIBLE": true,
+}
+
+// defaultValue 读取默认值；MySQL 中字符串默认值与 information_schema 一致，不带引号
+func (p *ddlParser) defaultValue() string {
+	start := p.pos
+	expr := p.expression()
+	if p.dialect == "mysql" && p.pos == start+1 && p.tokens[start].kind == tokenString {
+		return p.tokens[start].text
+	}
+	return expr
+}
+
+// expression 读取到下一个列属性、, 或 ) 为止的表达式，返回其文本
+func (p *ddlParser) expression() string {
+	w := &exprWriter{dialect: p.dialect}
+	depth := 0
+	for p.pos < len(p.tokens) {
+		tok := p.peek()
+		// 'a'::character varying 中的 character 是类型
+		keyword := tok.kind == tokenIdent && columnStop[strings.ToUpper(tok.text)] && w.text.Len() > 0 && !w.prev.is("::")
+		if depth == 0 && (tok.is(",") || tok.is(")") || keyword) {
+			break
+		}
+		p.pos++
+		switch {
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+		}
+		w.write(tok)
+	}
+	return w.text.String()
+}
+
+// groupText 读取从当前的 ( 开始到对应 ) 的表达式，返回其文本和其中可能是列名的标识符
+func (p *ddlParser) groupText() (string, []string) {
+	w := &exprWriter{dialect: p.dialect}
+	var names []string
+	depth := 0
+	for p.pos < len(p.tokens) {
+		tok := p.next()
+		w.write(tok)
+		switch {
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+			if depth <= 0 {
+				return w.text.String(), names
+			}
+		case tok.name() && !p.peek().is("(") && !p.peek().is("."):
+			names = append(names, tok.text)
+		}
+	}
+	return w.text.String(), names
+}
+
+// exprWriter 把词法单元拼接为表达式文本：二元运算符两侧加空格，括号、点号、:: 和一元运算符两侧不加
+type exprWriter struct {
+	dialect string
+	text    strings.Builder
+	prev    sqlToken
+	unary   bool // prev 是否为一元运算符
+}
+
+// operator 是否为运算符
+func (t sqlToken) operator() bool {
+	return t.kind == tokenSymbol && !t.is("(") && !t.is(")") && !t.is("[") && !t.is("]") && !t.is(",") && !t.is(".") && !t.is("::")
+}
+
+// exprKeywords 表达式中的关键字，之后的 ( 不是函数调用
+var exprKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IN": true, "IS": true, "LIKE": true, "ILIKE": true, "BETWEEN": true,
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "ANY": true, "ALL": true, "SOME": true, "EXISTS": true,
+}
+
+// write 追加一个词法单元
+func (w *exprWriter) write(tok sqlToken) {
+	var text string
+	switch {
+	case tok.kind == tokenString:
+		text = "'" + strings.ReplaceAll(tok.text, "'", "''") + "'"
+	case tok.kind == tokenQuoted && w.dialect == "mysql":
+		text = "`" + tok.text + "`"
+	case tok.kind == tokenQuoted:
+		text = `"` + tok.text + `"`
+	default:
+		text = tok.text
+	}
+
+	first := w.text.Len() == 0
+	unary := tok.operator() && (first || w.prev.operator() || w.prev.is("(") || w.prev.is("[") || w.prev.is(","))
+	attach := first || w.unary || tok.is(")") || tok.is("]") || tok.is(".") || tok.is("::") ||
+		w.prev.is("(") || w.prev.is("[") || w.prev.is(".") || w.prev.is("::") || w.prev.is(",") ||
+		(tok.is("(") || tok.is("[")) && w.prev.name() && !(w.prev.kind == tokenIdent && exprKeywords[strings.ToUpper(w.prev.text)])
+	switch {
+	case tok.is(","):
+		w.text.WriteString(", ")
+	case attach:
+		w.text.WriteString(text)
+	default:
+		w.text.WriteString(" " + text)
+	}
+	w.prev, w.unary = tok, unary
+}
+
+// createType 解析 PostgreSQL 的 CREATE TYPE name AS ENUM (...)
+func (p *ddlParser) createType() error {
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.accept("AS", "ENUM") {
+		return nil
+	}
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	var values []string
+	for !p.acceptSymbol(")") {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenString:
+			values = append(values, tok.text)
+		case tok.is(","):
+		default:
+			return fmt.Errorf("枚举类型 %s 的值只能是字符串，遇到 %q", strings.Join(parts, "."), tok.text)
+		}
+	}
+	p.enums[strings.ToLower(parts[len(parts)-1])] = values
+	return nil
+}
+
+// createIndex 解析 CREATE [UNIQUE] INDEX
+func (p *ddlParser) createIndex(unique bool) error {
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+	var name string
+	if !p.peek().keyword("ON") {
+		parts, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		name = parts[len(parts)-1]
+	}
+	if !p.accept("ON") {
+		return fmt.Errorf("索引缺少 ON")
+	}
+	p.accept("ONLY")
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	table := p.table(parts)
+	if table == nil {
+		return nil
+	}
+	_, method := p.indexName()
+	columns, err := p.indexColumns()
+	if err != nil {
+		return err
+	}
+	p.addIndex(table, name, columns, unique, p.indexMethod(method))
+	return nil
+}
+
+// alterTable 解析 ALTER TABLE 中的添加约束、索引和列，以及修改默认值、可空性和自增
+func (p *ddlParser) alterTable() error {
+	p.accept("IF", "EXISTS")
+	p.accept("ONLY")
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	table := p.table(parts)
+	if table == nil {
+		return nil
+	}
+	for p.pos < len(p.tokens) {
+		switch {
+		case p.accept("ADD"):
+			tok := p.peek()
+			if tok.keyword("COLUMN") || !tok.keyword("CONSTRAINT") && !tok.keyword("PRIMARY") && !tok.keyword("UNIQUE") &&
+				!tok.keyword("FOREIGN") && !tok.keyword("KEY") && !tok.keyword("INDEX") && !tok.keyword("CHECK") &&
+				!tok.keyword("FULLTEXT") && !tok.keyword("SPATIAL") {
+				p.accept("COLUMN")
+				p.accept("IF", "NOT", "EXISTS")
+				err = p.column(table)
+			} else {
+				err = p.constraint(table)
+			}
+			if err != nil {
+				return fmt.Errorf("表 %s: %v", table.info.TableName, err)
+			}
+		case p.accept("ALTER"):
+			p.accept("COLUMN")
+			col := table.column(p.next().text)
+			switch {
+			case col == nil:
+			case p.accept("SET", "DEFAULT"):
+				col.DefaultValue = p.defaultValue()
+			case p.accept("DROP", "DEFAULT"):
+				col.DefaultValue = ""
+			case p.accept("SET", "NOT", "NULL"):
+				col.IsNullable = false
+			case p.accept("DROP", "NOT", "NULL"):
+				col.IsNullable = true
+			case p.accept("ADD", "GENERATED"):
+				col.IsAutoIncrement = true
+			}
+		case p.accept("ATTACH", "PARTITION"):
+			parts, err := p.qualifiedName()
+			if err != nil {
+				return err
+			}
+			table.partitions = append(table.partitions, p.tableKey(parts))
+		}
+		p.skipUntilComma()
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return nil
+}
+
+// commentOn 解析 PostgreSQL 的 COMMENT ON TABLE/COLUMN
+func (p *ddlParser) commentOn() error {
+	isTable := p.accept("TABLE")
+	if !isTable && !p.accept("COLUMN") {
+		return nil
+	}
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.accept("IS") {
+		return nil
+	}
+	tok := p.next()
+	if tok.kind != tokenString {
+		return nil
+	}
+	if isTable {
+		if table := p.table(parts); table != nil {
+			table.info.TableComment = tok.text
+		}
+		return nil
+	}
+	if len(parts) < 2 {
+		return nil
+	}
+	if table := p.table(parts[:len(parts)-1]); table != nil {
+		if col := table.column(parts[len(parts)-1]); col != nil {
+			col.Comment = tok.text
+		}
+	}
+	return nil
+}
+
+// snapshot 整理解析结果：主键列按列顺序排列且不可为空，索引和外键按名称排序，与 GetTableInfo 一致
+func (p *ddlParser) snapshot() *Snapshot {
+	snapshot := &Snapshot{Dialect: p.dialect}
+	for _, key := range p.order {
+		table := p.tables[key]
+		info := table.info
+		primary := make(map[string]bool)
+		for _, column := range table.primary {
+			primary[strings.ToLower(column)] = true
+		}
+		for i := range info.Columns {
+			col := &info.Columns[i]
+			if primary[strings.ToLower(col.Name)] {
+				col.IsPrimaryKey = true
+				col.IsNullable = false
+				info.PrimaryKeys = append(info.PrimaryKeys, col.Name)
+			}
+			switch col.TypeName {
+			case "decimal", "numeric":
+				info.HasDecimal = true
+			case "uuid":
+				info.HasUUID = true
+			case "inet":
+				info.HasIP = true
+			case "date", "datetime", "timestamp":
+				// 与 MySQL 的 GetTableInfo 一致，PostgreSQL 不设置
+				info.HasTime = info.HasTime || p.dialect == "mysql"
+			}
+			if p.dialect == "postgres" {
+				col.EnumValues = p.enums[col.TypeName]
+				// 与 GetTableInfo 一致，默认值为序列的列视为自增
+				col.IsAutoIncrement = col.IsAutoIncrement || strings.Contains(col.DefaultValue, "nextval(")
+			}
+		}
+		info.Indexes = table.indexes
+		sort.SliceStable(info.Indexes, func(i, j int) bool {
+			if p.dialect == "mysql" {
+				return strings.ToLower(info.Indexes[i].Name) < strings.ToLower(info.Indexes[j].Name)
+			}
+			return info.Indexes[i].Name < info.Indexes[j].Name
+		})
+		info.ForeignKeys = table.fks
+		sort.SliceStable(info.ForeignKeys, func(i, j int) bool { return info.ForeignKeys[i].Name < info.ForeignKeys[j].Name })
+		info.CheckConstraints = p.checkConstraints(table)
+		if info.Partition != nil && len(table.partitions) > 0 {
+			// 与 GetTableInfo 一致，分区子表按 schema 和表名排序，与父表在同一 schema 时省略 schema
+			sort.Strings(table.partitions)
+			for _, key := range table.partitions {
+				info.Partition.Partitions = append(info.Partition.Partitions, strings.TrimPrefix(key, p.schemaOf(table)+"."))
+			}
+		}
+		snapshot.Tables = append(snapshot.Tables, info)
+	}
+	return snapshot
+}
+
+// checkConstraints 为未命名的检查约束按数据库的规则生成名称，按名称排序
+// MySQL 中为 <表>_chk_<序号>；PostgreSQL 中表达式只引用一列时为 <表>_<列>_check，否则为 <表>_check，重名时加上序号
+func (p *ddlParser) checkConstraints(table *ddlTable) []define.CheckConstraintInfo {
+	used := make(map[string]bool)
+	for _, check := range table.checks {
+		used[check.name] = true
+	}
+	var checks []define.CheckConstraintInfo
+	seq := 0
+	for _, check := range table.checks {
+		name := check.name
+		switch {
+		case name != "":
+		case p.dialect == "mysql":
+			seq++
+			name = fmt.Sprintf("%s_chk_%d", table.info.TableName, seq)
+		default:
+			base := p.relName(table) + "_check"
+			if column := checkColumn(table, check.names); column != "" {
+				base = p.relName(table) + "_" + column + "_check"
+			}
+			name = base
+			for n := 1; used[name]; n++ {
+				name = base + strconv.Itoa(n)
+			}
+			used[name] = true
+		}
+		checks = append(checks, define.CheckConstraintInfo{Name: name, Expression: check.expr})
+	}
+	sort.SliceStable(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	return checks
+}
+
+// checkColumn 返回检查约束的表达式引用的列，引用多列或没有引用列时返回空
+func checkColumn(table *ddlTable, names []string) string {
+	var column string
+	for _, name := range names {
+		col := table.column(name)
+		switch {
+		case col == nil, col.Name == column:
+		case column == "":
+			column = col.Name
+		default:
+			return ""
+		}
+	}
+	return column
+}
@@ -0,0 +1,243 @@
+package gomen
+
+import (
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mysqlDump = `
+-- MySQL dump 10.13
+/*!40101 SET NAMES utf8mb4 */;
+DROP TABLE IF EXISTS ` + "`users`" + `;
+CREATE TABLE ` + "`users`" + ` (
+  ` + "`id`" + ` bigint unsigned NOT NULL AUTO_INCREMENT,
+  ` + "`email`" + ` varchar(128) NOT NULL COMMENT 'login, unique',
+  ` + "`status`" + ` enum('new','it''s done') NOT NULL DEFAULT 'new',
+  ` + "`balance`" + ` decimal(10,2) DEFAULT NULL,
+  ` + "`active`" + ` boolean NOT NULL DEFAULT TRUE,
+  ` + "`created_at`" + ` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  ` + "`age`" + ` int DEFAULT -1 CHECK (` + "`age`" + ` >= -1),
+  PRIMARY KEY (` + "`id`" + `),
+  UNIQUE KEY ` + "`uk_email`" + ` (` + "`email`" + `(64)),
+  KEY ` + "`idx_lower`" + ` ((lower(` + "`email`" + `))),
+  KEY ` + "`idx_created`" + ` (` + "`created_at`" + `) USING HASH,
+  FULLTEXT KEY ` + "`ft_email`" + ` (` + "`email`" + `),
+  CONSTRAINT ` + "`chk_balance`" + ` CHECK ((` + "`balance`" + ` >= 0) and (` + "`balance`" + ` <> 1.5)),
+  CHECK (` + "`age`" + ` < 200)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='users; accounts';
+
+# orders of users
+CREATE TABLE IF NOT EXISTS orders (
+  tenant_id int NOT NULL,
+  id int NOT NULL,
+  user_id bigint unsigned REFERENCES ignored (id),
+  CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE SET NULL,
+  FOREIGN KEY (tenant_id) REFERENCES shop.tenants (id),
+  PRIMARY KEY (id, tenant_id)
+);
+CREATE INDEX idx_user ON orders (user_id);
+ALTER TABLE orders ADD COLUMN note text, ADD UNIQUE INDEX uk_note (note(32));
+
+CREATE TABLE ` + "`events`" + ` (
+  ` + "`id`" + ` int NOT NULL,
+  ` + "`created_at`" + ` date NOT NULL
+) ENGINE=MyISAM
+/*!50100 PARTITION BY RANGE (year(` + "`created_at`" + `))
+(PARTITION p2023 VALUES LESS THAN (2024) ENGINE = MyISAM,
+ PARTITION pmax VALUES LESS THAN MAXVALUE ENGINE = MyISAM) */;
+CREATE TABLE logs (id int) PARTITION BY LINEAR KEY ALGORITHM=2 (id) PARTITIONS 3;
+`
+
+func TestParseDDLMySQL(t *testing.T) {
+	snapshot, err := ParseDDL("mysql", mysqlDump)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Tables, 4)
+
+	users := snapshot.Tables[0]
+	assert.Equal(t, "users", users.TableName)
+	assert.Equal(t, "users; accounts", users.TableComment)
+	assert.Equal(t, "InnoDB", users.Engine)
+	assert.Equal(t, "utf8mb4_unicode_ci", users.Collation)
+	assert.Nil(t, users.Partition)
+	assert.Equal(t, []string{"id"}, users.PrimaryKeys)
+	assert.True(t, users.HasDecimal)
+	assert.True(t, users.HasTime)
+	assert.Equal(t, define.ColumnInfo{Name: "id", TypeName: "bigint", ColumnType: "bigint unsigned", DataType: "int64",
+		IsPrimaryKey: true, IsAutoIncrement: true}, users.Columns[0])
+	assert.Equal(t, define.ColumnInfo{Name: "email", TypeName: "varchar", ColumnType: "varchar(128)", DataType: "string",
+		Length: 128, Comment: "login, unique"}, users.Columns[1])
+	assert.Equal(t, define.ColumnInfo{Name: "status", TypeName: "enum", ColumnType: "enum('new','it''s done')", DataType: "string",
+		DefaultValue: "new", EnumValues: []string{"new", "it's done"}}, users.Columns[2])
+	assert.Equal(t, define.ColumnInfo{Name: "balance", TypeName: "decimal", ColumnType: "decimal(10,2)", DataType: "float64",
+		Precision: 10, Scale: 2, IsNullable: true, DefaultValue: "NULL"}, users.Columns[3])
+	assert.Equal(t, "tinyint(1)", users.Columns[4].ColumnType)
+	assert.Equal(t, "TRUE", users.Columns[4].DefaultValue)
+	assert.Equal(t, "CURRENT_TIMESTAMP", users.Columns[5].DefaultValue)
+	assert.Equal(t, "-1", users.Columns[6].DefaultValue)
+	assert.Equal(t, []define.IndexInfo{
+		{Name: "ft_email", Columns: []string{"email"}, Method: "FULLTEXT"},
+		{Name: "idx_created", Columns: []string{"created_at"}, Method: "HASH"},
+		{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true, Method: "BTREE"},
+		{Name: "uk_email", Columns: []string{"email"}, Unique: true, Method: "BTREE"},
+	}, users.Indexes, "表达式索引没有列，被忽略")
+	assert.Equal(t, []define.CheckConstraintInfo{
+		{Name: "chk_balance", Expression: "((`balance` >= 0) and (`balance` <> 1.5))"},
+		{Name: "users_chk_1", Expression: "(`age` >= -1)"},
+		{Name: "users_chk_2", Expression: "(`age` < 200)"},
+	}, users.CheckConstraints)
+
+	orders := snapshot.Tables[1]
+	assert.Equal(t, []string{"tenant_id", "id"}, orders.PrimaryKeys, "主键按列的顺序排列")
+	assert.Equal(t, []string{"tenant_id", "id", "user_id", "note"}, columnNames(orders))
+	assert.False(t, orders.Columns[1].IsNullable)
+	assert.Equal(t, []define.IndexInfo{
+		{Name: "idx_user", Columns: []string{"user_id"}, Method: "BTREE"},
+		{Name: "PRIMARY", Columns: []string{"id", "tenant_id"}, Unique: true, Primary: true, Method: "BTREE"},
+		{Name: "uk_note", Columns: []string{"note"}, Unique: true, Method: "BTREE"},
+	}, orders.Indexes)
+	assert.Equal(t, []define.ForeignKeyInfo{
+		{Name: "fk_orders_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"},
+			OnUpdate: "SET NULL", OnDelete: "CASCADE"},
+		{Name: "orders_ibfk_1", Columns: []string{"tenant_id"}, RefTable: "shop.tenants", RefColumns: []string{"id"},
+			OnUpdate: "NO ACTION", OnDelete: "NO ACTION"},
+	}, orders.ForeignKeys)
+	assert.Empty(t, orders.CheckConstraints)
+
+	events := snapshot.Tables[2]
+	assert.Equal(t, "MyISAM", events.Engine)
+	assert.Equal(t, &define.PartitionInfo{Method: "RANGE", Expression: "year(`created_at`)", Partitions: []string{"p2023", "pmax"}}, events.Partition)
+	logs := snapshot.Tables[3]
+	assert.Equal(t, &define.PartitionInfo{Method: "LINEAR KEY", Expression: "id", Partitions: []string{"p0", "p1", "p2"}}, logs.Partition)
+}
+
+const postgresDump = `
+SET statement_timeout = 0;
+CREATE TYPE public.mood AS ENUM (
+    'happy',
+    'sad'
+);
+CREATE FUNCTION public.touch() RETURNS trigger AS $body$
+BEGIN
+  NEW.updated_at = now(); -- ; inside a function
+  RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql;
+
+CREATE TABLE public.users (
+    id bigint NOT NULL,
+    name character varying(64) DEFAULT 'anon'::character varying NOT NULL,
+    mood public.mood,
+    tags text[],
+    price numeric(12,3) CHECK (price >= 0),
+    created_at timestamp(3) with time zone DEFAULT now() NOT NULL,
+    "Group" integer GENERATED BY DEFAULT AS IDENTITY,
+    CHECK (price < 1000 OR mood = 'happy'::public.mood),
+    CHECK ("Group" <> -1)
+);
+COMMENT ON TABLE public.users IS 'all users';
+COMMENT ON COLUMN public.users.name IS 'display name';
+CREATE SEQUENCE public.users_id_seq START WITH 1;
+ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+CREATE UNIQUE INDEX users_name_key ON public.users USING btree (name);
+CREATE INDEX users_lower_name ON public.users USING btree (lower((name)::text));
+CREATE INDEX users_tags_idx ON public.users USING gin (tags);
+ALTER TABLE public.users ADD CONSTRAINT users_name_length CHECK ((length((name)::text) > 0)) NOT VALID;
+
+CREATE TABLE audit.events (
+    id serial PRIMARY KEY,
+    user_id bigint REFERENCES public.users (id) MATCH SIMPLE ON DELETE SET NULL (user_id),
+    payload jsonb,
+    created_at date NOT NULL
+) PARTITION BY RANGE (created_at);
+CREATE TABLE audit.events_2023 PARTITION OF audit.events FOR VALUES FROM ('2023-01-01') TO ('2024-01-01');
+CREATE TABLE archive.events_2022 (id integer NOT NULL);
+ALTER TABLE ONLY audit.events ATTACH PARTITION archive.events_2022 FOR VALUES FROM ('2022-01-01') TO ('2023-01-01');
+CREATE TABLE posts (
+    id uuid PRIMARY KEY,
+    user_id bigint NOT NULL CONSTRAINT posts_author_fkey REFERENCES users ON UPDATE CASCADE ON DELETE RESTRICT,
+    slug text UNIQUE
+);
+`
+
+func TestParseDDLPostgres(t *testing.T) {
+	snapshot, err := ParseDDL("postgres", postgresDump)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Tables, 4)
+
+	users := snapshot.Tables[0]
+	assert.Equal(t, "public.users", users.TableName)
+	assert.Equal(t, "all users", users.TableComment)
+	assert.Equal(t, []string{"id"}, users.PrimaryKeys)
+	assert.Equal(t, define.ColumnInfo{Name: "id", TypeName: "int8", ColumnType: "bigint", DataType: "int64",
+		IsPrimaryKey: true, IsAutoIncrement: true, DefaultValue: "nextval('public.users_id_seq'::regclass)"}, users.Columns[0])
+	assert.Equal(t, define.ColumnInfo{Name: "name", TypeName: "varchar", ColumnType: "character varying(64)", DataType: "string",
+		Length: 64, DefaultValue: "'anon'::character varying", Comment: "display name"}, users.Columns[1])
+	assert.Equal(t, define.ColumnInfo{Name: "mood", TypeName: "mood", ColumnType: "mood", DataType: "string",
+		IsNullable: true, EnumValues: []string{"happy", "sad"}}, users.Columns[2])
+	assert.Equal(t, "_text", users.Columns[3].TypeName)
+	assert.Equal(t, "text[]", users.Columns[3].ColumnType)
+	assert.Equal(t, "numeric(12,3)", users.Columns[4].ColumnType)
+	assert.True(t, users.HasDecimal)
+	assert.Equal(t, "timestamp(3) with time zone", users.Columns[5].ColumnType)
+	assert.Equal(t, "timestamptz", users.Columns[5].TypeName)
+	assert.Equal(t, "now()", users.Columns[5].DefaultValue)
+	assert.Equal(t, "Group", users.Columns[6].Name)
+	assert.True(t, users.Columns[6].IsAutoIncrement)
+	assert.Equal(t, []define.IndexInfo{
+		{Name: "users_name_key", Columns: []string{"name"}, Unique: true, Method: "btree"},
+		{Name: "users_pkey", Columns: []string{"id"}, Unique: true, Primary: true, Method: "btree"},
+		{Name: "users_tags_idx", Columns: []string{"tags"}, Method: "gin"},
+	}, users.Indexes)
+	assert.Equal(t, []define.CheckConstraintInfo{
+		{Name: "users_Group_check", Expression: `("Group" <> -1)`},
+		{Name: "users_check", Expression: "(price < 1000 OR mood = 'happy'::public.mood)"},
+		{Name: "users_name_length", Expression: "((length((name)::text) > 0))"},
+		{Name: "users_price_check", Expression: "(price >= 0)"},
+	}, users.CheckConstraints)
+	assert.Empty(t, users.Engine)
+	assert.Nil(t, users.Partition)
+
+	events := snapshot.Tables[1]
+	assert.Equal(t, "audit.events", events.TableName)
+	assert.Equal(t, "nextval('events_id_seq'::regclass)", events.Columns[0].DefaultValue)
+	assert.True(t, events.Columns[0].IsAutoIncrement)
+	assert.Equal(t, "int4", events.Columns[0].TypeName)
+	assert.Equal(t, []define.ForeignKeyInfo{
+		{Name: "events_user_id_fkey", Columns: []string{"user_id"}, RefTable: "public.users", RefColumns: []string{"id"},
+			OnUpdate: "NO ACTION", OnDelete: "SET NULL"},
+	}, events.ForeignKeys)
+	assert.Equal(t, &define.PartitionInfo{Method: "RANGE", Expression: "created_at",
+		Partitions: []string{"archive.events_2022", "events_2023"}}, events.Partition)
+
+	posts := snapshot.Tables[3]
+	assert.Equal(t, "public.posts", posts.TableName)
+	assert.True(t, posts.HasUUID)
+	assert.Equal(t, []define.ForeignKeyInfo{
+		{Name: "posts_author_fkey", Columns: []string{"user_id"}, RefTable: "users", OnUpdate: "CASCADE", OnDelete: "RESTRICT"},
+	}, posts.ForeignKeys)
+	assert.Equal(t, []define.IndexInfo{
+		{Name: "posts_pkey", Columns: []string{"id"}, Unique: true, Primary: true, Method: "btree"},
+		{Name: "posts_slug_key", Columns: []string{"slug"}, Unique: true, Method: "btree"},
+	}, posts.Indexes)
+}
+
+func TestParseDDLErrors(t *testing.T) {
+	_, err := ParseDDL("sqlite", "CREATE TABLE t (id int)")
+	assert.ErrorContains(t, err, "unsupported driver")
+	_, err = ParseDDL("mysql", "CREATE TABLE t (\n  name varchar(10) DEFAULT 'x\n)")
+	assert.ErrorContains(t, err, "第 2 行: 引号没有结束")
+	_, err = ParseDDL("postgres", "SELECT 1;\nCREATE TABLE t (id int")
+	assert.ErrorContains(t, err, "第 2 行")
+}
+
+func columnNames(info *define.TableInfo) []string {
+	var names []string
+	for _, col := range info.Columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
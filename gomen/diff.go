@@ -0,0 +1,463 @@
+package gomen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// SchemaReader 读取表结构，*gom.DB 实现了该接口
+type SchemaReader interface {
+	GetTables(pattern string) ([]string, error)
+	GetTableInfo(tableName string) (*define.TableInfo, error)
+}
+
+// ColumnDiff 同名列在两个库中的差异
+type ColumnDiff struct {
+	Name            string             `json:"name"`
+	From            *define.ColumnInfo `json:"from"`
+	To              *define.ColumnInfo `json:"to"`
+	TypeChanged     bool               `json:"type_changed,omitempty"`
+	NullableChanged bool               `json:"nullable_changed,omitempty"`
+	DefaultChanged  bool               `json:"default_changed,omitempty"`
+}
+
+// IndexDiff 同名索引在两个库中的列或唯一性不同
+type IndexDiff struct {
+	Name string            `json:"name"`
+	From *define.IndexInfo `json:"from"`
+	To   *define.IndexInfo `json:"to"`
+}
+
+// ForeignKeyDiff 同名外键在两个库中的列、引用或动作不同
+type ForeignKeyDiff struct {
+	Name string                 `json:"name"`
+	From *define.ForeignKeyInfo `json:"from"`
+	To   *define.ForeignKeyInfo `json:"to"`
+}
+
+// TableDiff 同名表在两个库中的差异
+type TableDiff struct {
+	Name               string                  `json:"name"`
+	AddedColumns       []define.ColumnInfo     `json:"added_columns,omitempty"`   // 只在 to 中存在的列
+	RemovedColumns     []define.ColumnInfo     `json:"removed_columns,omitempty"` // 只在 from 中存在的列
+	ChangedColumns     []*ColumnDiff           `json:"changed_columns,omitempty"`
+	PrimaryKeyChanged  bool                    `json:"primary_key_changed,omitempty"`
+	FromPrimaryKeys    []string                `json:"from_primary_keys,omitempty"`
+	ToPrimaryKeys      []string                `json:"to_primary_keys,omitempty"`
+	AddedIndexes       []define.IndexInfo      `json:"added_indexes,omitempty"`   // 只在 to 中存在的索引，不含主键
+	RemovedIndexes     []define.IndexInfo      `json:"removed_indexes,omitempty"` // 只在 from 中存在的索引，不含主键
+	ChangedIndexes     []*IndexDiff            `json:"changed_indexes,omitempty"`
+	AddedForeignKeys   []define.ForeignKeyInfo `json:"added_foreign_keys,omitempty"`   // 只在 to 中存在的外键
+	RemovedForeignKeys []define.ForeignKeyInfo `json:"removed_foreign_keys,omitempty"` // 只在 from 中存在的外键
+	ChangedForeignKeys []*ForeignKeyDiff       `json:"changed_foreign_keys,omitempty"`
+}
+
+// Empty 表结构是否一致
+func (d *TableDiff) Empty() bool {
+	return len(d.AddedColumns) == 0 && len(d.RemovedColumns) == 0 && len(d.ChangedColumns) == 0 && !d.PrimaryKeyChanged &&
+		len(d.AddedIndexes) == 0 && len(d.RemovedIndexes) == 0 && len(d.ChangedIndexes) == 0 &&
+		len(d.AddedForeignKeys) == 0 && len(d.RemovedForeignKeys) == 0 && len(d.ChangedForeignKeys) == 0
+}
+
+// SchemaDiff 两个库的表结构差异，方向为从 from 变为 to
+type SchemaDiff struct {
+	AddedTables   []*define.TableInfo `json:"added_tables,omitempty"`   // 只在 to 中存在的表
+	RemovedTables []*define.TableInfo `json:"removed_tables,omitempty"` // 只在 from 中存在的表
+	ChangedTables []*TableDiff        `json:"changed_tables,omitempty"`
+}
+
+// Empty 两个库的表结构是否一致
+func (d *SchemaDiff) Empty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 && len(d.ChangedTables) == 0
+}
+
+// LoadSchema 读取匹配 pattern 的所有表结构，以表名为键
+func LoadSchema(db SchemaReader, pattern string) (map[string]*define.TableInfo, error) {
+	tables, err := db.GetTables(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("获取表列表失败: %v", err)
+	}
+	schema := make(map[string]*define.TableInfo, len(tables))
+	for _, table := range tables {
+		info, err := db.GetTableInfo(table)
+		if err != nil {
+			return nil, fmt.Errorf("获取表 %s 的信息失败: %v", table, err)
+		}
+		schema[table] = info
+	}
+	return schema, nil
+}
+
+// DiffSchemas 比较两个库的表结构：缺失和多余的表，列的类型、可空性和默认值，主键，以及索引和外键
+func DiffSchemas(from, to map[string]*define.TableInfo) *SchemaDiff {
+	diff := &SchemaDiff{}
+	for _, name := range sortedKeys(to) {
+		if _, ok := from[name]; !ok {
+			diff.AddedTables = append(diff.AddedTables, to[name])
+		}
+	}
+	for _, name := range sortedKeys(from) {
+		target, ok := to[name]
+		if !ok {
+			diff.RemovedTables = append(diff.RemovedTables, from[name])
+			continue
+		}
+		if tableDiff := diffTable(name, from[name], target); !tableDiff.Empty() {
+			diff.ChangedTables = append(diff.ChangedTables, tableDiff)
+		}
+	}
+	return diff
+}
+
+// diffTable 比较同名表的列、主键、索引和外键
+func diffTable(name string, from, to *define.TableInfo) *TableDiff {
+	diff := &TableDiff{Name: name}
+	fromCols := make(map[string]*define.ColumnInfo, len(from.Columns))
+	for i := range from.Columns {
+		fromCols[from.Columns[i].Name] = &from.Columns[i]
+	}
+	toCols := make(map[string]bool, len(to.Columns))
+	for i := range to.Columns {
+		target := &to.Columns[i]
+		toCols[target.Name] = true
+		current, ok := fromCols[target.Name]
+		if !ok {
+			diff.AddedColumns = append(diff.AddedColumns, *target)
+			continue
+		}
+		col := &ColumnDiff{
+			Name:            target.Name,
+			From:            current,
+			To:              target,
+			TypeChanged:     ColumnType(current) != ColumnType(target),
+			NullableChanged: current.IsNullable != target.IsNullable,
+			// 自增列的默认值引用各自的序列，不参与比较
+			DefaultChanged: !current.IsAutoIncrement && !target.IsAutoIncrement &&
+				define.NormalizeDefault(current.DefaultValue) != define.NormalizeDefault(target.DefaultValue),
+		}
+		if col.TypeChanged || col.NullableChanged || col.DefaultChanged {
+			diff.ChangedColumns = append(diff.ChangedColumns, col)
+		}
+	}
+	for _, col := range from.Columns {
+		if !toCols[col.Name] {
+			diff.RemovedColumns = append(diff.RemovedColumns, col)
+		}
+	}
+	if strings.Join(from.PrimaryKeys, ",") != strings.Join(to.PrimaryKeys, ",") {
+		diff.PrimaryKeyChanged = true
+		diff.FromPrimaryKeys = from.PrimaryKeys
+		diff.ToPrimaryKeys = to.PrimaryKeys
+	}
+	diffIndexes(diff, from, to)
+	diffForeignKeys(diff, from, to)
+	return diff
+}
+
+// diffIndexes 按名称比较索引的列和唯一性；主键索引由 PrimaryKeyChanged 表示
+func diffIndexes(diff *TableDiff, from, to *define.TableInfo) {
+	fromIndexes := make(map[string]*define.IndexInfo, len(from.Indexes))
+	for i := range from.Indexes {
+		fromIndexes[from.Indexes[i].Name] = &from.Indexes[i]
+	}
+	toIndexes := make(map[string]bool, len(to.Indexes))
+	for i := range to.Indexes {
+		target := &to.Indexes[i]
+		if target.Primary {
+			continue
+		}
+		toIndexes[target.Name] = true
+		current, ok := fromIndexes[target.Name]
+		switch {
+		case !ok:
+			diff.AddedIndexes = append(diff.AddedIndexes, *target)
+		case !define.IndexDefOf(target).Matches(current):
+			diff.ChangedIndexes = append(diff.ChangedIndexes, &IndexDiff{Name: target.Name, From: current, To: target})
+		}
+	}
+	for _, index := range from.Indexes {
+		if !index.Primary && !toIndexes[index.Name] {
+			diff.RemovedIndexes = append(diff.RemovedIndexes, index)
+		}
+	}
+}
+
+// diffForeignKeys 按名称比较外键的列、引用的表和列以及动作
+func diffForeignKeys(diff *TableDiff, from, to *define.TableInfo) {
+	fromKeys := make(map[string]*define.ForeignKeyInfo, len(from.ForeignKeys))
+	for i := range from.ForeignKeys {
+		fromKeys[from.ForeignKeys[i].Name] = &from.ForeignKeys[i]
+	}
+	toKeys := make(map[string]bool, len(to.ForeignKeys))
+	for i := range to.ForeignKeys {
+		target := &to.ForeignKeys[i]
+		toKeys[target.Name] = true
+		current, ok := fromKeys[target.Name]
+		switch {
+		case !ok:
+			diff.AddedForeignKeys = append(diff.AddedForeignKeys, *target)
+		case !define.ForeignKeyDefOf(target).Matches(current):
+			diff.ChangedForeignKeys = append(diff.ChangedForeignKeys, &ForeignKeyDiff{Name: target.Name, From: current, To: target})
+		}
+	}
+	for _, fk := range from.ForeignKeys {
+		if !toKeys[fk.Name] {
+			diff.RemovedForeignKeys = append(diff.RemovedForeignKeys, fk)
+		}
+	}
+}
+
+// ColumnType 返回列的完整类型，如 varchar(255)、numeric(10,2)
+func ColumnType(col *define.ColumnInfo) string {
+	typeName := strings.ToLower(col.TypeName)
+	switch typeName {
+	case "char", "varchar", "bpchar", "binary", "varbinary", "bit", "varbit":
+		if col.Length > 0 {
+			return fmt.Sprintf("%s(%d)", typeName, col.Length)
+		}
+	case "decimal", "numeric":
+		if col.Precision > 0 {
+			return fmt.Sprintf("%s(%d,%d)", typeName, col.Precision, col.Scale)
+		}
+	}
+	return typeName
+}
+
+// WriteText 以便于阅读的格式输出差异：+ 只在 to 中存在，- 只在 from 中存在，~ 不一致
+func (d *SchemaDiff) WriteText(w io.Writer) {
+	if d.Empty() {
+		fmt.Fprintln(w, "表结构一致")
+		return
+	}
+	for _, table := range d.AddedTables {
+		fmt.Fprintf(w, "+ table %s\n", table.TableName)
+	}
+	for _, table := range d.RemovedTables {
+		fmt.Fprintf(w, "- table %s\n", table.TableName)
+	}
+	for _, table := range d.ChangedTables {
+		fmt.Fprintf(w, "~ table %s\n", table.Name)
+		for _, col := range table.AddedColumns {
+			fmt.Fprintf(w, "    + column %s %s %s\n", col.Name, ColumnType(&col), nullText(col.IsNullable))
+		}
+		for _, col := range table.RemovedColumns {
+			fmt.Fprintf(w, "    - column %s\n", col.Name)
+		}
+		for _, col := range table.ChangedColumns {
+			var changes []string
+			if col.TypeChanged {
+				changes = append(changes, fmt.Sprintf("type %s -> %s", ColumnType(col.From), ColumnType(col.To)))
+			}
+			if col.NullableChanged {
+				changes = append(changes, fmt.Sprintf("%s -> %s", nullText(col.From.IsNullable), nullText(col.To.IsNullable)))
+			}
+			if col.DefaultChanged {
+				changes = append(changes, fmt.Sprintf("default %q -> %q", col.From.DefaultValue, col.To.DefaultValue))
+			}
+			fmt.Fprintf(w, "    ~ column %s: %s\n", col.Name, strings.Join(changes, ", "))
+		}
+		if table.PrimaryKeyChanged {
+			fmt.Fprintf(w, "    ~ primary key: (%s) -> (%s)\n", strings.Join(table.FromPrimaryKeys, ", "), strings.Join(table.ToPrimaryKeys, ", "))
+		}
+		for _, index := range table.AddedIndexes {
+			fmt.Fprintf(w, "    + index %s %s\n", index.Name, indexText(&index))
+		}
+		for _, index := range table.RemovedIndexes {
+			fmt.Fprintf(w, "    - index %s\n", index.Name)
+		}
+		for _, index := range table.ChangedIndexes {
+			fmt.Fprintf(w, "    ~ index %s: %s -> %s\n", index.Name, indexText(index.From), indexText(index.To))
+		}
+		for _, fk := range table.AddedForeignKeys {
+			fmt.Fprintf(w, "    + foreign key %s %s\n", fk.Name, foreignKeyText(&fk))
+		}
+		for _, fk := range table.RemovedForeignKeys {
+			fmt.Fprintf(w, "    - foreign key %s\n", fk.Name)
+		}
+		for _, fk := range table.ChangedForeignKeys {
+			fmt.Fprintf(w, "    ~ foreign key %s: %s -> %s\n", fk.Name, foreignKeyText(fk.From), foreignKeyText(fk.To))
+		}
+	}
+}
+
+// indexText 描述索引的列和唯一性，如 (user_id, status) UNIQUE
+func indexText(index *define.IndexInfo) string {
+	text := "(" + strings.Join(index.Columns, ", ") + ")"
+	if index.Unique {
+		text += " UNIQUE"
+	}
+	return text
+}
+
+// foreignKeyText 描述外键的列、引用和动作，如 (user_id) REFERENCES users(id) ON DELETE CASCADE
+func foreignKeyText(fk *define.ForeignKeyInfo) string {
+	text := fmt.Sprintf("(%s) REFERENCES %s(%s)", strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+	if fk.OnDelete != "" {
+		text += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		text += " ON UPDATE " + fk.OnUpdate
+	}
+	return text
+}
+
+// SQL 生成把 from 库变为与 to 库一致的 SQL 脚本，factory 为 from 库的方言
+// 主键变更需要人工处理，以注释形式列出
+// 新表的外键在所有新表创建之后添加；变更的索引和外键先删除再按 to 库重建
+func (d *SchemaDiff) SQL(factory define.SQLFactory) (string, error) {
+	var changes, foreignKeys []*define.SchemaChange
+	var notes []string
+	for _, table := range d.AddedTables {
+		def := &define.TableDef{Name: table.TableName, PrimaryKeys: table.PrimaryKeys}
+		for i := range table.Columns {
+			def.Columns = append(def.Columns, columnDef(factory.GetType(), &table.Columns[i]))
+		}
+		for i := range table.Indexes {
+			if !table.Indexes[i].Primary {
+				def.Indexes = append(def.Indexes, define.IndexDefOf(&table.Indexes[i]))
+			}
+		}
+		changes = append(changes, &define.SchemaChange{Kind: define.ChangeCreateTable, Table: table.TableName, TableDef: def})
+		for i := range table.ForeignKeys {
+			foreignKeys = append(foreignKeys, &define.SchemaChange{
+				Kind: define.ChangeAddForeignKey, Table: table.TableName, ForeignKey: define.ForeignKeyDefOf(&table.ForeignKeys[i]),
+			})
+		}
+	}
+	for _, table := range d.ChangedTables {
+		for i := range table.RemovedForeignKeys {
+			changes = append(changes, dropForeignKey(table.Name, &table.RemovedForeignKeys[i]))
+		}
+		for _, fk := range table.ChangedForeignKeys {
+			changes = append(changes, dropForeignKey(table.Name, fk.From))
+		}
+		for i := range table.RemovedIndexes {
+			changes = append(changes, dropIndex(table.Name, &table.RemovedIndexes[i]))
+		}
+		for _, index := range table.ChangedIndexes {
+			changes = append(changes, dropIndex(table.Name, index.From))
+		}
+		for i := range table.AddedColumns {
+			col := columnDef(factory.GetType(), &table.AddedColumns[i])
+			col.PrimaryKey = false
+			changes = append(changes, &define.SchemaChange{Kind: define.ChangeAddColumn, Table: table.Name, Column: col})
+		}
+		for _, col := range table.ChangedColumns {
+			target := columnDef(factory.GetType(), col.To)
+			target.PrimaryKey = false
+			changes = append(changes, &define.SchemaChange{
+				Kind: define.ChangeAlterColumn, Table: table.Name, Column: target, Current: col.From,
+				TypeChanged: col.TypeChanged, NullableChanged: col.NullableChanged, DefaultChanged: col.DefaultChanged,
+			})
+		}
+		for i := range table.RemovedColumns {
+			changes = append(changes, &define.SchemaChange{Kind: define.ChangeDropColumn, Table: table.Name, Current: &table.RemovedColumns[i]})
+		}
+		for i := range table.AddedIndexes {
+			changes = append(changes, &define.SchemaChange{Kind: define.ChangeCreateIndex, Table: table.Name, Index: define.IndexDefOf(&table.AddedIndexes[i])})
+		}
+		for _, index := range table.ChangedIndexes {
+			changes = append(changes, &define.SchemaChange{Kind: define.ChangeCreateIndex, Table: table.Name, Index: define.IndexDefOf(index.To)})
+		}
+		for i := range table.AddedForeignKeys {
+			foreignKeys = append(foreignKeys, &define.SchemaChange{Kind: define.ChangeAddForeignKey, Table: table.Name, ForeignKey: define.ForeignKeyDefOf(&table.AddedForeignKeys[i])})
+		}
+		for _, fk := range table.ChangedForeignKeys {
+			foreignKeys = append(foreignKeys, &define.SchemaChange{Kind: define.ChangeAddForeignKey, Table: table.Name, ForeignKey: define.ForeignKeyDefOf(fk.To)})
+		}
+		if table.PrimaryKeyChanged {
+			notes = append(notes, fmt.Sprintf("-- primary key of %s differs: (%s) -> (%s), not patched",
+				table.Name, strings.Join(table.FromPrimaryKeys, ", "), strings.Join(table.ToPrimaryKeys, ", ")))
+		}
+	}
+
+	changes = append(changes, foreignKeys...)
+
+	var sb strings.Builder
+	for _, change := range changes {
+		for _, proto := range factory.BuildSchemaChange(change) {
+			if proto.Error != nil {
+				return "", proto.Error
+			}
+			sb.WriteString(proto.Sql + ";\n")
+		}
+	}
+	for _, table := range d.RemovedTables {
+		proto := factory.BuildDropTable(table.TableName, true)
+		if proto.Error != nil {
+			return "", proto.Error
+		}
+		sb.WriteString(proto.Sql + ";\n")
+	}
+	for _, note := range notes {
+		sb.WriteString(note + "\n")
+	}
+	return sb.String(), nil
+}
+
+// dropIndex 删除 from 库中的索引
+func dropIndex(table string, index *define.IndexInfo) *define.SchemaChange {
+	return &define.SchemaChange{Kind: define.ChangeDropIndex, Table: table, Index: define.IndexDefOf(index)}
+}
+
+// dropForeignKey 删除 from 库中的外键
+func dropForeignKey(table string, fk *define.ForeignKeyInfo) *define.SchemaChange {
+	return &define.SchemaChange{Kind: define.ChangeDropForeignKey, Table: table, ForeignKey: define.ForeignKeyDefOf(fk)}
+}
+
+// columnDef 把读取到的列信息转换为建表用的列定义
+func columnDef(driver string, col *define.ColumnInfo) *define.ColumnDef {
+	def := &define.ColumnDef{
+		Name:          col.Name,
+		Type:          ColumnType(col),
+		Nullable:      col.IsNullable,
+		PrimaryKey:    col.IsPrimaryKey,
+		AutoIncrement: col.IsAutoIncrement,
+		Comment:       col.Comment,
+	}
+	switch {
+	case col.IsAutoIncrement && driver == "postgres":
+		// 序列默认值改由 IDENTITY 生成
+	case driver == "mysql":
+		def.Default = mysqlDefault(col.DefaultValue)
+	default:
+		def.Default = col.DefaultValue
+	}
+	return def
+}
+
+// mysqlDefault 把 information_schema 中不带引号的 MySQL 默认值还原为 SQL 表达式
+func mysqlDefault(value string) string {
+	if value == "" {
+		return ""
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	switch upper := strings.ToUpper(value); {
+	case upper == "NULL", strings.HasPrefix(upper, "CURRENT_TIMESTAMP"), strings.HasPrefix(value, "("), strings.HasPrefix(value, "'"):
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(value) + "'"
+}
+
+func nullText(nullable bool) string {
+	if nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+func sortedKeys(m map[string]*define.TableInfo) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
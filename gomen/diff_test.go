@@ -0,0 +1,142 @@
+package gomen
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/factory/mysql"
+	"github.com/kmlixh/gom/v4/factory/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staging() map[string]*define.TableInfo {
+	return map[string]*define.TableInfo{
+		"users": {TableName: "users", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{
+			{Name: "id", TypeName: "int8", IsPrimaryKey: true, IsAutoIncrement: true, DefaultValue: "nextval('users_id_seq'::regclass)"},
+			{Name: "name", TypeName: "varchar", Length: 100},
+			{Name: "status", TypeName: "varchar", Length: 16, IsNullable: true, DefaultValue: "'new'::character varying"},
+			{Name: "legacy", TypeName: "text", IsNullable: true},
+		}},
+		"old_logs": {TableName: "old_logs", Columns: []define.ColumnInfo{{Name: "id", TypeName: "int4"}}},
+	}
+}
+
+func production() map[string]*define.TableInfo {
+	return map[string]*define.TableInfo{
+		"users": {TableName: "users", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{
+			{Name: "id", TypeName: "int8", IsPrimaryKey: true, IsAutoIncrement: true, DefaultValue: "nextval('users_id_seq1'::regclass)"},
+			{Name: "name", TypeName: "varchar", Length: 255, IsNullable: true},
+			{Name: "status", TypeName: "varchar", Length: 16, IsNullable: true, DefaultValue: "'new'"},
+			{Name: "email", TypeName: "varchar", Length: 128, IsNullable: true, Comment: "login"},
+		}},
+		"orders": {TableName: "orders", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{
+			{Name: "id", TypeName: "int8", IsPrimaryKey: true, IsAutoIncrement: true, DefaultValue: "nextval('orders_id_seq'::regclass)"},
+			{Name: "total", TypeName: "numeric", Precision: 10, Scale: 2},
+		}},
+	}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	diff := DiffSchemas(staging(), production())
+	require.Len(t, diff.AddedTables, 1)
+	assert.Equal(t, "orders", diff.AddedTables[0].TableName)
+	require.Len(t, diff.RemovedTables, 1)
+	assert.Equal(t, "old_logs", diff.RemovedTables[0].TableName)
+
+	require.Len(t, diff.ChangedTables, 1)
+	users := diff.ChangedTables[0]
+	assert.Equal(t, "email", users.AddedColumns[0].Name)
+	assert.Equal(t, "legacy", users.RemovedColumns[0].Name)
+	require.Len(t, users.ChangedColumns, 1, "auto-increment and cast-only defaults are equal")
+	assert.True(t, users.ChangedColumns[0].TypeChanged)
+	assert.True(t, users.ChangedColumns[0].NullableChanged)
+	assert.False(t, users.PrimaryKeyChanged)
+
+	var buf bytes.Buffer
+	diff.WriteText(&buf)
+	assert.Equal(t, "+ table orders\n"+
+		"- table old_logs\n"+
+		"~ table users\n"+
+		"    + column email varchar(128) NULL\n"+
+		"    - column legacy\n"+
+		"    ~ column name: type varchar(100) -> varchar(255), NOT NULL -> NULL\n", buf.String())
+
+	assert.True(t, DiffSchemas(production(), production()).Empty())
+}
+
+func TestSchemaDiffSQL(t *testing.T) {
+	diff := DiffSchemas(staging(), production())
+	sql, err := diff.SQL(&postgres.Factory{})
+	require.NoError(t, err)
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS "orders" ("id" int8 GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, "total" numeric(10,2) NOT NULL);`+"\n"+
+		`ALTER TABLE "users" ADD COLUMN "email" varchar(128);`+"\n"+
+		`COMMENT ON COLUMN "users"."email" IS 'login';`+"\n"+
+		`ALTER TABLE "users" ALTER COLUMN "name" TYPE varchar(255) USING "name"::varchar(255);`+"\n"+
+		`ALTER TABLE "users" ALTER COLUMN "name" DROP NOT NULL;`+"\n"+
+		`ALTER TABLE "users" DROP COLUMN "legacy";`+"\n"+
+		`DROP TABLE IF EXISTS "old_logs";`+"\n", sql)
+
+	from := map[string]*define.TableInfo{"t": {TableName: "t", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{{Name: "id", TypeName: "int"}}}}
+	to := map[string]*define.TableInfo{"t": {TableName: "t", PrimaryKeys: []string{"id", "tenant"}, Columns: []define.ColumnInfo{
+		{Name: "id", TypeName: "int"}, {Name: "tenant", TypeName: "varchar", Length: 8, DefaultValue: "main"}}}}
+	sql, err = DiffSchemas(from, to).SQL(&mysql.Factory{})
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE `t` ADD COLUMN `tenant` varchar(8) NOT NULL DEFAULT 'main';\n"+
+		"-- primary key of t differs: (id) -> (id, tenant), not patched\n", sql)
+}
+
+func TestDiffSchemasIndexesAndForeignKeys(t *testing.T) {
+	columns := []define.ColumnInfo{{Name: "id", TypeName: "bigint"}, {Name: "user_id", TypeName: "bigint"}, {Name: "sku", TypeName: "varchar", Length: 32}}
+	from := map[string]*define.TableInfo{"orders": {TableName: "orders", PrimaryKeys: []string{"id"}, Columns: columns,
+		Indexes: []define.IndexInfo{
+			{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+			{Name: "idx_orders_sku", Columns: []string{"sku"}},
+			{Name: "idx_orders_legacy", Columns: []string{"user_id", "sku"}},
+		},
+		ForeignKeys: []define.ForeignKeyInfo{
+			{Name: "fk_orders_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}},
+		},
+	}}
+	to := map[string]*define.TableInfo{"orders": {TableName: "orders", PrimaryKeys: []string{"id"}, Columns: columns,
+		Indexes: []define.IndexInfo{
+			{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+			{Name: "idx_orders_sku", Columns: []string{"sku"}, Unique: true},
+			{Name: "idx_orders_user", Columns: []string{"user_id"}},
+		},
+		ForeignKeys: []define.ForeignKeyInfo{
+			{Name: "fk_orders_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"},
+		},
+	}}
+
+	diff := DiffSchemas(from, to)
+	require.Len(t, diff.ChangedTables, 1)
+	orders := diff.ChangedTables[0]
+	assert.Equal(t, "idx_orders_user", orders.AddedIndexes[0].Name)
+	assert.Equal(t, "idx_orders_legacy", orders.RemovedIndexes[0].Name)
+	require.Len(t, orders.ChangedIndexes, 1, "the primary key index is covered by PrimaryKeyChanged")
+	assert.Equal(t, "idx_orders_sku", orders.ChangedIndexes[0].Name)
+	require.Len(t, orders.ChangedForeignKeys, 1)
+	assert.Empty(t, orders.AddedForeignKeys)
+	assert.Empty(t, orders.RemovedForeignKeys)
+
+	var buf bytes.Buffer
+	diff.WriteText(&buf)
+	assert.Equal(t, "~ table orders\n"+
+		"    + index idx_orders_user (user_id)\n"+
+		"    - index idx_orders_legacy\n"+
+		"    ~ index idx_orders_sku: (sku) -> (sku) UNIQUE\n"+
+		"    ~ foreign key fk_orders_user: (user_id) REFERENCES users(id) -> (user_id) REFERENCES users(id) ON DELETE CASCADE\n", buf.String())
+
+	sql, err := diff.SQL(&mysql.Factory{})
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE `orders` DROP FOREIGN KEY `fk_orders_user`;\n"+
+		"DROP INDEX `idx_orders_legacy` ON `orders`;\n"+
+		"DROP INDEX `idx_orders_sku` ON `orders`;\n"+
+		"CREATE INDEX `idx_orders_user` ON `orders` (`user_id`);\n"+
+		"CREATE UNIQUE INDEX `idx_orders_sku` ON `orders` (`sku`);\n"+
+		"ALTER TABLE `orders` ADD CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;\n", sql)
+
+	assert.True(t, DiffSchemas(to, to).Empty())
+}
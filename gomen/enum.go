@@ -0,0 +1,226 @@
+package gomen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// EnumFile 枚举类型文件的文件名
+const EnumFile = "enums.go"
+
+// enumData 由枚举列生成的 Go 类型
+type enumData struct {
+	Name   string       // 类型名
+	Values []*enumValue // 可选值，按定义顺序
+}
+
+// enumValue 枚举类型的常量
+type enumValue struct {
+	Const string // 常量名，如 OrderStatusPending
+	Value string // 数据库中的值
+}
+
+// enumTypeName 返回枚举列的 Go 类型名：PostgreSQL 使用枚举类型名，MySQL 的 enum 列使用结构体名加字段名
+func (g *Generator) enumTypeName(table string, col *define.ColumnInfo, field string) string {
+	if col.TypeName == "" || strings.EqualFold(col.TypeName, "enum") {
+		return g.structName(table) + field
+	}
+	name := col.TypeName
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return g.options.Config.goName(name)
+}
+
+// buildEnums 收集 tables 中所有枚举列的类型，按类型名排序；同名类型的可选值不同时返回错误
+func (g *Generator) buildEnums(tables []string) ([]*enumData, error) {
+	byName := make(map[string]*enumData)
+	source := make(map[string]string)
+	for _, table := range tables {
+		info, err := g.db.GetTableInfo(table)
+		if err != nil {
+			return nil, fmt.Errorf("获取表 %s 的信息失败: %v", table, err)
+		}
+		for _, field := range g.buildFields(table, info) {
+			if field.Enum == nil {
+				continue
+			}
+			name := field.ColumnType
+			if existing, ok := byName[name]; ok {
+				if !sameValues(existing.Values, field.Enum) {
+					return nil, fmt.Errorf("枚举类型 %s 在 %s 和 %s.%s 中的可选值不同", name, source[name], table, field.Column)
+				}
+				continue
+			}
+			byName[name] = &enumData{Name: name, Values: g.enumValues(name, field.Enum)}
+			source[name] = table + "." + field.Column
+		}
+	}
+
+	enums := make([]*enumData, 0, len(byName))
+	for _, enum := range byName {
+		enums = append(enums, enum)
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	return enums, nil
+}
+
+// sameValues 枚举常量的值是否与 values 一致
+func sameValues(consts []*enumValue, values []string) bool {
+	if len(consts) != len(values) {
+		return false
+	}
+	for i, value := range values {
+		if consts[i].Value != value {
+			return false
+		}
+	}
+	return true
+}
+
+// enumValues 为每个值生成常量名，如 order_status 的 in_progress 对应 OrderStatusInProgress
+func (g *Generator) enumValues(typeName string, values []string) []*enumValue {
+	seen := make(map[string]int)
+	result := make([]*enumValue, len(values))
+	for i, value := range values {
+		name := typeName + g.enumConstSuffix(value)
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			name += strconv.Itoa(n)
+		}
+		result[i] = &enumValue{Const: name, Value: value}
+	}
+	return result
+}
+
+// enumConstSuffix 把枚举值转换为常量名的后缀，全大写的单词按普通单词处理，如 IN_PROGRESS 对应 InProgress
+func (g *Generator) enumConstSuffix(value string) string {
+	words := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "Empty"
+	}
+	for i, word := range words {
+		if strings.ToUpper(word) == word {
+			words[i] = strings.ToLower(word)
+		}
+	}
+	return g.options.Config.goName(strings.Join(words, "_"))
+}
+
+// GenerateEnums 生成枚举类型文件
+func (g *Generator) GenerateEnums(enums []*enumData, writer io.Writer) error {
+	data := struct {
+		Header      string
+		PackageName string
+		Enums       []*enumData
+	}{
+		Header:      generatedHeader,
+		PackageName: g.options.PackageName,
+		Enums:       enums,
+	}
+
+	tmpl, err := template.New("enums").Funcs(template.FuncMap{
+		"quote": strconv.Quote,
+	}).Parse(enumTemplate)
+	if err != nil {
+		return fmt.Errorf("解析模板失败: %v", err)
+	}
+	return executeTemplate(tmpl, data, writer)
+}
+
+// 枚举类型模板
+const enumTemplate = `{{.Header}}
+
+package {{.PackageName}}
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+{{- range .Enums}}
+{{- $name := .Name}}
+
+// {{$name}} is a database enum, its values are the {{$name}}* constants
+type {{$name}} string
+
+// Values of {{$name}}
+const (
+	{{- range .Values}}
+	{{.Const}} {{$name}} = {{quote .Value}}
+	{{- end}}
+)
+
+// {{$name}}Values returns the values of {{$name}} in definition order
+func {{$name}}Values() []{{$name}} {
+	return []{{$name}}{ {{- range $i, $v := .Values}}{{if $i}}, {{end}}{{$v.Const}}{{end -}} }
+}
+
+// Valid reports whether e is one of the values of {{$name}}
+func (e {{$name}}) Valid() bool {
+	switch e {
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v.Const}}{{end}}:
+		return true
+	}
+	return false
+}
+
+// String returns the value of e
+func (e {{$name}}) String() string {
+	return string(e)
+}
+
+// Scan implements sql.Scanner, values outside {{$name}} are rejected
+func (e *{{$name}}) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into {{$name}}", src)
+	}
+	if !{{$name}}(value).Valid() {
+		return fmt.Errorf("invalid {{$name}} value %q", value)
+	}
+	*e = {{$name}}(value)
+	return nil
+}
+
+// Value implements driver.Valuer, values outside {{$name}} are rejected
+func (e {{$name}}) Value() (driver.Value, error) {
+	if !e.Valid() {
+		return nil, fmt.Errorf("invalid {{$name}} value %q", string(e))
+	}
+	return string(e), nil
+}
+
+// MarshalJSON encodes e as a JSON string
+func (e {{$name}}) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(e))
+}
+
+// UnmarshalJSON decodes a JSON string, values outside {{$name}} are rejected
+func (e *{{$name}}) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if !{{$name}}(value).Valid() {
+		return fmt.Errorf("invalid {{$name}} value %q", value)
+	}
+	*e = {{$name}}(value)
+	return nil
+}
+{{- end}}
+`
@@ -0,0 +1,73 @@
+package gomen
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enumSchema() fakeSchema {
+	return fakeSchema{
+		"orders": {TableName: "orders", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{
+			{Name: "id", DataType: "int64", IsPrimaryKey: true},
+			{Name: "status", TypeName: "enum", ColumnType: "enum('pending','in_progress','DONE')", DataType: "string",
+				EnumValues: []string{"pending", "in_progress", "DONE"}},
+			{Name: "mood", TypeName: "mood", DataType: "string", IsNullable: true, EnumValues: []string{"happy", "sad", "it's \"ok\""}},
+		}},
+		"people": {TableName: "people", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{
+			{Name: "id", DataType: "int64", IsPrimaryKey: true},
+			{Name: "mood", TypeName: "mood", DataType: "string", EnumValues: []string{"happy", "sad", "it's \"ok\""}},
+		}},
+	}
+}
+
+func TestGenerateEnums(t *testing.T) {
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom"}, db: enumSchema()}
+	files, err := g.GenerateFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"enums.go", "gomen.go", "orders.go", "people.go"}, sortedFileNames(files))
+	_, err = parser.ParseFile(token.NewFileSet(), EnumFile, files[EnumFile], 0)
+	require.NoError(t, err, string(files[EnumFile]))
+
+	enums := compact(string(files[EnumFile]))
+	assert.Contains(t, enums, "type OrdersStatus string")
+	assert.Contains(t, enums, `OrdersStatusInProgress OrdersStatus = "in_progress"`)
+	assert.Contains(t, enums, `OrdersStatusDone OrdersStatus = "DONE"`)
+	assert.Contains(t, enums, "case OrdersStatusPending, OrdersStatusInProgress, OrdersStatusDone:")
+	assert.Contains(t, enums, "func OrdersStatusValues() []OrdersStatus {")
+	assert.Contains(t, enums, "func (e *OrdersStatus) Scan(src interface{}) error {")
+	assert.Contains(t, enums, "func (e OrdersStatus) Value() (driver.Value, error) {")
+	assert.Contains(t, enums, "func (e *OrdersStatus) UnmarshalJSON(data []byte) error {")
+	// PostgreSQL 的枚举类型只生成一次
+	assert.Equal(t, 1, strings.Count(enums, "type Mood string"))
+	assert.Contains(t, enums, `MoodItSOk Mood = "it's \"ok\""`)
+
+	orders := compact(string(files["orders.go"]))
+	assert.Contains(t, orders, "Status OrdersStatus `gom:\"status\"`")
+	assert.Contains(t, orders, "Mood *Mood `gom:\"mood\"`")
+	assert.Contains(t, orders, "Mood define.Column[Mood]")
+	assert.Contains(t, compact(string(files["people.go"])), "Mood Mood `gom:\"mood\"`")
+}
+
+func TestGenerateEnumsConflict(t *testing.T) {
+	schema := enumSchema()
+	schema["people"].Columns[1].EnumValues = []string{"happy"}
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom"}, db: schema}
+	_, err := g.GenerateFiles()
+	assert.ErrorContains(t, err, "枚举类型 Mood 在 orders.mood 和 people.mood 中的可选值不同")
+}
+
+func TestGenerateEnumsWithTypeOverride(t *testing.T) {
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom", Config: &Config{Tables: map[string]*TableConfig{
+		"orders": {Columns: map[string]*ColumnConfig{"status": {Type: "string"}}},
+	}}}, db: enumSchema()}
+	files, err := g.GenerateFiles()
+	require.NoError(t, err)
+	assert.NotContains(t, string(files[EnumFile]), "OrdersStatus")
+	assert.Contains(t, compact(string(files["orders.go"])), "Status string `gom:\"status\"`")
+}
@@ -1,21 +1,20 @@
 package gomen
 
 import (
-	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
+	"go/format"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
 	"unicode"
 
-	"database/sql"
-
 	"github.com/kmlixh/gom/v4"
 	"github.com/kmlixh/gom/v4/define"
 	"github.com/kmlixh/gom/v4/factory/mysql"
@@ -24,16 +23,41 @@ import (
 
 // Options 代码生成选项
 type Options struct {
-	Driver      string // 数据库驱动类型 (mysql/postgres)
-	URL         string // 数据库连接URL
-	OutputDir   string // 输出目录
-	PackageName string // 包名
-	Pattern     string // 表名匹配模式 (PostgreSQL 可用 schema.table* 格式)
-	Debug       bool   // 是否开启调试模式
-	TagStyle    string // 标签风格 (gom/db)
-	GenerateDB  bool   // 是否生成db标签
-	Prefix      string // 表名前缀（生成时会去掉）
-	Suffix      string // 表名后缀（生成时会去掉）
+	Driver      string  // 数据库驱动类型 (mysql/postgres)
+	URL         string  // 数据库连接URL
+	OutputDir   string  // 输出目录
+	PackageName string  // 包名
+	Pattern     string  // 表名匹配模式 (PostgreSQL 可用 schema.table* 格式)
+	Debug       bool    // 是否开启调试模式
+	TagStyle    string  // 标签风格 (gom/db)
+	GenerateDB  bool    // 是否生成db标签
+	Prefix      string  // 表名前缀（生成时会去掉）
+	Suffix      string  // 表名后缀（生成时会去掉）
+	Config      *Config // 配置文件中的覆盖项，可为空
+	Repository  bool    // 是否为每个表生成仓库（数据访问层）
+	Relations   bool    // 是否根据外键生成关联字段
+	Views       bool    // 是否同时为视图和物化视图生成只读模型
+	DDLFile     string  // 建表脚本文件，设置时从脚本解析表结构，不连接数据库
+	Snapshot    string  // 表结构快照文件 (JSON)，设置时从快照读取表结构，不连接数据库
+}
+
+// viewLister 可以读取视图列表的数据源，*gom.DB 和 Snapshot 实现了该接口
+type viewLister interface {
+	GetViews(pattern string) ([]string, error)
+	GetMaterializedViews(pattern string) ([]string, error)
+}
+
+// listViews 返回匹配 pattern 的视图和物化视图
+func listViews(db viewLister, pattern string) ([]string, error) {
+	views, err := db.GetViews(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("获取视图列表失败: %v", err)
+	}
+	matviews, err := db.GetMaterializedViews(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("获取物化视图列表失败: %v", err)
+	}
+	return append(views, matviews...), nil
 }
 
 // Generator 代码生成器
@@ -44,6 +68,8 @@ type Generator struct {
 		GetTableInfo(tableName string) (*define.TableInfo, error)
 		Close() error
 	}
+	// relations 由 GenerateFiles 根据外键推断的关联字段，以表名为键
+	relations map[string][]*relation
 }
 
 // NewGenerator 创建代码生成器实例
@@ -53,6 +79,26 @@ func NewGenerator(options Options) (*Generator, error) {
 		return nil, err
 	}
 
+	// 离线生成
+	if options.Snapshot != "" || options.DDLFile != "" {
+		var snapshot *Snapshot
+		var err error
+		if options.Snapshot != "" {
+			snapshot, err = LoadSnapshot(options.Snapshot)
+		} else {
+			snapshot, err = LoadDDLFile(options.Driver, options.DDLFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if options.Driver == "" {
+			options.Driver = snapshot.Dialect
+		} else if options.Driver != snapshot.Dialect {
+			return nil, fmt.Errorf("快照的数据库类型为 %s，与 %s 不一致", snapshot.Dialect, options.Driver)
+		}
+		return &Generator{options: options, db: snapshot}, nil
+	}
+
 	// 创建数据库连接
 	if options.Driver == "mysql" {
 		mysql.RegisterFactory()
@@ -90,63 +136,188 @@ func (g *Generator) Close() error {
 	return nil
 }
 
-// Generate 生成代码
+// RegistryFile 模型注册文件的文件名
+const RegistryFile = "gomen.go"
+
+// Generate 生成代码；writer 为空时每个表写入输出目录下的单独文件，否则依次写入 writer
 func (g *Generator) Generate(writer io.Writer) error {
-	// 如果没有提供 writer，使用默认的输出流
+	files, err := g.GenerateFiles()
+	if err != nil {
+		return err
+	}
 	if writer == nil {
-		var err error
-		writer, err = g.createDefaultWriter()
-		if err != nil {
-			return fmt.Errorf("创建默认输出流失败: %v", err)
-		}
-		if bw, ok := writer.(*bufio.Writer); ok {
-			defer bw.Flush()
+		return g.WriteFiles(files)
+	}
+	for _, name := range sortedFileNames(files) {
+		if _, err := writer.Write(files[name]); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
+// GenerateFiles 生成每个表的模型文件和模型注册文件，以文件名为键；没有匹配的表时返回空
+func (g *Generator) GenerateFiles() (map[string][]byte, error) {
 	// 获取表列表
 	tables, err := g.db.GetTables(g.options.Pattern)
 	if err != nil {
-		return fmt.Errorf("获取表列表失败: %v", err)
+		return nil, fmt.Errorf("获取表列表失败: %v", err)
+	}
+	if g.options.Views {
+		lister, ok := g.db.(viewLister)
+		if !ok {
+			return nil, errors.New("数据源不支持读取视图")
+		}
+		views, err := listViews(lister, g.options.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, views...)
+		sort.Strings(tables)
+	}
+
+	// 按配置过滤表
+	var included []string
+	for _, table := range tables {
+		if g.options.Config.includeTable(table) {
+			included = append(included, table)
+		}
 	}
+	tables = included
 
 	// 如果没有找到表,返回
 	if len(tables) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	// 创建输出目录
-	if err := os.MkdirAll(g.options.OutputDir, 0755); err != nil {
-		return fmt.Errorf("创建输出目录失败: %v", err)
+	if g.options.Relations {
+		relations, err := g.buildRelations(tables)
+		if err != nil {
+			return nil, err
+		}
+		g.relations = relations
 	}
 
 	// 生成每个表的结构体
+	files := make(map[string][]byte, len(tables)+1)
 	for _, table := range tables {
-		if err := g.GenerateTableStruct(table, writer); err != nil {
-			return fmt.Errorf("生成表 %s 的结构体失败: %v", table, err)
+		var buf bytes.Buffer
+		if err := g.GenerateTableStruct(table, &buf); err != nil {
+			return nil, fmt.Errorf("生成表 %s 的结构体失败: %v", table, err)
+		}
+		name := g.fileName(table)
+		if _, ok := files[name]; ok {
+			return nil, fmt.Errorf("表 %s 的文件名 %s 与其他表重复", table, name)
+		}
+		files[name] = buf.Bytes()
+
+		if g.options.Repository {
+			var repo bytes.Buffer
+			if err := g.GenerateRepository(table, &repo); err != nil {
+				return nil, fmt.Errorf("生成表 %s 的仓库失败: %v", table, err)
+			}
+			name := g.repoFileName(table)
+			if _, ok := files[name]; ok {
+				return nil, fmt.Errorf("表 %s 的仓库文件名 %s 与其他表重复", table, name)
+			}
+			files[name] = repo.Bytes()
 		}
 	}
 
-	// 生成模型注册文件
-	if err := g.GenerateModelRegistry(tables, writer); err != nil {
-		return fmt.Errorf("生成模型注册文件失败: %v", err)
+	// 生成枚举类型文件
+	enums, err := g.buildEnums(tables)
+	if err != nil {
+		return nil, err
+	}
+	if len(enums) > 0 {
+		var buf bytes.Buffer
+		if err := g.GenerateEnums(enums, &buf); err != nil {
+			return nil, fmt.Errorf("生成枚举类型失败: %v", err)
+		}
+		files[EnumFile] = buf.Bytes()
 	}
 
-	// 格式化生成的代码
-	if err := formatGeneratedCode(g.options.OutputDir); err != nil {
-		return fmt.Errorf("格式化生成的代码失败: %v", err)
+	// 生成模型注册文件
+	var buf bytes.Buffer
+	if err := g.GenerateModelRegistry(tables, &buf); err != nil {
+		return nil, fmt.Errorf("生成模型注册文件失败: %v", err)
 	}
+	files[RegistryFile] = buf.Bytes()
+	return files, nil
+}
 
+// WriteFiles 把生成的文件写入输出目录
+func (g *Generator) WriteFiles(files map[string][]byte) error {
+	if err := os.MkdirAll(g.options.OutputDir, 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %v", err)
+	}
+	for _, name := range sortedFileNames(files) {
+		if err := os.WriteFile(filepath.Join(g.options.OutputDir, name), files[name], 0644); err != nil {
+			return fmt.Errorf("写入文件 %s 失败: %v", name, err)
+		}
+	}
 	return nil
 }
 
-// createDefaultWriter 创建默认的输出流
-func (g *Generator) createDefaultWriter() (io.Writer, error) {
-	// 创建输出目录
-	if err := os.MkdirAll(g.options.OutputDir, 0755); err != nil {
-		return nil, fmt.Errorf("创建输出目录失败: %v", err)
+// Drift 生成结果与输出目录中文件的差异
+type Drift struct {
+	File   string
+	Reason string // changed: 内容不一致；missing: 文件不存在；stale: 生成的文件已没有对应的表
+}
+
+// Check 比较生成结果与输出目录中的文件，返回所有差异
+func (g *Generator) Check(files map[string][]byte) ([]Drift, error) {
+	var drifts []Drift
+	for _, name := range sortedFileNames(files) {
+		current, err := os.ReadFile(filepath.Join(g.options.OutputDir, name))
+		switch {
+		case os.IsNotExist(err):
+			drifts = append(drifts, Drift{File: name, Reason: "missing"})
+		case err != nil:
+			return nil, err
+		case !bytes.Equal(current, files[name]):
+			drifts = append(drifts, Drift{File: name, Reason: "changed"})
+		}
+	}
+
+	// 带有生成标记但不在本次结果中的文件
+	entries, err := os.ReadDir(g.options.OutputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || files[name] != nil {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(g.options.OutputDir, name))
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(content, []byte(generatedHeader)) {
+			drifts = append(drifts, Drift{File: name, Reason: "stale"})
+		}
 	}
-	return bufio.NewWriter(os.Stdout), nil
+	return drifts, nil
+}
+
+// fileName 返回表的模型文件名，由结构体名转换而来，如 UserOrder 对应 user_order.go
+func (g *Generator) fileName(table string) string {
+	name := snakeCase(g.structName(table))
+	if strings.HasSuffix(name, "_test") || name+".go" == RegistryFile || name+".go" == EnumFile {
+		// 避免被当作测试文件或与注册文件、枚举类型文件重名
+		name += "_model"
+	}
+	return name + ".go"
+}
+
+func sortedFileNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // validateOptions 验证生成选项
@@ -155,15 +326,20 @@ func validateOptions(options *Options) error {
 		return errors.New("options cannot be nil")
 	}
 
-	if options.Driver == "" {
+	if options.Snapshot != "" && options.DDLFile != "" {
+		return errors.New("snapshot and ddl file cannot be used together")
+	}
+
+	// 快照中记录了数据库类型
+	if options.Driver == "" && options.Snapshot == "" {
 		return errors.New("driver cannot be empty")
 	}
 
-	if options.Driver != "mysql" && options.Driver != "postgres" {
+	if options.Driver != "" && options.Driver != "mysql" && options.Driver != "postgres" {
 		return fmt.Errorf("unsupported driver: %s", options.Driver)
 	}
 
-	if options.URL == "" {
+	if options.URL == "" && options.Snapshot == "" && options.DDLFile == "" {
 		return errors.New("url cannot be empty")
 	}
 
@@ -195,53 +371,67 @@ func (g *Generator) GenerateTableStruct(tableName string, writer io.Writer) erro
 	tableInfo.HasUUID = hasUUIDType(tableInfo.Columns)
 	tableInfo.HasIP = hasIPType(tableInfo.Columns)
 
-	// 处理表名
-	structName := tableInfo.TableName
-	if idx := strings.LastIndex(structName, "."); idx >= 0 {
-		structName = structName[idx+1:]
-	}
-	if g.options.Prefix != "" {
-		structName = strings.TrimPrefix(structName, g.options.Prefix)
+	structName := g.structName(tableName)
+	fields := g.buildFields(tableName, tableInfo)
+
+	// 钩子只为 time.Time 类型的时间字段赋值
+	var createdAtField, updatedAtField string
+	for _, field := range fields {
+		if field.Type != "time.Time" {
+			continue
+		}
+		switch field.Column {
+		case "created_at":
+			createdAtField = field.Name
+		case "updated_at":
+			updatedAtField = field.Name
+		}
 	}
-	if g.options.Suffix != "" {
-		structName = strings.TrimSuffix(structName, g.options.Suffix)
+
+	// 视图不能写入，不生成钩子
+	hooks := g.options.Config.hooks(tableName) && !tableInfo.IsView
+	required := []string{"github.com/kmlixh/gom/v4/define"}
+	if hooks && (createdAtField != "" || updatedAtField != "") {
+		required = append(required, "time")
 	}
-	structName = toGoName(structName)
+	stdImports, imports := fileImports(fields, required...)
+
 	// 准备模板数据
 	data := struct {
-		Timestamp    string
-		PackageName  string
-		TableInfo    *define.TableInfo
-		StructName   string
-		GenerateDB   bool
-		TagStyle     string
-		GenerateJson bool
+		Header         string
+		PackageName    string
+		TableInfo      *define.TableInfo
+		StructName     string
+		Fields         []*fieldData
+		Relations      []*fieldData
+		StdImports     []string
+		Imports        []string
+		Hooks          bool
+		CreatedAtField string
+		UpdatedAtField string
 	}{
-		Timestamp:    time.Now().Format("2006-01-02 15:04:05"),
-		PackageName:  g.options.PackageName,
-		TableInfo:    tableInfo,
-		StructName:   structName,
-		GenerateDB:   g.options.GenerateDB,
-		TagStyle:     g.options.TagStyle,
-		GenerateJson: true, // 默认生成 JSON 标签
+		Header:         generatedHeader,
+		PackageName:    g.options.PackageName,
+		TableInfo:      tableInfo,
+		StructName:     structName,
+		Fields:         fields,
+		Relations:      g.relationFields(g.relations[tableName]),
+		StdImports:     stdImports,
+		Imports:        imports,
+		Hooks:          hooks,
+		CreatedAtField: createdAtField,
+		UpdatedAtField: updatedAtField,
 	}
 
 	// 使用模板生成代码
 	tmpl, err := template.New("struct").Funcs(template.FuncMap{
-		"toGoName":    toGoName,
-		"goType":      goType,
-		"buildTags":   g.buildTags,
-		"isTimeField": isTimeField,
+		"lowerFirst": lowerFirst,
 	}).Parse(structTemplate)
 	if err != nil {
 		return fmt.Errorf("解析模板失败: %v", err)
 	}
 
-	if err := tmpl.Execute(writer, data); err != nil {
-		return fmt.Errorf("生成代码失败: %v", err)
-	}
-
-	return nil
+	return executeTemplate(tmpl, data, writer)
 }
 
 // generateModelRegistry 生成模型注册文件
@@ -250,24 +440,15 @@ func (g *Generator) GenerateModelRegistry(tables []string, writer io.Writer) err
 	// 准备模型列表
 	var models []string
 	for _, table := range tables {
-		if idx := strings.LastIndex(table, "."); idx >= 0 {
-			table = table[idx+1:]
-		}
-		if g.options.Prefix != "" {
-			table = strings.TrimPrefix(table, g.options.Prefix)
-		}
-		if g.options.Suffix != "" {
-			table = strings.TrimSuffix(table, g.options.Suffix)
-		}
-		models = append(models, toGoName(table))
+		models = append(models, g.structName(table))
 	}
 
 	data := struct {
-		Timestamp   string
+		Header      string
 		PackageName string
 		Models      []string
 	}{
-		Timestamp:   time.Now().Format("2006-01-02 15:04:05"),
+		Header:      generatedHeader,
 		PackageName: g.options.PackageName,
 		Models:      models,
 	}
@@ -277,20 +458,160 @@ func (g *Generator) GenerateModelRegistry(tables []string, writer io.Writer) err
 		return fmt.Errorf("解析模板失败: %v", err)
 	}
 
-	if err := tmpl.Execute(writer, data); err != nil {
-		return fmt.Errorf("生成模型注册文件失败: %v", err)
+	return executeTemplate(tmpl, data, writer)
+}
+
+// executeTemplate 执行模板并用 gofmt 格式化结果
+func executeTemplate(tmpl *template.Template, data interface{}, writer io.Writer) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("生成代码失败: %v", err)
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("格式化生成的代码失败: %v\n%s", err, buf.String())
+	}
+	_, err = writer.Write(code)
+	return err
+}
+
+// fieldData 生成结构体字段和列描述所需的信息
+type fieldData struct {
+	Name       string // 字段名
+	Column     string // 列名
+	Type       string // 字段类型
+	ColumnType string // 列描述 define.Column 的类型参数
+	ImportPath string // 字段类型所在的包，无需导入时为空
+	Tags       string
+	Comment    string
+	Enum       []string // 枚举列的可选值，此时 ColumnType 为生成的枚举类型
+}
+
+// structName 返回表对应的结构体名：配置的 struct_name，或去掉 schema、前缀和后缀后的表名
+func (g *Generator) structName(table string) string {
+	if t := g.options.Config.table(table); t != nil && t.StructName != "" {
+		return t.StructName
+	}
+	name := table
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	prefix, suffix := g.options.Prefix, g.options.Suffix
+	if config := g.options.Config; config != nil {
+		if config.TrimPrefix != "" {
+			prefix = config.TrimPrefix
+		}
+		if config.TrimSuffix != "" {
+			suffix = config.TrimSuffix
+		}
 	}
+	if prefix != "" {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	if suffix != "" {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return g.options.Config.goName(name)
+}
 
-	return nil
+// buildFields 根据列信息和配置生成字段
+func (g *Generator) buildFields(table string, tableInfo *define.TableInfo) []*fieldData {
+	config := g.options.Config
+	var fields []*fieldData
+	for i := range tableInfo.Columns {
+		col := &tableInfo.Columns[i]
+		override := config.column(table, col.Name)
+		if override != nil && override.Skip {
+			continue
+		}
+
+		field := &fieldData{Name: config.goName(col.Name), Column: col.Name, Comment: col.Comment}
+		if override != nil && override.FieldName != "" {
+			field.Name = override.FieldName
+		}
+		if mapped := config.mappedType(table, col); mapped != "" {
+			field.ImportPath, field.ColumnType = splitGoType(mapped)
+			field.Type = field.ColumnType
+			if col.IsNullable && !nilable(field.Type) {
+				field.Type = "*" + field.Type
+			}
+		} else if len(col.EnumValues) > 0 {
+			field.ColumnType = g.enumTypeName(table, col, field.Name)
+			field.Type = field.ColumnType
+			if col.IsNullable {
+				field.Type = "*" + field.Type
+			}
+			field.Enum = col.EnumValues
+		} else {
+			field.Type = goType(col.DataType, col.IsNullable)
+			field.ColumnType = goType(col.DataType, false)
+		}
+		field.Tags = g.buildTags(col, override)
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// nilable 类型本身是否可以表示 NULL
+func nilable(goType string) bool {
+	for _, prefix := range []string{"*", "[]", "map[", "sql.Null", "json.RawMessage", "net.IP", "interface{}"} {
+		if strings.HasPrefix(goType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// knownPackages 生成的类型中用到的包
+var knownPackages = map[string]string{
+	"time":    "time",
+	"sql":     "database/sql",
+	"json":    "encoding/json",
+	"net":     "net",
+	"decimal": "github.com/shopspring/decimal",
+	"uuid":    "github.com/google/uuid",
+}
+
+// qualifiedName 匹配类型中的包名，如 *sql.NullTime 中的 sql
+var qualifiedName = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\.[A-Z]`)
+
+// fileImports 根据字段类型计算需要导入的包，加上 required 中的包，分为标准库和其他包两组
+func fileImports(fields []*fieldData, required ...string) ([]string, []string) {
+	seen := make(map[string]bool)
+	for _, importPath := range required {
+		seen[importPath] = true
+	}
+	for _, field := range fields {
+		if field.ImportPath != "" {
+			seen[field.ImportPath] = true
+		}
+		for _, match := range qualifiedName.FindAllStringSubmatch(field.Type+" "+field.ColumnType, -1) {
+			if importPath, ok := knownPackages[match[1]]; ok && !strings.HasSuffix(field.ImportPath, "/"+match[1]) {
+				seen[importPath] = true
+			}
+		}
+	}
+
+	var std, others []string
+	for importPath := range seen {
+		if strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".") {
+			others = append(others, importPath)
+		} else {
+			std = append(std, importPath)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+	return std, others
 }
 
 // buildTags 构建字段标签
-func (g *Generator) buildTags(col *define.ColumnInfo) string {
+func (g *Generator) buildTags(col *define.ColumnInfo, override *ColumnConfig) string {
 	var tags []string
 
 	// 根据 TagStyle 添加主标签
 	if g.options.TagStyle == "gom" {
-		tags = append(tags, fmt.Sprintf(`gom:"%s"`, col.Name))
+		tags = append(tags, fmt.Sprintf(`gom:"%s%s"`, col.Name, primaryKeyOption(col)))
 	} else {
 		tags = append(tags, fmt.Sprintf(`db:"%s"`, col.Name))
 	}
@@ -300,36 +621,36 @@ func (g *Generator) buildTags(col *define.ColumnInfo) string {
 		tags = append(tags, fmt.Sprintf(`db:"%s"`, col.Name))
 	}
 
-	return strings.Join(tags, " ")
-}
-
-// formatGeneratedCode 格式化生成的代码
-func formatGeneratedCode(dir string) error {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return err
+	// 配置的额外标签
+	if config := g.options.Config; config != nil {
+		for _, tag := range config.Tags {
+			tags = append(tags, fmt.Sprintf(`%s:"%s"`, tag, col.Name))
+		}
 	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".go" {
-			filePath := filepath.Join(dir, entry.Name())
-			if err := formatGoFile(filePath); err != nil {
-				return fmt.Errorf("格式化文件 %s 失败: %v", filePath, err)
-			}
+	if override != nil {
+		keys := make([]string, 0, len(override.Tags))
+		for key := range override.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			tags = append(tags, fmt.Sprintf(`%s:"%s"`, key, override.Tags[key]))
 		}
 	}
 
-	return nil
+	return strings.Join(tags, " ")
 }
 
-// formatGoFile 格式化单个Go文件
-func formatGoFile(filePath string) error {
-	cmd := exec.Command("go", "fmt", filePath)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("go fmt failed: %v, output: %s", err, string(output))
+// primaryKeyOption 返回主键列在 gom 标签中的选项：自增主键为 @，其他主键为 !
+func primaryKeyOption(col *define.ColumnInfo) string {
+	switch {
+	case !col.IsPrimaryKey:
+		return ""
+	case col.IsAutoIncrement:
+		return ",@"
+	default:
+		return ",!"
 	}
-	return nil
 }
 
 // 辅助函数
@@ -341,6 +662,16 @@ func toGoName(name string) string {
 	return strings.Join(parts, "")
 }
 
+// lowerFirst 把首字母转换为小写，用于生成未导出的类型名
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
 func snakeCase(s string) string {
 	var result []rune
 	var lastUpper bool
@@ -358,108 +689,33 @@ func snakeCase(s string) string {
 	return string(result)
 }
 
-func goType(dataType reflect.Type, isNullable bool) string {
-	if dataType == nil {
+// goType 把列的 DataType（如 int64、time.Time）转换为字段类型，可空列使用 sql.Null* 或指针类型
+func goType(dataType string, isNullable bool) string {
+	if dataType == "" {
 		return "interface{}"
 	}
-
-	// 处理 sql.Null* 类型
-	switch dataType {
-	case reflect.TypeOf(sql.NullInt32{}):
-		if isNullable {
-			return "*sql.NullInt32"
-		}
-		return "int32"
-	case reflect.TypeOf(sql.NullInt64{}):
-		if isNullable {
-			return "*sql.NullInt64"
-		}
-		return "int64"
-	case reflect.TypeOf(sql.NullFloat64{}):
-		if isNullable {
-			return "*sql.NullFloat64"
-		}
-		return "float64"
-	case reflect.TypeOf(sql.NullString{}):
-		if isNullable {
-			return "*sql.NullString"
-		}
-		return "string"
-	case reflect.TypeOf(sql.NullBool{}):
-		if isNullable {
-			return "*sql.NullBool"
-		}
-		return "bool"
-	case reflect.TypeOf(sql.NullTime{}):
-		if isNullable {
-			return "*sql.NullTime"
-		}
-		return "time.Time"
-	case reflect.TypeOf(sql.RawBytes{}):
-		if isNullable {
-			return "*[]byte"
-		}
-		return "[]byte"
-	}
-
-	// 处理基本类型
-	switch dataType.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
-		if isNullable {
-			return "*sql.NullInt32"
-		}
-		return "int32"
-	case reflect.Int64:
-		if isNullable {
-			return "*sql.NullInt64"
-		}
-		return "int64"
-	case reflect.Float32, reflect.Float64:
-		if isNullable {
-			return "*sql.NullFloat64"
-		}
-		return "float64"
-	case reflect.Bool:
-		if isNullable {
-			return "*sql.NullBool"
-		}
-		return "bool"
-	case reflect.String:
-		if isNullable {
-			return "*sql.NullString"
-		}
-		return "string"
-	case reflect.Struct:
-		typeName := dataType.String()
-		switch {
-		case strings.Contains(typeName, "decimal.Decimal"):
-			if isNullable {
-				return "*decimal.Decimal"
-			}
-			return "decimal.Decimal"
-		case strings.Contains(typeName, "uuid.UUID"):
-			if isNullable {
-				return "*uuid.UUID"
-			}
-			return "uuid.UUID"
-		case strings.Contains(typeName, "net.IP"):
-			if isNullable {
-				return "*net.IP"
-			}
-			return "net.IP"
-		case strings.Contains(typeName, "time.Time"):
-			if isNullable {
-				return "*sql.NullTime"
-			}
-			return "time.Time"
-		}
+	if !isNullable {
+		return dataType
 	}
 
-	// 默认处理
-	if isNullable {
-		return "*" + dataType.String()
-	}
-	return dataType.String()
+	switch dataType {
+	case "int8", "int16", "int32":
+		return "*sql.NullInt32"
+	case "int", "int64":
+		return "*sql.NullInt64"
+	case "float32", "float64":
+		return "*sql.NullFloat64"
+	case "string":
+		return "*sql.NullString"
+	case "bool":
+		return "*sql.NullBool"
+	case "time.Time":
+		return "*sql.NullTime"
+	case "[]byte", "json.RawMessage", "net.IP":
+		// nil 即为 NULL
+		return dataType
+	}
+	return "*" + dataType
 }
 
 func isTimeField(name string) bool {
@@ -498,30 +754,29 @@ func hasIPType(columns []define.ColumnInfo) bool {
 	return false
 }
 
+// generatedHeader 生成文件的首行，用于识别 gomen 生成的文件
+const generatedHeader = "// Code generated by gomen. DO NOT EDIT."
+
 // 结构体模板
-const structTemplate = `// Code generated by gom at {{.Timestamp}}. DO NOT EDIT.
+const structTemplate = `{{.Header}}
+
 package {{.PackageName}}
 
 import (
-	"time"
-	{{- if .GenerateJson}}
-	"encoding/json"
-	{{- end}}
-	{{- if .TableInfo.HasDecimal}}
-	"github.com/shopspring/decimal"
-	{{- end}}
-	{{- if .TableInfo.HasUUID}}
-	"github.com/google/uuid"
-	{{- end}}
-	{{- if .TableInfo.HasIP}}
-	"net"
-	{{- end}}
-)
+{{range .StdImports}}	"{{.}}"
+{{end}}{{if and .StdImports .Imports}}
+{{end}}{{range .Imports}}	"{{.}}"
+{{end}})
 
 // {{.StructName}} {{.TableInfo.TableComment}}
 type {{.StructName}} struct {
-	{{- range .TableInfo.Columns}}
-	{{toGoName .Name}} {{goType .DataType .IsNullable}} ` + "`" + `{{buildTags .}}` + "`" + ` {{if .Comment}}// {{.Comment}}{{end}}
+	{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `{{.Tags}}` + "`" + ` {{if .Comment}}// {{.Comment}}{{end}}
+	{{- end}}
+	{{- if .Relations}}
+{{range .Relations}}
+	{{.Name}} {{.Type}} ` + "`" + `{{.Tags}}` + "`" + `
+	{{- end}}
 	{{- end}}
 }
 
@@ -529,34 +784,61 @@ type {{.StructName}} struct {
 func (m *{{.StructName}}) TableName() string {
 	return "{{.TableInfo.TableName}}"
 }
+{{- if .TableInfo.IsView}}
+
+// ReadOnly reports that {{.TableInfo.TableName}} is a view, gom refuses to insert, update or delete it
+func (m *{{.StructName}}) ReadOnly() bool {
+	return true
+}
+{{- end}}
+
+// {{lowerFirst .StructName}}Columns holds the typed columns of {{.TableInfo.TableName}}
+type {{lowerFirst .StructName}}Columns struct {
+	{{- range .Fields}}
+	{{.Name}} define.Column[{{.ColumnType}}]
+	{{- end}}
+}
+
+// Fields returns the column names of {{.TableInfo.TableName}} in table order
+func ({{lowerFirst .StructName}}Columns) Fields() []string {
+	return []string{ {{- range $i, $field := .Fields}}{{if $i}}, {{end}}"{{$field.Column}}"{{end -}} }
+}
+
+// {{.StructName}}Cols describes the columns of {{.TableInfo.TableName}} for type-checked conditions
+var {{.StructName}}Cols = {{lowerFirst .StructName}}Columns{
+	{{- range .Fields}}
+	{{.Name}}: define.Col[{{.ColumnType}}]("{{.Column}}"),
+	{{- end}}
+}
+{{- if .Hooks}}
 
 // BeforeCreate handles the before create hook
 func (m *{{.StructName}}) BeforeCreate() error {
+	{{- if or .CreatedAtField .UpdatedAtField}}
 	now := time.Now()
-	{{- range .TableInfo.Columns}}
-	{{- if and (isTimeField .Name) (eq .Name "created_at")}}
-	m.CreatedAt = now
 	{{- end}}
-	{{- if and (isTimeField .Name) (eq .Name "updated_at")}}
-	m.UpdatedAt = now
+	{{- if .CreatedAtField}}
+	m.{{.CreatedAtField}} = now
 	{{- end}}
+	{{- if .UpdatedAtField}}
+	m.{{.UpdatedAtField}} = now
 	{{- end}}
 	return nil
 }
 
 // BeforeUpdate handles the before update hook
 func (m *{{.StructName}}) BeforeUpdate() error {
-	{{- range .TableInfo.Columns}}
-	{{- if and (isTimeField .Name) (eq .Name "updated_at")}}
-	m.UpdatedAt = time.Now()
-	{{- end}}
+	{{- if .UpdatedAtField}}
+	m.{{.UpdatedAtField}} = time.Now()
 	{{- end}}
 	return nil
 }
+{{- end}}
 `
 
 // 模型注册模板
-const modelsTemplate = `// Code generated by gom at {{.Timestamp}}. DO NOT EDIT.
+const modelsTemplate = `{{.Header}}
+
 package {{.PackageName}}
 
 // RegisterModels registers all models
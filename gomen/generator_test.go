@@ -0,0 +1,97 @@
+package gomen
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSchema serves table infos to the generator without a database
+type fakeSchema map[string]*define.TableInfo
+
+func (f fakeSchema) GetTables(pattern string) ([]string, error) {
+	return sortedKeys(f), nil
+}
+
+func (f fakeSchema) GetTableInfo(table string) (*define.TableInfo, error) {
+	return f[table], nil
+}
+
+func (f fakeSchema) Close() error { return nil }
+
+func userSchema() fakeSchema {
+	return fakeSchema{"users": {TableName: "users", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{
+		{Name: "id", DataType: "int64", IsPrimaryKey: true},
+		{Name: "user_name", DataType: "string"},
+		{Name: "nickname", DataType: "string", IsNullable: true},
+		{Name: "created_at", DataType: "time.Time"},
+	}}}
+}
+
+var blanks = regexp.MustCompile(`[ \t]+`)
+
+// compact collapses the alignment gofmt adds so that assertions can match single spaces
+func compact(code string) string {
+	return blanks.ReplaceAllString(code, " ")
+}
+
+func TestGenerateTableStructColumns(t *testing.T) {
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom", OutputDir: t.TempDir()}, db: userSchema()}
+	var buf bytes.Buffer
+	require.NoError(t, g.GenerateTableStruct("users", &buf))
+	_, err := parser.ParseFile(token.NewFileSet(), "users.go", buf.String(), 0)
+	require.NoError(t, err, buf.String())
+	code := compact(buf.String())
+	assert.Contains(t, code, "Nickname *sql.NullString")
+	assert.Contains(t, code, "UserName define.Column[string]")
+	assert.Contains(t, code, `Nickname: define.Col[string]("nickname"),`)
+	assert.Contains(t, code, `CreatedAt: define.Col[time.Time]("created_at"),`)
+	assert.Contains(t, code, `return []string{"id", "user_name", "nickname", "created_at"}`)
+	assert.Contains(t, code, "var UsersCols = usersColumns{")
+}
+
+func TestGenerateFilesAndCheck(t *testing.T) {
+	dir := t.TempDir()
+	schema := userSchema()
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom", OutputDir: dir}, db: schema}
+
+	files, err := g.GenerateFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gomen.go", "users.go"}, sortedFileNames(files))
+	assert.Contains(t, string(files["users.go"]), "import (\n\t\"database/sql\"\n\t\"time\"\n\n\t\"github.com/kmlixh/gom/v4/define\"\n)")
+	assert.NotContains(t, string(files["users.go"]), "encoding/json")
+
+	drifts, err := g.Check(files)
+	require.NoError(t, err)
+	assert.Equal(t, []Drift{{File: "gomen.go", Reason: "missing"}, {File: "users.go", Reason: "missing"}}, drifts)
+
+	require.NoError(t, g.WriteFiles(files))
+	drifts, err = g.Check(files)
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	// 表结构变化、表被删除后，已生成的文件与新的结果不一致
+	schema["users"].Columns = schema["users"].Columns[:2]
+	schema["user_orders"] = &define.TableInfo{TableName: "user_orders", Columns: []define.ColumnInfo{{Name: "id", DataType: "int64"}}}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "legacy.go"), []byte(generatedHeader+"\n\npackage models\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "helpers.go"), []byte("package models\n"), 0644))
+
+	files, err = g.GenerateFiles()
+	require.NoError(t, err)
+	drifts, err = g.Check(files)
+	require.NoError(t, err)
+	assert.Equal(t, []Drift{
+		{File: "gomen.go", Reason: "changed"},
+		{File: "user_orders.go", Reason: "missing"},
+		{File: "users.go", Reason: "changed"},
+		{File: "legacy.go", Reason: "stale"},
+	}, drifts)
+}
@@ -0,0 +1,259 @@
+package gomen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// 关联类型，写入关联字段 relation 标签的第一项
+const (
+	RelationBelongsTo  = "belongs_to"   // 本表外键引用的父表记录，字段类型为 *Parent
+	RelationHasOne     = "has_one"      // 通过唯一外键引用本表的子表记录，字段类型为 *Child
+	RelationHasMany    = "has_many"     // 通过外键引用本表的子表记录，字段类型为 []Child
+	RelationManyToMany = "many_to_many" // 通过纯关联表连接的另一个表的记录，字段类型为 []Other
+)
+
+// relation 由外键推断出的关联
+type relation struct {
+	Kind   string // 关联类型
+	Field  string // 字段名，已应用配置
+	Target string // 关联的结构体名
+	Tag    string // relation 标签的值
+	// 同名时用于区分的后缀，如 sender_id 对应 BySender
+	qualifier string
+}
+
+// buildRelations 读取 tables 的外键，为每个表推断关联字段；只连接 tables 中的表
+// 只由两个外键的列组成的表视为多对多的关联表，不为它生成一对多关联
+func (g *Generator) buildRelations(tables []string) (map[string][]*relation, error) {
+	infos := make(map[string]*define.TableInfo, len(tables))
+	for _, table := range tables {
+		info, err := g.db.GetTableInfo(table)
+		if err != nil {
+			return nil, fmt.Errorf("获取表 %s 的信息失败: %v", table, err)
+		}
+		infos[table] = info
+	}
+	// resolve 把外键引用的表名转换为 tables 中的表名，引用的表不在 tables 中时返回空
+	resolve := func(from, ref string) string {
+		if _, ok := infos[ref]; ok {
+			return ref
+		}
+		if i := strings.LastIndexByte(from, '.'); i >= 0 {
+			if _, ok := infos[from[:i+1]+ref]; ok {
+				return from[:i+1] + ref
+			}
+		}
+		return ""
+	}
+
+	relations := make(map[string][]*relation)
+	add := func(table string, rel *relation) {
+		relations[table] = append(relations[table], rel)
+	}
+	for _, table := range tables {
+		info := infos[table]
+		child := g.structName(table)
+		if isJoinTable(info, func(ref string) string { return resolve(table, ref) }) {
+			a, b := info.ForeignKeys[0], info.ForeignKeys[1]
+			left, right := resolve(table, a.RefTable), resolve(table, b.RefTable)
+			add(left, &relation{
+				Kind:      RelationManyToMany,
+				Field:     plural(fkFieldName(g.options.Config, b, g.structName(right))),
+				Target:    g.structName(right),
+				Tag:       manyToManyTag(info.TableName, a, b),
+				qualifier: child,
+			})
+			add(right, &relation{
+				Kind:      RelationManyToMany,
+				Field:     plural(fkFieldName(g.options.Config, a, g.structName(left))),
+				Target:    g.structName(left),
+				Tag:       manyToManyTag(info.TableName, b, a),
+				qualifier: child,
+			})
+			continue
+		}
+		for _, fk := range info.ForeignKeys {
+			parent := resolve(table, fk.RefTable)
+			if parent == "" {
+				continue
+			}
+			name := fkFieldName(g.options.Config, fk, g.structName(parent))
+			keys := fmt.Sprintf("foreignKey:%s,references:%s", strings.Join(fk.Columns, "|"), strings.Join(fk.RefColumns, "|"))
+			add(table, &relation{
+				Kind:   RelationBelongsTo,
+				Field:  name,
+				Target: g.structName(parent),
+				Tag:    RelationBelongsTo + "," + keys,
+			})
+			kind, field := RelationHasMany, plural(child)
+			if uniqueColumns(info, fk.Columns) {
+				kind, field = RelationHasOne, child
+			}
+			add(parent, &relation{Kind: kind, Field: field, Target: child, Tag: kind + "," + keys, qualifier: "By" + name})
+		}
+	}
+
+	for table, list := range relations {
+		resolved, err := g.nameRelations(table, infos[table], list)
+		if err != nil {
+			return nil, err
+		}
+		relations[table] = resolved
+	}
+	return relations, nil
+}
+
+// nameRelations 为同名的关联加上后缀区分，再应用配置中的重命名和跳过，最后检查与列字段是否重名
+func (g *Generator) nameRelations(table string, info *define.TableInfo, list []*relation) ([]*relation, error) {
+	count := make(map[string]int)
+	for _, rel := range list {
+		count[rel.Field]++
+	}
+	for _, rel := range list {
+		if count[rel.Field] > 1 && rel.qualifier != "" {
+			rel.Field += rel.qualifier
+		}
+	}
+
+	var result []*relation
+	for _, rel := range list {
+		if override := g.options.Config.relation(table, rel.Field); override != nil {
+			if override.Skip {
+				continue
+			}
+			if override.FieldName != "" {
+				rel.Field = override.FieldName
+			}
+		}
+		result = append(result, rel)
+	}
+
+	names := make(map[string]bool)
+	for _, field := range g.buildFields(table, info) {
+		names[field.Name] = true
+	}
+	for _, rel := range result {
+		if names[rel.Field] {
+			return nil, fmt.Errorf("表 %s 的关联字段 %s 与其他字段重名，请在配置文件的 relations 中重命名或跳过", table, rel.Field)
+		}
+		names[rel.Field] = true
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Field < result[j].Field })
+	return result, nil
+}
+
+// relationFields 把关联转换为结构体字段；关联字段标记为 gom:"-"（或 db:"-"），不参与读写
+func (g *Generator) relationFields(relations []*relation) []*fieldData {
+	var fields []*fieldData
+	for _, rel := range relations {
+		field := &fieldData{Name: rel.Field, Type: "*" + rel.Target}
+		if rel.Kind == RelationHasMany || rel.Kind == RelationManyToMany {
+			field.Type = "[]" + rel.Target
+		}
+		var tags []string
+		if g.options.TagStyle == "gom" {
+			tags = append(tags, `gom:"-"`)
+		}
+		if g.options.TagStyle != "gom" || g.options.GenerateDB {
+			tags = append(tags, `db:"-"`)
+		}
+		tags = append(tags, fmt.Sprintf(`relation:"%s"`, rel.Tag))
+		if config := g.options.Config; config != nil {
+			for _, tag := range config.Tags {
+				tags = append(tags, fmt.Sprintf(`%s:"%s,omitempty"`, tag, snakeCase(rel.Field)))
+			}
+		}
+		field.Tags = strings.Join(tags, " ")
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// isJoinTable 表是否为纯关联表：恰好有两个引用已生成表的外键，且所有列都属于这两个外键
+func isJoinTable(info *define.TableInfo, resolve func(ref string) string) bool {
+	if len(info.ForeignKeys) != 2 {
+		return false
+	}
+	covered := make(map[string]bool)
+	for _, fk := range info.ForeignKeys {
+		if resolve(fk.RefTable) == "" {
+			return false
+		}
+		for _, column := range fk.Columns {
+			covered[column] = true
+		}
+	}
+	for _, col := range info.Columns {
+		if !covered[col.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// uniqueColumns columns 是否为表的主键或唯一索引，此时一个父记录最多对应一个子记录
+func uniqueColumns(info *define.TableInfo, columns []string) bool {
+	if sameColumns(info.PrimaryKeys, columns) {
+		return true
+	}
+	for _, index := range info.Indexes {
+		if index.Unique && sameColumns(index.Columns, columns) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameColumns 两组列是否相同，不考虑顺序
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) || len(a) == 0 {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, column := range a {
+		set[column] = true
+	}
+	for _, column := range b {
+		if !set[column] {
+			return false
+		}
+	}
+	return true
+}
+
+// fkFieldName 返回外键对应的字段名：单列外键 user_id 对应 User，其他外键使用引用表的结构体名
+func fkFieldName(config *Config, fk define.ForeignKeyInfo, target string) string {
+	if len(fk.Columns) == 1 {
+		if name := strings.TrimSuffix(fk.Columns[0], "_id"); name != fk.Columns[0] && name != "" {
+			return config.goName(name)
+		}
+	}
+	return target
+}
+
+// manyToManyTag 返回多对多关联的标签，from 为关联表中引用本表的外键，to 为引用另一个表的外键
+func manyToManyTag(joinTable string, from, to define.ForeignKeyInfo) string {
+	return fmt.Sprintf("%s,joinTable:%s,foreignKey:%s,joinForeignKey:%s,references:%s,joinReferences:%s",
+		RelationManyToMany, joinTable,
+		strings.Join(from.RefColumns, "|"), strings.Join(from.Columns, "|"),
+		strings.Join(to.RefColumns, "|"), strings.Join(to.Columns, "|"))
+}
+
+// plural 返回英文名称的复数形式，已是复数（以 s 结尾）的名称保持不变
+func plural(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	case strings.HasSuffix(lower, "s"):
+		return name
+	case len(lower) > 1 && strings.HasSuffix(lower, "y") && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
@@ -0,0 +1,109 @@
+package gomen
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shopSchema() fakeSchema {
+	id := define.ColumnInfo{Name: "id", DataType: "int64", IsPrimaryKey: true, IsAutoIncrement: true}
+	return fakeSchema{
+		"user": {TableName: "user", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{id, {Name: "name", DataType: "string"}}},
+		"profile": {TableName: "profile", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{id, {Name: "user_id", DataType: "int64"}},
+			Indexes:     []define.IndexInfo{{Name: "uk_user", Columns: []string{"user_id"}, Unique: true}},
+			ForeignKeys: []define.ForeignKeyInfo{{Name: "fk_profile_user", Columns: []string{"user_id"}, RefTable: "user", RefColumns: []string{"id"}}}},
+		"order": {TableName: "order", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{id, {Name: "user_id", DataType: "int64"}, {Name: "warehouse_id", DataType: "int64"}},
+			ForeignKeys: []define.ForeignKeyInfo{
+				{Name: "fk_order_user", Columns: []string{"user_id"}, RefTable: "user", RefColumns: []string{"id"}},
+				{Name: "fk_order_warehouse", Columns: []string{"warehouse_id"}, RefTable: "warehouse", RefColumns: []string{"id"}},
+			}},
+		"message": {TableName: "message", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{id, {Name: "sender_id", DataType: "int64"}, {Name: "recipient_id", DataType: "int64"}},
+			ForeignKeys: []define.ForeignKeyInfo{
+				{Name: "fk_message_sender", Columns: []string{"sender_id"}, RefTable: "user", RefColumns: []string{"id"}},
+				{Name: "fk_message_recipient", Columns: []string{"recipient_id"}, RefTable: "user", RefColumns: []string{"id"}},
+			}},
+		"role": {TableName: "role", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{id, {Name: "name", DataType: "string"}}},
+		"user_role": {TableName: "user_role", PrimaryKeys: []string{"user_id", "role_id"}, Columns: []define.ColumnInfo{
+			{Name: "user_id", DataType: "int64", IsPrimaryKey: true}, {Name: "role_id", DataType: "int64", IsPrimaryKey: true}},
+			ForeignKeys: []define.ForeignKeyInfo{
+				{Name: "fk_user_role_user", Columns: []string{"user_id"}, RefTable: "user", RefColumns: []string{"id"}},
+				{Name: "fk_user_role_role", Columns: []string{"role_id"}, RefTable: "role", RefColumns: []string{"id"}},
+			}},
+	}
+}
+
+func generateWithRelations(t *testing.T, config *Config) map[string]string {
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom", Relations: true, Config: config}, db: shopSchema()}
+	files, err := g.GenerateFiles()
+	require.NoError(t, err)
+	code := make(map[string]string, len(files))
+	for name, content := range files {
+		_, err := parser.ParseFile(token.NewFileSet(), name, content, 0)
+		require.NoError(t, err, string(content))
+		code[name] = compact(string(content))
+	}
+	return code
+}
+
+func TestGenerateRelations(t *testing.T) {
+	code := generateWithRelations(t, &Config{Tags: []string{"json"}})
+
+	user := code["user.go"]
+	assert.Contains(t, user, "Profile *Profile `gom:\"-\" relation:\"has_one,foreignKey:user_id,references:id\" json:\"profile,omitempty\"`")
+	assert.Contains(t, user, "Orders []Order `gom:\"-\" relation:\"has_many,foreignKey:user_id,references:id\" json:\"orders,omitempty\"`")
+	assert.Contains(t, user, "MessagesBySender []Message `gom:\"-\" relation:\"has_many,foreignKey:sender_id,references:id\"")
+	assert.Contains(t, user, "MessagesByRecipient []Message `gom:\"-\" relation:\"has_many,foreignKey:recipient_id,references:id\"")
+	assert.Contains(t, user, "Roles []Role `gom:\"-\" relation:\"many_to_many,joinTable:user_role,foreignKey:id,joinForeignKey:user_id,references:id,joinReferences:role_id\"")
+	assert.NotContains(t, user, "UserRoles")
+	// 关联字段不是列
+	assert.Contains(t, user, `return []string{"id", "name"}`)
+
+	assert.Contains(t, code["order.go"], "User *User `gom:\"-\" relation:\"belongs_to,foreignKey:user_id,references:id\"")
+	assert.NotContains(t, code["order.go"], "Warehouse *", "warehouse 不在生成的表中")
+	assert.Contains(t, code["message.go"], "Sender *User `gom:\"-\" relation:\"belongs_to,foreignKey:sender_id,references:id\"")
+	assert.Contains(t, code["role.go"], "Users []User `gom:\"-\" relation:\"many_to_many,joinTable:user_role,foreignKey:id,joinForeignKey:role_id,references:id,joinReferences:user_id\"")
+	assert.NotContains(t, code["user_role.go"], "relation:")
+
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "db", Relations: true}, db: shopSchema()}
+	files, err := g.GenerateFiles()
+	require.NoError(t, err)
+	assert.Contains(t, compact(string(files["order.go"])), "User *User `db:\"-\" relation:\"belongs_to,foreignKey:user_id,references:id\"`")
+}
+
+func TestGenerateRelationsWithConfig(t *testing.T) {
+	code := generateWithRelations(t, &Config{Tables: map[string]*TableConfig{
+		"user": {Relations: map[string]*RelationConfig{
+			"Orders":              {FieldName: "PlacedOrders"},
+			"MessagesByRecipient": {Skip: true},
+		}},
+	}})
+	assert.Contains(t, code["user.go"], "PlacedOrders []Order")
+	assert.NotContains(t, code["user.go"], "MessagesByRecipient")
+	assert.Contains(t, code["user.go"], "MessagesBySender []Message")
+
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom", Relations: true, Config: &Config{Tables: map[string]*TableConfig{
+		"user": {Relations: map[string]*RelationConfig{"Orders": {FieldName: "Name"}}},
+	}}}, db: shopSchema()}
+	_, err := g.GenerateFiles()
+	assert.ErrorContains(t, err, "关联字段 Name 与其他字段重名")
+}
+
+func TestGenerateWithoutRelations(t *testing.T) {
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom"}, db: shopSchema()}
+	files, err := g.GenerateFiles()
+	require.NoError(t, err)
+	assert.NotContains(t, string(files["user.go"]), "relation:")
+}
+
+func TestPlural(t *testing.T) {
+	for name, want := range map[string]string{
+		"Order": "Orders", "Users": "Users", "Address": "Addresses", "Box": "Boxes", "Category": "Categories", "Day": "Days", "Batch": "Batches",
+	} {
+		assert.Equal(t, want, plural(name), name)
+	}
+}
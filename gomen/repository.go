@@ -0,0 +1,315 @@
+package gomen
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// repoField 仓库方法参数对应的列
+type repoField struct {
+	Name   string // 字段名
+	Column string // 列名
+	Type   string // 参数类型
+	Param  string // 参数名
+	Auto   bool   // 是否自增
+}
+
+// repoFinder 由唯一索引生成的查询方法
+type repoFinder struct {
+	Name   string // 方法名，如 FindByEmail
+	Fields []*repoField
+}
+
+// repoFileName 返回表的仓库文件名，如 users.go 对应 users_repo.go
+func (g *Generator) repoFileName(table string) string {
+	return strings.TrimSuffix(g.fileName(table), ".go") + "_repo.go"
+}
+
+// GenerateRepository 生成单个表的仓库：按主键读写的 CRUD 方法、由唯一索引生成的 FindBy 方法，以及便于 mock 的接口
+// 没有主键的表只生成 Insert、List 和 FindBy 方法；视图不生成 Insert、Update、Delete 和 Upsert 方法
+func (g *Generator) GenerateRepository(tableName string, writer io.Writer) error {
+	tableInfo, err := g.db.GetTableInfo(tableName)
+	if err != nil {
+		return fmt.Errorf("获取表信息失败: %v", err)
+	}
+
+	fields := g.buildFields(tableName, tableInfo)
+	autoIncrement := make(map[string]bool)
+	for _, col := range tableInfo.Columns {
+		autoIncrement[col.Name] = col.IsAutoIncrement
+	}
+	byColumn := make(map[string]*fieldData, len(fields))
+	for _, field := range fields {
+		byColumn[field.Column] = field
+	}
+	repoFields := func(columns []string) []*repoField {
+		var result []*repoField
+		for _, column := range columns {
+			field, ok := byColumn[column]
+			if !ok {
+				return nil
+			}
+			result = append(result, &repoField{Name: field.Name, Column: column, Type: field.ColumnType, Param: paramName(field.Name), Auto: autoIncrement[column]})
+		}
+		return result
+	}
+
+	keys := repoFields(tableInfo.PrimaryKeys)
+	// 单列自增整数主键在插入后回填
+	var autoKey *repoField
+	if len(keys) == 1 && keys[0].Auto && strings.HasPrefix(keys[0].Type, "int") {
+		autoKey = keys[0]
+	}
+	var finders []*repoFinder
+	seen := make(map[string]bool)
+	for _, index := range tableInfo.Indexes {
+		if !index.Unique || index.Primary {
+			continue
+		}
+		indexFields := repoFields(index.Columns)
+		if len(indexFields) == 0 {
+			continue
+		}
+		var names []string
+		for _, field := range indexFields {
+			names = append(names, field.Name)
+		}
+		name := "FindBy" + strings.Join(names, "And")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		finders = append(finders, &repoFinder{Name: name, Fields: indexFields})
+	}
+
+	// 参数类型用到的包
+	var typed []*fieldData
+	for _, field := range keys {
+		typed = append(typed, &fieldData{Type: field.Type})
+	}
+	for _, finder := range finders {
+		for _, field := range finder.Fields {
+			typed = append(typed, &fieldData{Type: field.Type})
+		}
+	}
+	required := []string{"context", "github.com/kmlixh/gom/v4"}
+	if len(keys) > 1 {
+		required = append(required, "github.com/kmlixh/gom/v4/define")
+	}
+	stdImports, imports := fileImports(typed, required...)
+
+	data := struct {
+		Header      string
+		PackageName string
+		StructName  string
+		TableName   string
+		Keys        []*repoField
+		AutoKey     *repoField
+		Finders     []*repoFinder
+		ReadOnly    bool
+		StdImports  []string
+		Imports     []string
+	}{
+		Header:      generatedHeader,
+		PackageName: g.options.PackageName,
+		StructName:  g.structName(tableName),
+		TableName:   tableInfo.TableName,
+		Keys:        keys,
+		AutoKey:     autoKey,
+		Finders:     finders,
+		ReadOnly:    tableInfo.IsView,
+		StdImports:  stdImports,
+		Imports:     imports,
+	}
+
+	tmpl, err := template.New("repository").Funcs(template.FuncMap{
+		"params": params,
+	}).Parse(repositoryTemplate)
+	if err != nil {
+		return fmt.Errorf("解析模板失败: %v", err)
+	}
+	return executeTemplate(tmpl, data, writer)
+}
+
+// params 生成参数列表，如 tenantID int64, email string
+func params(fields []*repoField) string {
+	list := make([]string, len(fields))
+	for i, field := range fields {
+		list[i] = field.Param + " " + field.Type
+	}
+	return strings.Join(list, ", ")
+}
+
+// reservedParams 生成的方法中已经使用的变量名
+var reservedParams = map[string]bool{"ctx": true, "m": true, "r": true, "list": true, "keys": true, "key": true, "cond": true, "match": true, "err": true}
+
+// paramName 把字段名转换为参数名，开头的缩写整体小写，如 ID 转换为 id，URLPath 转换为 urlPath
+func paramName(field string) string {
+	r := []rune(field)
+	upper := 0
+	for upper < len(r) && unicode.IsUpper(r[upper]) {
+		upper++
+	}
+	if upper > 1 && upper < len(r) {
+		// 最后一个大写字母属于下一个单词
+		upper--
+	}
+	for i := 0; i < upper; i++ {
+		r[i] = unicode.ToLower(r[i])
+	}
+	name := string(r)
+	if token.IsKeyword(name) || reservedParams[name] {
+		name += "Value"
+	}
+	return name
+}
+
+// 仓库模板
+const repositoryTemplate = `{{.Header}}
+
+package {{.PackageName}}
+
+import (
+{{range .StdImports}}	"{{.}}"
+{{end}}{{if and .StdImports .Imports}}
+{{end}}{{range .Imports}}	"{{.}}"
+{{end}})
+{{- $key := "" }}
+{{- if gt (len .Keys) 1}}
+{{- $key = printf "%sKey" .StructName}}
+
+// {{$key}} is the primary key of {{.TableName}}
+type {{$key}} struct {
+	{{- range .Keys}}
+	{{.Name}} {{.Type}}
+	{{- end}}
+}
+{{- else if .Keys}}
+{{- $key = (index .Keys 0).Type}}
+{{- end}}
+
+// {{.StructName}}Repository is the data access interface of {{.TableName}}, implemented by {{.StructName}}Repo
+type {{.StructName}}Repository interface {
+	{{- if .Keys}}
+	Get(ctx context.Context, {{params .Keys}}) (*{{.StructName}}, error)
+	GetMany(ctx context.Context, keys ...{{$key}}) ([]{{.StructName}}, error)
+	{{- end}}
+	{{- if not .ReadOnly}}
+	Insert(ctx context.Context, m *{{.StructName}}) error
+	{{- end}}
+	{{- if and .Keys (not .ReadOnly)}}
+	Update(ctx context.Context, m *{{.StructName}}) error
+	Delete(ctx context.Context, {{params .Keys}}) error
+	Upsert(ctx context.Context, m *{{.StructName}}) error
+	{{- end}}
+	List(ctx context.Context, opts gom.ListOptions) ([]{{.StructName}}, error)
+	{{- range .Finders}}
+	{{.Name}}(ctx context.Context, {{params .Fields}}) (*{{$.StructName}}, error)
+	{{- end}}
+}
+
+// {{.StructName}}Repo reads and writes {{.TableName}} through gom
+type {{.StructName}}Repo struct {
+	db *gom.DB
+}
+
+var _ {{.StructName}}Repository = (*{{.StructName}}Repo)(nil)
+
+// New{{.StructName}}Repo creates a repository of {{.TableName}}
+func New{{.StructName}}Repo(db *gom.DB) *{{.StructName}}Repo {
+	return &{{.StructName}}Repo{db: db}
+}
+
+func (r *{{.StructName}}Repo) chain(ctx context.Context) *gom.Chain {
+	return r.db.Chain().WithContext(ctx).Table("{{.TableName}}")
+}
+{{- if .Keys}}
+
+// Get returns the row with the given primary key, or sql.ErrNoRows
+func (r *{{.StructName}}Repo) Get(ctx context.Context, {{params .Keys}}) (*{{.StructName}}, error) {
+	var m {{.StructName}}
+	if err := r.chain(ctx){{range .Keys}}.Eq("{{.Column}}", {{.Param}}){{end}}.First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetMany returns the rows with the given primary keys
+func (r *{{.StructName}}Repo) GetMany(ctx context.Context, keys ...{{$key}}) ([]{{.StructName}}, error) {
+	var list []{{.StructName}}
+	if len(keys) == 0 {
+		return list, nil
+	}
+	{{- if gt (len .Keys) 1}}
+	var cond *define.Condition
+	for _, key := range keys {
+		match := define.Eq("{{(index .Keys 0).Column}}", key.{{(index .Keys 0).Name}})
+		{{- range $i, $field := .Keys}}{{if $i}}.And(define.Eq("{{$field.Column}}", key.{{$field.Name}})){{end}}{{end}}
+		if cond == nil {
+			cond = match
+		} else {
+			cond.Or(match)
+		}
+	}
+	err := r.chain(ctx).Where2(cond).List(&list).Error
+	{{- else}}
+	err := r.chain(ctx).In("{{(index .Keys 0).Column}}", keys).List(&list).Error
+	{{- end}}
+	return list, err
+}
+{{- end}}
+{{- if not .ReadOnly}}
+
+// Insert inserts m{{if .AutoKey}} and sets the generated {{.AutoKey.Name}} on it{{end}}
+func (r *{{.StructName}}Repo) Insert(ctx context.Context, m *{{.StructName}}) error {
+	{{- if .AutoKey}}
+	result := r.chain(ctx).Insert(m)
+	if result.Error == nil && result.ID > 0 {
+		m.{{.AutoKey.Name}} = {{if eq .AutoKey.Type "int64"}}result.ID{{else}}{{.AutoKey.Type}}(result.ID){{end}}
+	}
+	return result.Error
+	{{- else}}
+	return r.chain(ctx).Insert(m).Error
+	{{- end}}
+}
+{{- end}}
+{{- if and .Keys (not .ReadOnly)}}
+
+// Update writes the fields of m to the row with the primary key of m
+func (r *{{.StructName}}Repo) Update(ctx context.Context, m *{{.StructName}}) error {
+	return r.chain(ctx){{range .Keys}}.Eq("{{.Column}}", m.{{.Name}}){{end}}.From(m).Update().Error
+}
+
+// Delete deletes the row with the given primary key
+func (r *{{.StructName}}Repo) Delete(ctx context.Context, {{params .Keys}}) error {
+	return r.chain(ctx){{range .Keys}}.Eq("{{.Column}}", {{.Param}}){{end}}.Delete().Error
+}
+
+// Upsert inserts m, or updates the row with the same primary key
+func (r *{{.StructName}}Repo) Upsert(ctx context.Context, m *{{.StructName}}) error {
+	return r.chain(ctx).Upsert(m{{range .Keys}}, "{{.Column}}"{{end}}).Error
+}
+{{- end}}
+
+// List returns the rows matching opts
+func (r *{{.StructName}}Repo) List(ctx context.Context, opts gom.ListOptions) ([]{{.StructName}}, error) {
+	var list []{{.StructName}}
+	err := r.chain(ctx).ApplyListOptions(opts).List(&list).Error
+	return list, err
+}
+{{- range .Finders}}
+
+// {{.Name}} returns the row matching the unique index on {{range $i, $field := .Fields}}{{if $i}}, {{end}}{{$field.Column}}{{end}}, or sql.ErrNoRows
+func (r *{{$.StructName}}Repo) {{.Name}}(ctx context.Context, {{params .Fields}}) (*{{$.StructName}}, error) {
+	var m {{$.StructName}}
+	if err := r.chain(ctx){{range .Fields}}.Eq("{{.Column}}", {{.Param}}){{end}}.First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+{{- end}}
+`
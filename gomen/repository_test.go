@@ -0,0 +1,96 @@
+package gomen
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func repoSchema() fakeSchema {
+	return fakeSchema{
+		"users": {TableName: "users", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{
+			{Name: "id", DataType: "int64", IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "email", DataType: "string"},
+			{Name: "type", DataType: "string", IsNullable: true},
+			{Name: "created_at", DataType: "time.Time"},
+		}, Indexes: []define.IndexInfo{
+			{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+			{Name: "uk_email", Columns: []string{"email"}, Unique: true},
+			{Name: "uk_type_created", Columns: []string{"type", "created_at"}, Unique: true},
+			{Name: "idx_created", Columns: []string{"created_at"}},
+		}},
+		"user_roles": {TableName: "user_roles", PrimaryKeys: []string{"user_id", "role_id"}, Columns: []define.ColumnInfo{
+			{Name: "user_id", DataType: "int64", IsPrimaryKey: true},
+			{Name: "role_id", DataType: "int32", IsPrimaryKey: true},
+		}},
+		"logs": {TableName: "logs", Columns: []define.ColumnInfo{{Name: "msg", DataType: "string"}}},
+	}
+}
+
+func generateRepository(t *testing.T, table string) string {
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom", Config: &Config{Initialisms: []string{"ID"}}}, db: repoSchema()}
+	var buf bytes.Buffer
+	require.NoError(t, g.GenerateRepository(table, &buf))
+	_, err := parser.ParseFile(token.NewFileSet(), table+"_repo.go", buf.String(), 0)
+	require.NoError(t, err, buf.String())
+	return compact(buf.String())
+}
+
+func TestGenerateRepository(t *testing.T) {
+	code := generateRepository(t, "users")
+	assert.Contains(t, code, "type UsersRepository interface {")
+	assert.Contains(t, code, "var _ UsersRepository = (*UsersRepo)(nil)")
+	assert.Contains(t, code, "func NewUsersRepo(db *gom.DB) *UsersRepo {")
+	assert.Contains(t, code, "\"context\"\n \"time\"\n\n \"github.com/kmlixh/gom/v4\"\n)")
+	assert.Contains(t, code, `r.chain(ctx).Eq("id", id).First(&m)`)
+	assert.Contains(t, code, `r.chain(ctx).In("id", keys).List(&list)`)
+	assert.Contains(t, code, "m.ID = result.ID")
+	assert.Contains(t, code, `r.chain(ctx).Upsert(m, "id")`)
+	assert.Contains(t, code, "FindByEmail(ctx context.Context, email string) (*Users, error)")
+	assert.Contains(t, code, `FindByTypeAndCreatedAt(ctx context.Context, typeValue string, createdAt time.Time) (*Users, error)`)
+	assert.Contains(t, code, `.Eq("type", typeValue).Eq("created_at", createdAt).First(&m)`)
+	assert.NotContains(t, code, "FindByCreatedAt(")
+	assert.NotContains(t, code, "FindByID")
+}
+
+func TestGenerateRepositoryCompositeKey(t *testing.T) {
+	code := generateRepository(t, "user_roles")
+	assert.Contains(t, code, "type UserRolesKey struct {\n UserID int64\n RoleID int32\n}")
+	assert.Contains(t, code, "Get(ctx context.Context, userID int64, roleID int32) (*UserRoles, error)")
+	assert.Contains(t, code, "GetMany(ctx context.Context, keys ...UserRolesKey) ([]UserRoles, error)")
+	assert.Contains(t, code, `match := define.Eq("user_id", key.UserID).And(define.Eq("role_id", key.RoleID))`)
+	assert.Contains(t, code, `r.chain(ctx).Eq("user_id", m.UserID).Eq("role_id", m.RoleID).From(m).Update()`)
+	assert.Contains(t, code, `r.chain(ctx).Upsert(m, "user_id", "role_id")`)
+	assert.Contains(t, code, "return r.chain(ctx).Insert(m).Error")
+}
+
+func TestGenerateRepositoryWithoutKey(t *testing.T) {
+	code := generateRepository(t, "logs")
+	assert.Contains(t, code, "Insert(ctx context.Context, m *Logs) error")
+	assert.Contains(t, code, "List(ctx context.Context, opts gom.ListOptions) ([]Logs, error)")
+	for _, method := range []string{"Get(", "GetMany(", "Update(", "Delete(", "Upsert("} {
+		assert.NotContains(t, code, method)
+	}
+}
+
+func TestGenerateFilesWithRepository(t *testing.T) {
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom", Repository: true}, db: repoSchema()}
+	files, err := g.GenerateFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gomen.go", "logs.go", "logs_repo.go", "user_roles.go", "user_roles_repo.go", "users.go", "users_repo.go"}, sortedFileNames(files))
+	assert.Contains(t, string(files["users.go"]), "`gom:\"id,@\"`")
+	assert.Contains(t, string(files["user_roles.go"]), "`gom:\"role_id,!\"`")
+}
+
+func TestParamName(t *testing.T) {
+	for field, want := range map[string]string{
+		"ID": "id", "UserID": "userID", "URLPath": "urlPath", "Email": "email", "Type": "typeValue", "M": "mValue",
+	} {
+		assert.Equal(t, want, paramName(field), field)
+	}
+}
@@ -0,0 +1,165 @@
+package gomen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// Snapshot 表结构快照，可以代替数据库连接生成代码
+type Snapshot struct {
+	Dialect string              `json:"dialect"` // 数据库类型 (mysql/postgres)
+	Tables  []*define.TableInfo `json:"tables"`  // 表和视图的结构，按名称排序
+}
+
+// TakeSnapshot 读取匹配 pattern 的所有表结构；db 可以读取视图时也包括匹配的视图
+func TakeSnapshot(db SchemaReader, dialect, pattern string) (*Snapshot, error) {
+	schema, err := LoadSchema(db, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if lister, ok := db.(viewLister); ok {
+		views, err := listViews(lister, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, view := range views {
+			info, err := db.GetTableInfo(view)
+			if err != nil {
+				return nil, fmt.Errorf("获取视图 %s 的信息失败: %v", view, err)
+			}
+			schema[view] = info
+		}
+	}
+	snapshot := &Snapshot{Dialect: dialect, Tables: make([]*define.TableInfo, 0, len(schema))}
+	for _, name := range sortedKeys(schema) {
+		snapshot.Tables = append(snapshot.Tables, schema[name])
+	}
+	return snapshot, nil
+}
+
+// LoadSnapshot 读取 JSON 格式的快照文件
+func LoadSnapshot(file string) (*Snapshot, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("解析快照文件 %s 失败: %v", file, err)
+	}
+	return &snapshot, nil
+}
+
+// LoadDDLFile 解析建表脚本文件
+func LoadDDLFile(dialect, file string) (*Snapshot, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := ParseDDL(dialect, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析建表脚本 %s 失败: %v", file, err)
+	}
+	return snapshot, nil
+}
+
+// Write 以缩进的 JSON 格式写入快照
+func (s *Snapshot) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// GetTables 返回匹配 pattern 的表名，不包括视图，规则与连接数据库时一致：
+// MySQL 中 pattern 为 LIKE 模式；PostgreSQL 中 pattern 为 schema.table 格式，没有 schema 时为 public，* 匹配任意字符
+func (s *Snapshot) GetTables(pattern string) ([]string, error) {
+	return s.names(pattern, func(table *define.TableInfo) bool { return !table.IsView })
+}
+
+// GetViews 返回匹配 pattern 的视图名，不包括物化视图
+func (s *Snapshot) GetViews(pattern string) ([]string, error) {
+	return s.names(pattern, func(table *define.TableInfo) bool { return table.IsView && !table.IsMaterialized })
+}
+
+// GetMaterializedViews 返回匹配 pattern 的物化视图名
+func (s *Snapshot) GetMaterializedViews(pattern string) ([]string, error) {
+	return s.names(pattern, func(table *define.TableInfo) bool { return table.IsMaterialized })
+}
+
+// names 返回名称匹配 pattern 且满足 filter 的表名
+func (s *Snapshot) names(pattern string, filter func(*define.TableInfo) bool) ([]string, error) {
+	match, err := s.tableMatcher(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, table := range s.Tables {
+		if filter(table) && match(table.TableName) {
+			names = append(names, table.TableName)
+		}
+	}
+	return names, nil
+}
+
+// tableMatcher 把表名匹配模式转换为匹配函数
+func (s *Snapshot) tableMatcher(pattern string) (func(string) bool, error) {
+	if s.Dialect == "postgres" {
+		schema, table := "public", pattern
+		if parts := strings.Split(pattern, "."); len(parts) == 2 {
+			schema, table = parts[0], parts[1]
+		}
+		re, err := likePattern(schema + "." + table)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	if pattern == "" || pattern == "*" || pattern == "%" {
+		return func(string) bool { return true }, nil
+	}
+	re, err := likePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// likePattern 把 LIKE 模式转换为正则表达式：% 和 * 匹配任意字符，_ 匹配单个字符
+func likePattern(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%', '*':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// GetTableInfo 返回表结构的副本
+func (s *Snapshot) GetTableInfo(tableName string) (*define.TableInfo, error) {
+	for _, table := range s.Tables {
+		if table.TableName == tableName {
+			info := *table
+			return &info, nil
+		}
+	}
+	return nil, fmt.Errorf("快照中没有表 %s", tableName)
+}
+
+// Close 实现生成器的数据源接口，快照没有需要释放的资源
+func (s *Snapshot) Close() error {
+	return nil
+}
@@ -0,0 +1,108 @@
+package gomen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeSnapshotRoundTrip(t *testing.T) {
+	snapshot, err := TakeSnapshot(userSchema(), "mysql", "")
+	require.NoError(t, err)
+	require.Len(t, snapshot.Tables, 1)
+
+	file := filepath.Join(t.TempDir(), "schema.json")
+	var buf bytes.Buffer
+	require.NoError(t, snapshot.Write(&buf))
+	require.NoError(t, os.WriteFile(file, buf.Bytes(), 0644))
+
+	loaded, err := LoadSnapshot(file)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, loaded)
+
+	info, err := loaded.GetTableInfo("users")
+	require.NoError(t, err)
+	info.Columns = nil
+	assert.Len(t, loaded.Tables[0].Columns, 4, "GetTableInfo 返回副本")
+	_, err = loaded.GetTableInfo("orders")
+	assert.ErrorContains(t, err, "快照中没有表 orders")
+}
+
+func TestSnapshotGetTables(t *testing.T) {
+	tables := func(names ...string) []*define.TableInfo {
+		var infos []*define.TableInfo
+		for _, name := range names {
+			infos = append(infos, &define.TableInfo{TableName: name})
+		}
+		return infos
+	}
+
+	mysql := &Snapshot{Dialect: "mysql", Tables: tables("user_orders", "users", "userxs")}
+	for pattern, want := range map[string][]string{
+		"":       {"user_orders", "users", "userxs"},
+		"*":      {"user_orders", "users", "userxs"},
+		"user%":  {"user_orders", "users", "userxs"},
+		"user_s": {"userxs"},
+		"users":  {"users"},
+		"x":      nil,
+	} {
+		got, err := mysql.GetTables(pattern)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, pattern)
+	}
+
+	postgres := &Snapshot{Dialect: "postgres", Tables: tables("audit.events", "public.users", "public.posts")}
+	for pattern, want := range map[string][]string{
+		"":        nil,
+		"*":       {"public.users", "public.posts"},
+		"audit.*": {"audit.events"},
+		"*.*":     {"audit.events", "public.users", "public.posts"},
+		"p*":      {"public.posts"},
+	} {
+		got, err := postgres.GetTables(pattern)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, pattern)
+	}
+}
+
+func TestNewGeneratorOffline(t *testing.T) {
+	dir := t.TempDir()
+	ddl := filepath.Join(dir, "schema.sql")
+	require.NoError(t, os.WriteFile(ddl, []byte(mysqlDump), 0644))
+
+	g, err := NewGenerator(Options{Driver: "mysql", DDLFile: ddl, OutputDir: filepath.Join(dir, "models"), PackageName: "models", TagStyle: "gom"})
+	require.NoError(t, err)
+	files, err := g.GenerateFiles()
+	require.NoError(t, err)
+	require.NoError(t, g.Close())
+	assert.Equal(t, []string{"enums.go", "events.go", "gomen.go", "logs.go", "orders.go", "users.go"}, sortedFileNames(files))
+	assert.Contains(t, compact(string(files["users.go"])), "Status UsersStatus `gom:\"status\"`")
+
+	// 快照与脚本生成的代码一致
+	snapshot, err := LoadDDLFile("mysql", ddl)
+	require.NoError(t, err)
+	file := filepath.Join(dir, "schema.json")
+	out, err := os.Create(file)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.Write(out))
+	require.NoError(t, out.Close())
+
+	g, err = NewGenerator(Options{Snapshot: file, OutputDir: filepath.Join(dir, "models"), PackageName: "models", TagStyle: "gom"})
+	require.NoError(t, err)
+	assert.Equal(t, "mysql", g.options.Driver)
+	fromSnapshot, err := g.GenerateFiles()
+	require.NoError(t, err)
+	assert.Equal(t, files, fromSnapshot)
+
+	_, err = NewGenerator(Options{Driver: "postgres", Snapshot: file, OutputDir: dir, PackageName: "models"})
+	assert.ErrorContains(t, err, "快照的数据库类型为 mysql，与 postgres 不一致")
+	_, err = NewGenerator(Options{Driver: "mysql", Snapshot: file, DDLFile: ddl, OutputDir: dir, PackageName: "models"})
+	assert.ErrorContains(t, err, "snapshot and ddl file cannot be used together")
+	_, err = NewGenerator(Options{DDLFile: ddl, OutputDir: dir, PackageName: "models"})
+	assert.ErrorContains(t, err, "driver cannot be empty")
+}
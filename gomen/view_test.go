@@ -0,0 +1,84 @@
+package gomen
+
+import (
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func viewSnapshot() *Snapshot {
+	return &Snapshot{Dialect: "postgres", Tables: []*define.TableInfo{
+		{TableName: "public.active_users", IsView: true, ViewDefinition: " SELECT id, name, created_at FROM users WHERE active;",
+			Columns: []define.ColumnInfo{
+				{Name: "id", DataType: "int64"},
+				{Name: "name", DataType: "string"},
+				{Name: "created_at", DataType: "time.Time"},
+			}},
+		{TableName: "public.users", PrimaryKeys: []string{"id"}, Columns: []define.ColumnInfo{
+			{Name: "id", DataType: "int64", IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", DataType: "string"},
+			{Name: "created_at", DataType: "time.Time"},
+		}},
+		{TableName: "report.monthly_sales", IsView: true, IsMaterialized: true, Columns: []define.ColumnInfo{
+			{Name: "month", DataType: "time.Time"},
+			{Name: "total", DataType: "int64"},
+		}, Indexes: []define.IndexInfo{{Name: "monthly_sales_month_key", Columns: []string{"month"}, Unique: true}}},
+	}}
+}
+
+func TestSnapshotViews(t *testing.T) {
+	snapshot := viewSnapshot()
+	tables, err := snapshot.GetTables("*.*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"public.users"}, tables)
+	views, err := snapshot.GetViews("*.*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"public.active_users"}, views)
+	views, err = snapshot.GetMaterializedViews("*")
+	require.NoError(t, err)
+	assert.Empty(t, views)
+	views, err = snapshot.GetMaterializedViews("report.*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"report.monthly_sales"}, views)
+
+	// Snapshot 可以读取视图，重新生成的快照包括视图
+	taken, err := TakeSnapshot(snapshot, "postgres", "*.*")
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, taken)
+}
+
+func TestGenerateViewModels(t *testing.T) {
+	g := &Generator{options: Options{PackageName: "models", TagStyle: "gom", Pattern: "*.*", Repository: true}, db: viewSnapshot()}
+	files, err := g.GenerateFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gomen.go", "users.go", "users_repo.go"}, sortedFileNames(files))
+
+	g.options.Views = true
+	files, err = g.GenerateFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"active_users.go", "active_users_repo.go", "gomen.go", "monthly_sales.go", "monthly_sales_repo.go",
+		"users.go", "users_repo.go"}, sortedFileNames(files))
+
+	view := compact(string(files["active_users.go"]))
+	assert.Contains(t, view, "func (m *ActiveUsers) ReadOnly() bool {\n return true\n}")
+	assert.NotContains(t, view, "BeforeCreate")
+	assert.NotContains(t, string(files["users.go"]), "ReadOnly")
+	assert.Contains(t, string(files["users.go"]), "BeforeCreate")
+
+	repo := compact(string(files["monthly_sales_repo.go"]))
+	assert.Contains(t, repo, "List(ctx context.Context, opts gom.ListOptions) ([]MonthlySales, error)")
+	assert.Contains(t, repo, "FindByMonth(ctx context.Context, month time.Time) (*MonthlySales, error)")
+	for _, method := range []string{"Insert(", "Update(", "Delete(", "Upsert("} {
+		assert.NotContains(t, repo, method)
+		assert.NotContains(t, string(files["active_users_repo.go"]), method)
+	}
+	assert.Contains(t, string(files["users_repo.go"]), "Insert(")
+	assert.Contains(t, string(files["gomen.go"]), "&ActiveUsers{}")
+
+	// 不能读取视图的数据源
+	g = &Generator{options: Options{PackageName: "models", TagStyle: "gom", Views: true}, db: userSchema()}
+	_, err = g.GenerateFiles()
+	assert.EqualError(t, err, "数据源不支持读取视图")
+}
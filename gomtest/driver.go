@@ -0,0 +1,206 @@
+package gomtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DriverName is the database/sql driver name registered by this package
+const DriverName = "gomtest"
+
+func init() {
+	sql.Register(DriverName, fakeDriver{})
+}
+
+// fakeDriver opens connections to the Mock registered under the DSN
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	mock := lookup(dsn)
+	if mock == nil {
+		return nil, fmt.Errorf("gomtest: no mock registered for dsn %q", dsn)
+	}
+	return &conn{mock: mock}, nil
+}
+
+// conn records every statement on its Mock
+type conn struct {
+	mock *Mock
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if _, _, err := c.mock.handle("BEGIN", nil, false); err != nil {
+		return nil, err
+	}
+	return &tx{conn: c}, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error { return nil }
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, _, err := c.mock.handle(query, args, true)
+	return rows, err
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	_, result, err := c.mock.handle(query, args, false)
+	return result, err
+}
+
+// CheckNamedValue keeps arguments as passed so recorded args match what the caller bound
+// Valuers and pointers are resolved the way real drivers see them; a nil pointer is NULL,
+// even when its type is a Valuer such as *sql.NullString
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	rv := reflect.ValueOf(nv.Value)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		nv.Value = nil
+		return nil
+	}
+	if valuer, ok := nv.Value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		nv.Value = v
+		return nil
+	}
+	if rv.Kind() == reflect.Ptr {
+		nv.Value = rv.Elem().Interface()
+	}
+	return nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+type tx struct {
+	conn *conn
+}
+
+func (t *tx) Commit() error {
+	_, _, err := t.conn.mock.handle("COMMIT", nil, false)
+	return err
+}
+
+func (t *tx) Rollback() error {
+	_, _, err := t.conn.mock.handle("ROLLBACK", nil, false)
+	return err
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+// result is the driver.Result of a scripted Exec
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r result) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+// rowsCursor iterates over a copy of scripted Rows
+type rowsCursor struct {
+	rows *Rows
+	pos  int
+}
+
+func (r *rowsCursor) Columns() []string { return r.rows.columns }
+func (r *rowsCursor) Close() error      { return nil }
+
+func (r *rowsCursor) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows.values) {
+		return io.EOF
+	}
+	copy(dest, r.rows.values[r.pos])
+	r.pos++
+	return nil
+}
+
+func (r *rowsCursor) ColumnTypeDatabaseTypeName(index int) string {
+	if index < len(r.rows.types) {
+		return strings.ToUpper(r.rows.types[index])
+	}
+	return ""
+}
+
+func (r *rowsCursor) ColumnTypeScanType(index int) reflect.Type {
+	if t := scanTypeForDatabaseType(r.ColumnTypeDatabaseTypeName(index)); t != nil {
+		return t
+	}
+	for _, row := range r.rows.values {
+		if row[index] != nil {
+			return reflect.TypeOf(row[index])
+		}
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+func (r *rowsCursor) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return true, true
+}
+
+// scanTypeForDatabaseType maps a declared column type to the Go type a real driver would scan
+func scanTypeForDatabaseType(name string) reflect.Type {
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+	switch strings.TrimSpace(name) {
+	case "":
+		return nil
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "INT2", "INT4", "INT8", "SERIAL", "BIGSERIAL":
+		return reflect.TypeOf(int64(0))
+	case "FLOAT", "DOUBLE", "REAL", "FLOAT4", "FLOAT8", "DOUBLE PRECISION":
+		return reflect.TypeOf(float64(0))
+	case "BOOL", "BOOLEAN":
+		return reflect.TypeOf(false)
+	case "DATE", "DATETIME", "TIMESTAMP", "TIMESTAMPTZ", "TIME":
+		return reflect.TypeOf(time.Time{})
+	case "BLOB", "BYTEA", "BINARY", "VARBINARY":
+		return reflect.TypeOf([]byte(nil))
+	default:
+		return reflect.TypeOf("")
+	}
+}
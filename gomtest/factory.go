@@ -0,0 +1,38 @@
+package gomtest
+
+import (
+	"database/sql"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/factory/mysql"
+	"github.com/kmlixh/gom/v4/factory/postgres"
+)
+
+// Driver names to pass to gom.Open together with Mock.DSN
+const (
+	MySQL    = "gomtest-mysql"
+	Postgres = "gomtest-postgres"
+)
+
+func init() {
+	define.RegisterFactory(MySQL, &factory{SQLFactory: &mysql.Factory{}})
+	define.RegisterFactory(Postgres, &factory{SQLFactory: &postgres.Factory{}})
+}
+
+// factory builds SQL with a real dialect factory but connects to the fake driver
+type factory struct {
+	define.SQLFactory
+}
+
+// Connect opens the Mock registered under dsn
+func (f *factory) Connect(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,355 @@
+// Package gomtest provides an in-memory database/sql driver for testing code built on gom
+// without a live database. Statements are built by the real MySQL or Postgres factory,
+// recorded by a Mock and answered from results scripted by SQL pattern:
+//
+//	mock := gomtest.New()
+//	defer mock.Close()
+//	mock.On("SELECT * FROM `users`").Return(gomtest.NewRows("id", "name").AddRow(1, "ann"))
+//	db, _ := gom.Open(gomtest.MySQL, mock.DSN(), nil)
+//	...
+//	mock.AssertStatements(t, "SELECT * FROM `users`")
+package gomtest
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Mock)
+	nextID     int
+)
+
+// lookup returns the Mock registered under dsn
+func lookup(dsn string) *Mock {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return registry[dsn]
+}
+
+// txStatements are recorded for transaction control and never fail unless scripted to
+var txStatements = map[string]bool{"BEGIN": true, "COMMIT": true, "ROLLBACK": true}
+
+// Statement is a statement received by the driver
+// Transactions are recorded as BEGIN, COMMIT and ROLLBACK statements
+type Statement struct {
+	SQL   string
+	Args  []interface{}
+	Query bool // Run with Query rather than Exec
+}
+
+// Mock records the statements of every connection opened with its DSN and answers them from scripts
+type Mock struct {
+	dsn        string
+	mu         sync.Mutex
+	strict     bool
+	scripts    []*Script
+	statements []Statement
+}
+
+// New creates a Mock and registers its DSN with the driver
+func New() *Mock {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	nextID++
+	m := &Mock{dsn: fmt.Sprintf("gomtest-%d", nextID)}
+	registry[m.dsn] = m
+	return m
+}
+
+// DSN returns the data source name to pass to gom.Open or sql.Open
+func (m *Mock) DSN() string {
+	return m.dsn
+}
+
+// Close unregisters the Mock; connections opened afterwards fail
+func (m *Mock) Close() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, m.dsn)
+}
+
+// Strict makes statements without a matching script fail
+// By default they succeed, queries with no rows and statements with no affected rows
+func (m *Mock) Strict() *Mock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.strict = true
+	return m
+}
+
+// On scripts the statements containing pattern
+// Matching ignores case and collapses whitespace. Scripts are tried in the order they were added
+func (m *Mock) On(pattern string) *Script {
+	want := normalize(pattern)
+	return m.addScript(pattern, func(query string) bool {
+		return strings.Contains(normalize(query), want)
+	})
+}
+
+// OnRegexp scripts the statements matching the regular expression expr
+func (m *Mock) OnRegexp(expr string) *Script {
+	re := regexp.MustCompile(expr)
+	return m.addScript(expr, re.MatchString)
+}
+
+func (m *Mock) addScript(pattern string, match func(string) bool) *Script {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := &Script{mock: m, pattern: pattern, match: match}
+	m.scripts = append(m.scripts, s)
+	return s
+}
+
+// Statements returns the recorded statements in execution order
+func (m *Mock) Statements() []Statement {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Statement(nil), m.statements...)
+}
+
+// Reset clears the recorded statements and the scripts
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statements = nil
+	m.scripts = nil
+}
+
+// handle records a statement and returns its scripted outcome
+func (m *Mock) handle(query string, args []driver.NamedValue, isQuery bool) (driver.Rows, driver.Result, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statements = append(m.statements, Statement{SQL: query, Args: values, Query: isQuery})
+
+	var script *Script
+	for _, s := range m.scripts {
+		if (s.times == 0 || s.used < s.times) && s.match(query) {
+			script = s
+			break
+		}
+	}
+	if script == nil {
+		if m.strict && !txStatements[query] {
+			return nil, nil, fmt.Errorf("gomtest: unexpected statement: %s", query)
+		}
+		if isQuery {
+			return &rowsCursor{rows: &Rows{}}, nil, nil
+		}
+		return nil, result{}, nil
+	}
+
+	script.used++
+	if script.err != nil {
+		return nil, nil, script.err
+	}
+	if isQuery {
+		rows := script.rows
+		if rows == nil {
+			rows = &Rows{}
+		}
+		return &rowsCursor{rows: rows}, nil, nil
+	}
+	return nil, result{lastInsertID: script.lastInsertID, rowsAffected: script.rowsAffected}, nil
+}
+
+// Script is the scripted outcome of the statements matching a pattern
+type Script struct {
+	mock         *Mock
+	pattern      string
+	match        func(string) bool
+	rows         *Rows
+	lastInsertID int64
+	rowsAffected int64
+	err          error
+	times        int
+	used         int
+}
+
+// Return answers matching queries with rows
+func (s *Script) Return(rows *Rows) *Script {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+	s.rows = rows
+	return s
+}
+
+// ReturnResult answers matching statements with the given insert id and affected row count
+func (s *Script) ReturnResult(lastInsertID, rowsAffected int64) *Script {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+	s.lastInsertID = lastInsertID
+	s.rowsAffected = rowsAffected
+	return s
+}
+
+// ReturnError makes matching statements fail with err
+func (s *Script) ReturnError(err error) *Script {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+	s.err = err
+	return s
+}
+
+// Times limits the script to the first n matching statements; 0 means unlimited
+func (s *Script) Times(n int) *Script {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+	s.times = n
+	return s
+}
+
+// Rows is a scripted result set
+type Rows struct {
+	columns []string
+	types   []string
+	values  [][]driver.Value
+}
+
+// NewRows creates an empty result set with the given columns
+func NewRows(columns ...string) *Rows {
+	return &Rows{columns: columns}
+}
+
+// WithTypes sets the database type names of the columns, such as "BIGINT" or "VARCHAR"
+// They decide the scan types gom sees; untyped columns use the type of their first non-nil value
+func (r *Rows) WithTypes(types ...string) *Rows {
+	r.types = types
+	return r
+}
+
+// AddRow appends a row; values are converted like database/sql arguments
+// It panics if the number of values does not match the columns or a value is not a valid driver value
+func (r *Rows) AddRow(values ...interface{}) *Rows {
+	if len(values) != len(r.columns) {
+		panic(fmt.Sprintf("gomtest: row has %d values for %d columns", len(values), len(r.columns)))
+	}
+	row := make([]driver.Value, len(values))
+	for i, v := range values {
+		converted, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			panic(fmt.Sprintf("gomtest: column %s: %v", r.columns[i], err))
+		}
+		row[i] = converted
+	}
+	r.values = append(r.values, row)
+	return r
+}
+
+// TestingT is the subset of testing.TB used by the assertion helpers
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertStatements checks that exactly the statements matching patterns ran, in order
+// Patterns match like On. Include "BEGIN", "COMMIT" or "ROLLBACK" to check transactions
+func (m *Mock) AssertStatements(t TestingT, patterns ...string) bool {
+	t.Helper()
+	statements := m.Statements()
+	if len(statements) != len(patterns) {
+		t.Errorf("gomtest: expected %d statements, got %d:\n%s", len(patterns), len(statements), describe(statements))
+		return false
+	}
+	for i, pattern := range patterns {
+		if !strings.Contains(normalize(statements[i].SQL), normalize(pattern)) {
+			t.Errorf("gomtest: statement %d does not match %q:\n%s", i, pattern, describe(statements))
+			return false
+		}
+	}
+	return true
+}
+
+// AssertStatementsInOrder checks that statements matching patterns ran in order, possibly with others in between
+func (m *Mock) AssertStatementsInOrder(t TestingT, patterns ...string) bool {
+	t.Helper()
+	statements := m.Statements()
+	next := 0
+	for _, statement := range statements {
+		if next < len(patterns) && strings.Contains(normalize(statement.SQL), normalize(patterns[next])) {
+			next++
+		}
+	}
+	if next < len(patterns) {
+		t.Errorf("gomtest: no statement matching %q in order:\n%s", patterns[next], describe(statements))
+		return false
+	}
+	return true
+}
+
+// AssertNotExecuted checks that no statement matching pattern ran
+func (m *Mock) AssertNotExecuted(t TestingT, pattern string) bool {
+	t.Helper()
+	statements := m.Statements()
+	for _, statement := range statements {
+		if strings.Contains(normalize(statement.SQL), normalize(pattern)) {
+			t.Errorf("gomtest: unexpected statement matching %q:\n%s", pattern, describe(statements))
+			return false
+		}
+	}
+	return true
+}
+
+// AssertArgs checks the arguments of the statement at index
+func (m *Mock) AssertArgs(t TestingT, index int, args ...interface{}) bool {
+	t.Helper()
+	statements := m.Statements()
+	if index < 0 || index >= len(statements) {
+		t.Errorf("gomtest: no statement %d:\n%s", index, describe(statements))
+		return false
+	}
+	got := statements[index].Args
+	if len(got) == 0 && len(args) == 0 {
+		return true
+	}
+	if !reflect.DeepEqual(got, args) {
+		t.Errorf("gomtest: statement %d args = %#v, want %#v", index, got, args)
+		return false
+	}
+	return true
+}
+
+// AssertScriptsUsed checks that every script matched at least once, and exactly Times(n) when set
+func (m *Mock) AssertScriptsUsed(t TestingT) bool {
+	t.Helper()
+	m.mu.Lock()
+	var unused []string
+	for _, s := range m.scripts {
+		if s.used == 0 || (s.times > 0 && s.used < s.times) {
+			unused = append(unused, fmt.Sprintf("%q (used %d times)", s.pattern, s.used))
+		}
+	}
+	m.mu.Unlock()
+	if len(unused) > 0 {
+		t.Errorf("gomtest: scripts not used: %s", strings.Join(unused, ", "))
+		return false
+	}
+	return true
+}
+
+// describe lists statements for failure messages
+func describe(statements []Statement) string {
+	if len(statements) == 0 {
+		return "  (no statements)"
+	}
+	var sb strings.Builder
+	for i, s := range statements {
+		fmt.Fprintf(&sb, "  %d: %s %v\n", i, s.SQL, s.Args)
+	}
+	return sb.String()
+}
+
+// normalize lower-cases s and collapses whitespace for pattern matching
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,135 @@
+package gomtest_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/kmlixh/gom/v4"
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/gomtest"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	ID     int64  `gom:"id,@"`
+	Name   string `gom:"name"`
+	Active bool   `gom:"active"`
+}
+
+func (user) TableName() string { return "users" }
+
+func open(t *testing.T, driverName string) (*gom.DB, *gomtest.Mock) {
+	mock := gomtest.New()
+	t.Cleanup(mock.Close)
+	db, err := gom.Open(driverName, mock.DSN(), nil)
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, mock
+}
+
+func TestMySQLInsertAndQuery(t *testing.T) {
+	db, mock := open(t, gomtest.MySQL)
+	mock.On("INSERT INTO `users`").ReturnResult(42, 1)
+	mock.On("SELECT * FROM `users`").Return(
+		gomtest.NewRows("id", "name", "active").
+			WithTypes("BIGINT", "VARCHAR", "BOOLEAN").
+			AddRow(42, "ann", true))
+
+	result := db.Chain().Insert(&user{Name: "ann", Active: true})
+	assert.NoError(t, result.Error)
+	assert.Equal(t, int64(42), result.ID)
+
+	var users []user
+	assert.NoError(t, db.Chain().Table("users").Where("id", define.OpEq, 42).List().Into(&users))
+	assert.Equal(t, []user{{ID: 42, Name: "ann", Active: true}}, users)
+
+	mock.AssertStatements(t,
+		"INSERT INTO `users` (`name`, `active`) VALUES (?, ?)",
+		"SELECT * FROM `users` WHERE `id` = ?")
+	mock.AssertArgs(t, 0, "ann", 1)
+	mock.AssertArgs(t, 1, 42)
+	mock.AssertScriptsUsed(t)
+}
+
+func TestPostgresTransactionRollback(t *testing.T) {
+	db, mock := open(t, gomtest.Postgres)
+	boom := errors.New("boom")
+	mock.On(`DELETE FROM "users"`).ReturnError(boom)
+
+	err := db.Chain().Transaction(func(tx *gom.Chain) error {
+		if result := tx.Table("users").Set("name", "bob").Where("id", define.OpEq, 1).Update(); result.Error != nil {
+			return result.Error
+		}
+		return tx.Table("users").Where("id", define.OpEq, 2).Delete().Error
+	})
+	assert.ErrorIs(t, err, boom)
+
+	mock.AssertStatements(t,
+		"BEGIN",
+		`UPDATE "users" SET "name" = $1 WHERE "id" = $2`,
+		`DELETE FROM "users" WHERE "id" = $1`,
+		"ROLLBACK")
+	mock.AssertNotExecuted(t, "COMMIT")
+}
+
+func TestStrictModeAndTimes(t *testing.T) {
+	db, mock := open(t, gomtest.MySQL)
+	mock.Strict()
+	mock.On("update `users`").ReturnResult(0, 3).Times(1)
+
+	first := db.Chain().Table("users").Set("name", "x").Where("id", define.OpGt, 0).Update()
+	assert.NoError(t, first.Error)
+	assert.Equal(t, int64(3), first.Affected)
+
+	second := db.Chain().Table("users").Set("name", "y").Where("id", define.OpGt, 0).Update()
+	assert.ErrorContains(t, second.Error, "unexpected statement")
+
+	mock.AssertStatementsInOrder(t, "UPDATE", "UPDATE")
+	mock.AssertScriptsUsed(t)
+}
+
+func TestAssertionsReportFailures(t *testing.T) {
+	mock := gomtest.New()
+	defer mock.Close()
+	rec := &recorder{}
+
+	assert.False(t, mock.AssertStatements(rec, "SELECT"))
+	assert.False(t, mock.AssertArgs(rec, 0))
+	mock.On("never")
+	assert.False(t, mock.AssertScriptsUsed(rec))
+	assert.Len(t, rec.errors, 3)
+}
+
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
+
+func TestNilPointerArgsAreNull(t *testing.T) {
+	type profile struct {
+		ID       int64           `gom:"id,!"`
+		Name     string          `gom:"name"`
+		Nickname *sql.NullString `gom:"nickname"`
+		Bio      *string         `gom:"bio"`
+	}
+	db, mock := open(t, gomtest.MySQL)
+	mock.On("UPDATE `profile`").ReturnResult(0, 1)
+	mock.On("INSERT INTO `profile`").ReturnResult(2, 1)
+
+	result := db.Chain().Table("profile").From(&profile{ID: 1, Name: "ann"}).Where("id", define.OpEq, 1).Update()
+	assert.NoError(t, result.Error)
+	var nickname *sql.NullString
+	raw := db.Chain().RawExecute("INSERT INTO `profile` (`name`, `nickname`) VALUES (?, ?)", "bob", nickname)
+	assert.NoError(t, raw.Error)
+
+	mock.AssertStatements(t,
+		"UPDATE `profile` SET `id` = ?, `name` = ?, `nickname` = ?, `bio` = ? WHERE `id` = ?",
+		"INSERT INTO `profile` (`name`, `nickname`) VALUES (?, ?)")
+	mock.AssertArgs(t, 0, int64(1), "ann", nil, nil, 1)
+	mock.AssertArgs(t, 1, "bob", nil)
+}
@@ -0,0 +1,259 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// Handler executes a statement and returns its result
+type Handler func(ctx context.Context, proto *define.SqlProto) *define.Result
+
+// Interceptor wraps statement execution
+// An interceptor sees the final SQL and may modify proto, replace ctx,
+// short-circuit by returning a result without calling next, or post-process the result
+type Interceptor func(ctx context.Context, proto *define.SqlProto, next Handler) *define.Result
+
+// TxHooks observes the transaction lifecycle
+type TxHooks struct {
+	// OnBegin runs before the transaction starts; the returned context is used for the transaction
+	// Returning an error aborts the transaction before it is started
+	OnBegin func(ctx context.Context, txID uint64) (context.Context, error)
+	// OnCommit runs after a commit attempt with its outcome
+	OnCommit func(ctx context.Context, txID uint64, err error)
+	// OnRollback runs after a rollback, or after a failed begin, with its outcome
+	OnRollback func(ctx context.Context, txID uint64, err error)
+}
+
+// plugins holds the interceptors and hooks registered on a DB
+// It is shared by every DB clone so registrations are visible to all goroutines
+type plugins struct {
+	mu           sync.RWMutex
+	interceptors []Interceptor
+	txHooks      []TxHooks
+	tracer       define.Tracer
+}
+
+// Use registers interceptors; they run in registration order, the first being outermost
+func (db *DB) Use(interceptors ...Interceptor) *DB {
+	p := db.getPlugins()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, interceptor := range interceptors {
+		if interceptor != nil {
+			p.interceptors = append(p.interceptors, interceptor)
+		}
+	}
+	return db
+}
+
+// UseTxHooks registers transaction lifecycle hooks
+func (db *DB) UseTxHooks(hooks TxHooks) *DB {
+	p := db.getPlugins()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.txHooks = append(p.txHooks, hooks)
+	return db
+}
+
+// getPlugins returns the plugin registry, creating it if necessary
+func (db *DB) getPlugins() *plugins {
+	db.Lock()
+	defer db.Unlock()
+	if db.plugins == nil {
+		db.plugins = &plugins{}
+	}
+	return db.plugins
+}
+
+// interceptorList returns a snapshot of the registered interceptors
+func (db *DB) interceptorList() []Interceptor {
+	if db == nil || db.plugins == nil {
+		return nil
+	}
+	db.plugins.mu.RLock()
+	defer db.plugins.mu.RUnlock()
+	return db.plugins.interceptors
+}
+
+// txHookList returns a snapshot of the registered transaction hooks
+func (db *DB) txHookList() []TxHooks {
+	if db == nil || db.plugins == nil {
+		return nil
+	}
+	db.plugins.mu.RLock()
+	defer db.plugins.mu.RUnlock()
+	return db.plugins.txHooks
+}
+
+// execute runs proto through the interceptor chain and then run
+// The statement is logged after the interceptors so the log shows the final SQL
+func (c *Chain) execute(proto *define.SqlProto, run Handler) *define.Result {
+	if proto.Table == "" {
+		proto.Table = c.tableName
+	}
+	if c.dryRun != nil {
+		c.dryRun.record(proto)
+		return &define.Result{}
+	}
+	handler := Handler(func(ctx context.Context, p *define.SqlProto) *define.Result {
+		start := time.Now()
+		result := run(ctx, p)
+		c.db.metrics.recordStatement(p, time.Since(start), result)
+		if result.Error == nil {
+			if ddlOperations[p.Operation()] {
+				c.db.invalidateStatements()
+			}
+			c.recordWrite(p)
+		}
+		c.logQuery(ctx, p.Sql, p.Args, start, resultRows(result), result.Error)
+		return result
+	})
+
+	interceptors := c.db.interceptorList()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, p *define.SqlProto) *define.Result {
+			result := interceptor(ctx, p, next)
+			if result == nil {
+				result = &define.Result{}
+			}
+			return result
+		}
+	}
+	return handler(c.getContext(), proto)
+}
+
+// resultRows returns the rows affected by result, or the rows it returned
+func resultRows(result *define.Result) int64 {
+	if result.Affected != 0 {
+		return result.Affected
+	}
+	return int64(len(result.Data))
+}
+
+// beginTx starts a transaction and runs the OnBegin hooks
+// It returns the transaction id and the context produced by the hooks
+// When detach is true the transaction is not bound to the cancellation of ctx,
+// matching sql.DB.Begin; the returned context keeps it
+func (db *DB) beginTx(ctx context.Context, opts *sql.TxOptions, detach bool) (*sql.Tx, uint64, context.Context, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txID := nextTxID()
+	hooks := db.txHookList()
+	for i, hook := range hooks {
+		if hook.OnBegin == nil {
+			continue
+		}
+		hookCtx, err := hook.OnBegin(ctx, txID)
+		if err != nil {
+			runRollbackHooks(hooks[:i], ctx, txID, err)
+			return nil, 0, ctx, err
+		}
+		if hookCtx != nil {
+			ctx = hookCtx
+		}
+	}
+
+	beginCtx := ctx
+	if detach {
+		beginCtx = context.WithoutCancel(ctx)
+	}
+	tx, err := db.executor().BeginTx(beginCtx, opts)
+	db.metrics.recordBegin(err)
+	if err != nil {
+		runRollbackHooks(hooks, ctx, txID, err)
+		return nil, 0, ctx, err
+	}
+	return tx, txID, ctx, nil
+}
+
+// commitTx commits tx and runs the OnCommit hooks
+func (db *DB) commitTx(ctx context.Context, tx *sql.Tx, txID uint64) error {
+	err := tx.Commit()
+	db.metrics.recordCommit(err)
+	if err == nil {
+		db.results.commit(txID)
+	} else {
+		db.results.discard(txID)
+	}
+	for _, hook := range db.txHookList() {
+		if hook.OnCommit != nil {
+			hook.OnCommit(ctx, txID, err)
+		}
+	}
+	return err
+}
+
+// rollbackTx rolls back tx and runs the OnRollback hooks
+func (db *DB) rollbackTx(ctx context.Context, tx *sql.Tx, txID uint64) error {
+	err := tx.Rollback()
+	db.metrics.recordRollback(err)
+	db.results.discard(txID)
+	runRollbackHooks(db.txHookList(), ctx, txID, err)
+	return err
+}
+
+// runRollbackHooks runs the OnRollback hooks of hooks
+func runRollbackHooks(hooks []TxHooks, ctx context.Context, txID uint64, err error) {
+	for _, hook := range hooks {
+		if hook.OnRollback != nil {
+			hook.OnRollback(ctx, txID, err)
+		}
+	}
+}
+
+// beginTx starts a transaction detached from the chain context's cancellation
+// and returns a copy of the chain bound to it
+func (c *Chain) beginTx() (*Chain, error) {
+	return c.beginTxContext(c.getContext())
+}
+
+// beginTxContext starts a transaction bound to ctx and returns a chain using it
+// A dry-run chain gets a copy without a transaction
+func (c *Chain) beginTxContext(ctx context.Context) (*Chain, error) {
+	if c.dryRun != nil {
+		return c.clone().SetContext(ctx), nil
+	}
+	tx, txID, ctx, err := c.db.beginTx(ctx, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	txChain := c.clone()
+	txChain.tx = tx
+	txChain.txID = txID
+	txChain.ctx = ctx
+	return txChain, nil
+}
+
+// commit commits the chain transaction and runs the OnCommit hooks
+// Errors are wrapped in commitError, so that retries can tell them from errors before the COMMIT
+func (c *Chain) commit() error {
+	if c.dryRun != nil {
+		return nil
+	}
+	if err := c.db.commitTx(c.getContext(), c.tx, c.txID); err != nil {
+		return &commitError{err: err}
+	}
+	return nil
+}
+
+// commitError is an error returned by COMMIT; the transaction may have been applied
+type commitError struct {
+	err error
+}
+
+func (e *commitError) Error() string { return e.err.Error() }
+func (e *commitError) Unwrap() error { return e.err }
+
+// rollback rolls back the chain transaction and runs the OnRollback hooks
+func (c *Chain) rollback() error {
+	if c.dryRun != nil {
+		return nil
+	}
+	return c.db.rollbackTx(c.getContext(), c.tx, c.txID)
+}
@@ -0,0 +1,79 @@
+package gom
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey string
+
+func TestInterceptorOrder(t *testing.T) {
+	db := &DB{}
+	var calls []string
+	db.Use(
+		func(ctx context.Context, proto *define.SqlProto, next Handler) *define.Result {
+			calls = append(calls, "outer")
+			proto.Sql = "/* outer */ " + proto.Sql
+			result := next(context.WithValue(ctx, ctxKey("k"), "v"), proto)
+			calls = append(calls, "outer done")
+			return result
+		},
+		func(ctx context.Context, proto *define.SqlProto, next Handler) *define.Result {
+			calls = append(calls, "inner")
+			assert.Equal(t, "v", ctx.Value(ctxKey("k")))
+			return next(ctx, proto)
+		},
+	)
+
+	chain := &Chain{db: db}
+	var executed string
+	result := chain.execute(&define.SqlProto{Sql: "SELECT 1"}, func(ctx context.Context, proto *define.SqlProto) *define.Result {
+		executed = proto.Sql
+		return &define.Result{Affected: 1}
+	})
+
+	assert.NoError(t, result.Error)
+	assert.Equal(t, "/* outer */ SELECT 1", executed)
+	assert.Equal(t, []string{"outer", "inner", "outer done"}, calls)
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	logger := &recordingLogger{}
+	db := &DB{options: define.DBOptions{Logger: logger, LogLevel: define.LogLevelInfo}}
+	db.Use(func(ctx context.Context, proto *define.SqlProto, next Handler) *define.Result {
+		return &define.Result{Error: errors.New("blocked")}
+	})
+
+	chain := &Chain{db: db}
+	result := chain.execute(&define.SqlProto{Sql: "DROP TABLE users"}, func(ctx context.Context, proto *define.SqlProto) *define.Result {
+		t.Fatal("statement must not run")
+		return nil
+	})
+
+	assert.EqualError(t, result.Error, "blocked")
+	assert.Empty(t, logger.events, "short-circuited statements are not logged")
+}
+
+func TestTxHooksBeginError(t *testing.T) {
+	db := &DB{}
+	var rolledBack []error
+	db.UseTxHooks(TxHooks{
+		OnRollback: func(ctx context.Context, txID uint64, err error) {
+			rolledBack = append(rolledBack, err)
+		},
+	})
+	db.UseTxHooks(TxHooks{
+		OnBegin: func(ctx context.Context, txID uint64) (context.Context, error) {
+			return ctx, errors.New("denied")
+		},
+	})
+
+	_, err := db.BeginChain()
+	assert.EqualError(t, err, "denied")
+	assert.Len(t, rolledBack, 1)
+	assert.EqualError(t, rolledBack[0], "denied")
+}
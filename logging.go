@@ -0,0 +1,172 @@
+package gom
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// gomSourceDir is the directory of the gom module, used to find the first caller outside gom
+var gomSourceDir = func() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return ""
+	}
+	return filepath.Dir(file)
+}()
+
+// defaultLogger is used when DBOptions.Logger is not set
+var defaultLogger define.Logger = define.NewStdLogger(nil)
+
+// txIDCounter generates transaction ids for query events
+var txIDCounter uint64
+
+// nextTxID returns a new process-unique transaction id
+func nextTxID() uint64 {
+	return atomic.AddUint64(&txIDCounter, 1)
+}
+
+// logger returns the Logger configured for this DB
+func (db *DB) logger() define.Logger {
+	if db.options.Logger != nil {
+		return db.options.Logger
+	}
+	return defaultLogger
+}
+
+// IsDebug reports whether debug logging is enabled for this DB
+func (db *DB) IsDebug() bool {
+	return db.options.Debug
+}
+
+// logQuery reports an executed statement to the DB logger
+func (c *Chain) logQuery(ctx context.Context, sqlStr string, args []interface{}, start time.Time, rows int64, err error) {
+	if c.db == nil {
+		return
+	}
+	duration := time.Since(start)
+	opts := &c.db.options
+	slow := opts.SlowThreshold > 0 && duration >= opts.SlowThreshold
+
+	level := define.LogLevelInfo
+	if err != nil {
+		level = define.LogLevelError
+	} else if slow {
+		level = define.LogLevelWarn
+	}
+	if !opts.EffectiveLogLevel().Enabled(level) {
+		return
+	}
+
+	c.db.logger().LogQuery(ctx, level, &define.QueryEvent{
+		SQL:      sqlStr,
+		Args:     c.redactArgs(sqlStr, args),
+		Duration: duration,
+		Rows:     rows,
+		Err:      err,
+		TxID:     c.txID,
+		Caller:   callerLocation(),
+		Slow:     slow,
+	})
+}
+
+// redactMarker stands in for sensitive values when locating their argument positions
+type redactMarker struct{ _ byte }
+
+// redactArgs replaces the arguments of sqlStr that bind sensitive fields
+// Positions are found by building the statement again with the sensitive values marked; when that
+// does not line up with args, every argument is redacted rather than risk logging a sensitive value
+func (c *Chain) redactArgs(sqlStr string, args []interface{}) []interface{} {
+	if len(c.sensitiveFields) == 0 || len(args) == 0 {
+		return args
+	}
+
+	marker := &redactMarker{}
+	fields := make(map[string]interface{}, len(c.fieldMap))
+	for field, value := range c.fieldMap {
+		if _, ok := c.sensitiveFields[field]; ok {
+			value = marker
+		}
+		fields[field] = value
+	}
+	conds := c.markSensitiveConds(c.conds, marker)
+
+	var probe *define.SqlProto
+	if c.factory != nil {
+		switch verb := strings.ToUpper(strings.Fields(sqlStr + " ")[0]); verb {
+		case "INSERT":
+			probe = c.factory.BuildInsert(c.tableName, fields, c.fieldOrder)
+		case "UPDATE":
+			probe = c.factory.BuildUpdate(c.tableName, fields, c.fieldOrder, conds)
+		case "SELECT", "DELETE":
+			probe = c.factory.BuildSelect(c.tableName, nil, conds, "", 0, 0)
+		}
+	}
+
+	redacted := make([]interface{}, len(args))
+	for i, arg := range args {
+		redacted[i] = arg
+		if probe == nil || probe.Error != nil || len(probe.Args) != len(args) || probe.Args[i] == marker {
+			redacted[i] = define.RedactedValue
+		}
+	}
+	return redacted
+}
+
+// markSensitiveConds copies conds with the values compared against sensitive fields replaced by marker
+func (c *Chain) markSensitiveConds(conds []*define.Condition, marker *redactMarker) []*define.Condition {
+	marked := make([]*define.Condition, len(conds))
+	for i, cond := range conds {
+		if cond == nil {
+			continue
+		}
+		copied := *cond
+		if _, ok := c.sensitiveFields[cond.Field]; ok && !cond.IsRawExpr && cond.Value != nil {
+			if list, ok := cond.Value.([]interface{}); ok {
+				values := make([]interface{}, len(list))
+				for j := range values {
+					values[j] = marker
+				}
+				copied.Value = values
+			} else {
+				copied.Value = marker
+			}
+		}
+		copied.SubConds = c.markSensitiveConds(cond.SubConds, marker)
+		marked[i] = &copied
+	}
+	return marked
+}
+
+// callerLocation returns file:line of the first frame outside gom
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isGomFrame(frame.File) {
+			if strings.HasPrefix(frame.Function, "runtime.") {
+				return ""
+			}
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// isGomFrame reports whether file belongs to gom itself (tests excluded)
+func isGomFrame(file string) bool {
+	if gomSourceDir == "" || strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	return strings.HasPrefix(file, gomSourceDir+"/")
+}
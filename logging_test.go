@@ -0,0 +1,87 @@
+package gom
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/gomtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingLogger collects query events for assertions
+type recordingLogger struct {
+	levels []define.LogLevel
+	events []*define.QueryEvent
+}
+
+func (l *recordingLogger) LogQuery(ctx context.Context, level define.LogLevel, event *define.QueryEvent) {
+	l.levels = append(l.levels, level)
+	l.events = append(l.events, event)
+}
+
+func TestChainLogQuery(t *testing.T) {
+	logger := &recordingLogger{}
+	db := &DB{options: define.DBOptions{Logger: logger, LogLevel: define.LogLevelInfo, SlowThreshold: time.Hour}}
+	chain := &Chain{db: db, txID: 42}
+
+	chain.logQuery(context.Background(), "SELECT 1", nil, time.Now(), 1, nil)
+	chain.logQuery(context.Background(), "SELECT 2", nil, time.Now(), 0, errors.New("failed"))
+
+	assert.Equal(t, []define.LogLevel{define.LogLevelInfo, define.LogLevelError}, logger.levels)
+	assert.Equal(t, uint64(42), logger.events[0].TxID)
+	assert.True(t, strings.HasSuffix(strings.Split(logger.events[0].Caller, ":")[0], "logging_test.go"))
+	assert.False(t, logger.events[0].Slow)
+}
+
+func TestChainLogQuerySlowAndLevels(t *testing.T) {
+	logger := &recordingLogger{}
+	db := &DB{options: define.DBOptions{Logger: logger, SlowThreshold: time.Nanosecond}}
+	chain := &Chain{db: db}
+
+	chain.logQuery(context.Background(), "SELECT 1", nil, time.Now().Add(-time.Millisecond), 1, nil)
+	assert.Len(t, logger.events, 1)
+	assert.Equal(t, define.LogLevelWarn, logger.levels[0])
+	assert.True(t, logger.events[0].Slow)
+
+	// Without a slow threshold, successful statements are below the default WARN level
+	db.options.SlowThreshold = 0
+	chain.logQuery(context.Background(), "SELECT 1", nil, time.Now(), 1, nil)
+	assert.Len(t, logger.events, 1)
+}
+
+func TestRedactArgs(t *testing.T) {
+	db, _ := openMock(t, gomtest.MySQL)
+	chain := db.Chain().Table("users")
+	chain.AddSensitiveField("phone", SensitiveOptions{Type: SensitivePhone})
+	chain.fieldMap = map[string]interface{}{"name": "13800138000", "phone": "13800138000"}
+	chain.fieldOrder = []string{"name", "phone"}
+	chain.Where("phone", define.OpEq, "13900139000").Where("age", define.OpEq, 18)
+
+	// name 的值与 phone 相同，但不在敏感字段的位置上
+	args := []interface{}{"13800138000", "13800138000", "13900139000", 18}
+	redacted := chain.redactArgs("UPDATE `users` SET `name` = ?, `phone` = ? WHERE `phone` = ? AND `age` = ?", args)
+	assert.Equal(t, []interface{}{"13800138000", define.RedactedValue, define.RedactedValue, 18}, redacted)
+	assert.Equal(t, "13800138000", args[1], "original args must not be modified")
+
+	redacted = chain.redactArgs("SELECT * FROM `users` WHERE `phone` = ? AND `age` = ?", []interface{}{"13900139000", 18})
+	assert.Equal(t, []interface{}{define.RedactedValue, 18}, redacted)
+
+	// 无法确定位置时全部隐藏
+	redacted = chain.redactArgs("CALL refresh_user(?, ?)", []interface{}{"13900139000", 18})
+	assert.Equal(t, []interface{}{define.RedactedValue, define.RedactedValue}, redacted)
+}
+
+func TestDBErrorQueryFollowsDebug(t *testing.T) {
+	db, _ := openMock(t, gomtest.MySQL)
+	err := db.Chain().queryError("BatchDelete", errors.New("boom"), "DELETE FROM `users`")
+	assert.Equal(t, "BatchDelete: boom", err.Error())
+
+	db.options.Debug = true
+	err = db.Chain().queryError("BatchDelete", errors.New("boom"), "DELETE FROM `users`")
+	assert.Equal(t, "BatchDelete: boom [Query: DELETE FROM `users`]", err.Error())
+	assert.EqualError(t, errors.Unwrap(err), "boom")
+}
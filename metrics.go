@@ -0,0 +1,505 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// latencyBuckets are the upper bounds of the statement latency histogram
+var latencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// metricsSampleInterval is how often connection pool statistics are sampled
+const metricsSampleInterval = time.Second
+
+// Error categories used by the statement error counters
+const (
+	ErrCategoryTimeout    = "timeout"
+	ErrCategoryCanceled   = "canceled"
+	ErrCategoryConnection = "connection"
+	ErrCategoryConstraint = "constraint"
+	ErrCategorySyntax     = "syntax"
+	ErrCategoryOther      = "other"
+)
+
+// HistogramBucket is a cumulative latency bucket
+type HistogramBucket struct {
+	UpperBound time.Duration // Inclusive upper bound
+	Count      int64         // Statements that took at most UpperBound
+}
+
+// StatementMetrics aggregates the statements of one operation on one table
+type StatementMetrics struct {
+	Operation     string
+	Table         string
+	Count         int64
+	Errors        int64
+	RowsScanned   int64
+	RowsAffected  int64
+	TotalDuration time.Duration
+	Buckets       []HistogramBucket
+}
+
+// ErrorMetrics counts failed statements of one operation, table and error category
+type ErrorMetrics struct {
+	Operation string
+	Table     string
+	Category  string
+	Count     int64
+}
+
+// TxMetrics counts transaction outcomes
+type TxMetrics struct {
+	Commits        int64
+	CommitErrors   int64
+	Rollbacks      int64
+	RollbackErrors int64
+	BeginErrors    int64
+}
+
+// PoolMetrics extends the pool statistics with peaks observed by the sampler
+type PoolMetrics struct {
+	DBMetrics
+	MaxOpenSeen  int64 // Highest number of open connections sampled
+	MaxInUseSeen int64 // Highest number of in-use connections sampled
+}
+
+// MetricsSnapshot is a point-in-time copy of the metrics of a DB
+type MetricsSnapshot struct {
+	Statements   []StatementMetrics
+	Errors       []ErrorMetrics
+	Transactions TxMetrics
+	Retries      map[string]int64 // Retries by operation
+	Pool         PoolMetrics
+	StmtCache    StmtCacheStats
+	CollectedAt  time.Time
+}
+
+type statementKey struct {
+	operation string
+	table     string
+}
+
+type errorKey struct {
+	statementKey
+	category string
+}
+
+type statementStats struct {
+	count        int64
+	errors       int64
+	rowsScanned  int64
+	rowsAffected int64
+	total        time.Duration
+	buckets      []int64 // Non-cumulative; the last entry counts statements above every bound
+}
+
+// metricsCollector accumulates the metrics of a DB and all its clones
+type metricsCollector struct {
+	mu         sync.Mutex
+	statements map[statementKey]*statementStats
+	errors     map[errorKey]int64
+	tx         TxMetrics
+	retries    map[string]int64
+	pool       PoolMetrics
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// newMetricsCollector creates an empty collector
+func newMetricsCollector() *metricsCollector {
+	return &metricsCollector{
+		statements: make(map[statementKey]*statementStats),
+		errors:     make(map[errorKey]int64),
+		retries:    make(map[string]int64),
+		stop:       make(chan struct{}),
+	}
+}
+
+// recordStatement records an executed statement
+func (m *metricsCollector) recordStatement(proto *define.SqlProto, duration time.Duration, result *define.Result) {
+	if m == nil {
+		return
+	}
+	key := statementKey{operation: proto.Operation(), table: proto.Table}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats, ok := m.statements[key]
+	if !ok {
+		stats = &statementStats{buckets: make([]int64, len(latencyBuckets)+1)}
+		m.statements[key] = stats
+	}
+	stats.count++
+	stats.total += duration
+	stats.buckets[sort.Search(len(latencyBuckets), func(i int) bool {
+		return duration <= latencyBuckets[i]
+	})]++
+	if proto.SqlType == define.Query {
+		stats.rowsScanned += int64(len(result.Data))
+	} else {
+		stats.rowsAffected += result.Affected
+	}
+	if result.Error != nil {
+		stats.errors++
+		m.errors[errorKey{statementKey: key, category: errorCategory(result.Error)}]++
+	}
+}
+
+// recordBegin records a failed transaction start
+func (m *metricsCollector) recordBegin(err error) {
+	if m == nil || err == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tx.BeginErrors++
+}
+
+// recordCommit records a commit attempt
+func (m *metricsCollector) recordCommit(err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.tx.CommitErrors++
+	} else {
+		m.tx.Commits++
+	}
+}
+
+// recordRollback records a rollback attempt
+func (m *metricsCollector) recordRollback(err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.tx.RollbackErrors++
+	} else {
+		m.tx.Rollbacks++
+	}
+}
+
+// recordRetry records one retry of operation
+func (m *metricsCollector) recordRetry(operation string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries[operation]++
+}
+
+// samplePool records pool statistics and updates the observed peaks
+func (m *metricsCollector) samplePool(stats sql.DBStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pool.DBMetrics = poolMetrics(stats)
+	if m.pool.OpenConnections > m.pool.MaxOpenSeen {
+		m.pool.MaxOpenSeen = m.pool.OpenConnections
+	}
+	if m.pool.InUseConnections > m.pool.MaxInUseSeen {
+		m.pool.MaxInUseSeen = m.pool.InUseConnections
+	}
+}
+
+// run samples the pool of sqlDB until stopped
+func (m *metricsCollector) run(sqlDB *sql.DB) {
+	ticker := time.NewTicker(metricsSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.samplePool(sqlDB.Stats())
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// close stops the sampler goroutine; it is safe to call more than once
+func (m *metricsCollector) close() {
+	if m == nil {
+		return
+	}
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// snapshot copies the collected metrics
+func (m *metricsCollector) snapshot() MetricsSnapshot {
+	snapshot := MetricsSnapshot{Retries: make(map[string]int64), CollectedAt: time.Now()}
+	if m == nil {
+		return snapshot
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, stats := range m.statements {
+		metrics := StatementMetrics{
+			Operation:     key.operation,
+			Table:         key.table,
+			Count:         stats.count,
+			Errors:        stats.errors,
+			RowsScanned:   stats.rowsScanned,
+			RowsAffected:  stats.rowsAffected,
+			TotalDuration: stats.total,
+			Buckets:       make([]HistogramBucket, len(latencyBuckets)),
+		}
+		var cumulative int64
+		for i, bound := range latencyBuckets {
+			cumulative += stats.buckets[i]
+			metrics.Buckets[i] = HistogramBucket{UpperBound: bound, Count: cumulative}
+		}
+		snapshot.Statements = append(snapshot.Statements, metrics)
+	}
+	sort.Slice(snapshot.Statements, func(i, j int) bool {
+		a, b := snapshot.Statements[i], snapshot.Statements[j]
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		return a.Table < b.Table
+	})
+
+	for key, count := range m.errors {
+		snapshot.Errors = append(snapshot.Errors, ErrorMetrics{
+			Operation: key.operation,
+			Table:     key.table,
+			Category:  key.category,
+			Count:     count,
+		})
+	}
+	sort.Slice(snapshot.Errors, func(i, j int) bool {
+		a, b := snapshot.Errors[i], snapshot.Errors[j]
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Category < b.Category
+	})
+
+	for operation, count := range m.retries {
+		snapshot.Retries[operation] = count
+	}
+	snapshot.Transactions = m.tx
+	snapshot.Pool = m.pool
+	return snapshot
+}
+
+// poolMetrics converts sql.DBStats to DBMetrics
+func poolMetrics(stats sql.DBStats) DBMetrics {
+	return DBMetrics{
+		OpenConnections:   int64(stats.OpenConnections),
+		InUseConnections:  int64(stats.InUse),
+		IdleConnections:   int64(stats.Idle),
+		WaitCount:         stats.WaitCount,
+		WaitDuration:      stats.WaitDuration,
+		MaxIdleTimeClosed: stats.MaxIdleClosed,
+	}
+}
+
+// errorCategory classifies a statement error for the error counters
+func errorCategory(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrCategoryTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrCategoryCanceled
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return ErrCategoryConnection
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrCategoryTimeout
+		}
+		return ErrCategoryConnection
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "duplicate"), strings.Contains(msg, "constraint"),
+		strings.Contains(msg, "foreign key"), strings.Contains(msg, "violates"):
+		return ErrCategoryConstraint
+	case strings.Contains(msg, "syntax"):
+		return ErrCategorySyntax
+	case strings.Contains(msg, "connection refused"), strings.Contains(msg, "broken pipe"),
+		strings.Contains(msg, "bad connection"):
+		return ErrCategoryConnection
+	}
+	return ErrCategoryOther
+}
+
+// Metrics returns a snapshot of the statement, transaction, retry and pool metrics
+// Pool statistics are read live; the peaks come from the background sampler
+func (db *DB) Metrics() MetricsSnapshot {
+	snapshot := db.metrics.snapshot()
+	if db.DB != nil {
+		snapshot.Pool.DBMetrics = poolMetrics(db.DB.Stats())
+	}
+	snapshot.StmtCache = db.StmtCacheStats()
+	return snapshot
+}
+
+// MetricsHandler returns an http.Handler serving the metrics in the Prometheus text format
+func (db *DB) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		snapshot := db.Metrics()
+		snapshot.WritePrometheus(w)
+	})
+}
+
+// PublishExpvar publishes the metrics snapshot under name in expvar
+// Like expvar.Publish, it panics if name is already registered
+func (db *DB) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return db.Metrics()
+	}))
+}
+
+// WritePrometheus writes the snapshot in the Prometheus text exposition format
+func (s *MetricsSnapshot) WritePrometheus(w io.Writer) error {
+	pw := &promWriter{w: w}
+
+	pw.header("gom_statement_duration_seconds", "histogram", "Statement latency by operation and table")
+	for _, st := range s.Statements {
+		labels := promLabels("operation", st.Operation, "table", st.Table)
+		for _, bucket := range st.Buckets {
+			pw.printf("gom_statement_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, bucket.UpperBound.Seconds(), bucket.Count)
+		}
+		pw.printf("gom_statement_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, st.Count)
+		pw.printf("gom_statement_duration_seconds_sum{%s} %g\n", labels, st.TotalDuration.Seconds())
+		pw.printf("gom_statement_duration_seconds_count{%s} %d\n", labels, st.Count)
+	}
+
+	pw.header("gom_statement_rows_scanned_total", "counter", "Rows returned by queries")
+	for _, st := range s.Statements {
+		pw.printf("gom_statement_rows_scanned_total{%s} %d\n", promLabels("operation", st.Operation, "table", st.Table), st.RowsScanned)
+	}
+	pw.header("gom_statement_rows_affected_total", "counter", "Rows affected by statements")
+	for _, st := range s.Statements {
+		pw.printf("gom_statement_rows_affected_total{%s} %d\n", promLabels("operation", st.Operation, "table", st.Table), st.RowsAffected)
+	}
+
+	pw.header("gom_statement_errors_total", "counter", "Failed statements by operation, table and error category")
+	for _, e := range s.Errors {
+		pw.printf("gom_statement_errors_total{%s} %d\n", promLabels("operation", e.Operation, "table", e.Table, "category", e.Category), e.Count)
+	}
+
+	pw.header("gom_transactions_total", "counter", "Transaction outcomes")
+	for _, outcome := range []struct {
+		name  string
+		count int64
+	}{
+		{"commit", s.Transactions.Commits},
+		{"commit_error", s.Transactions.CommitErrors},
+		{"rollback", s.Transactions.Rollbacks},
+		{"rollback_error", s.Transactions.RollbackErrors},
+		{"begin_error", s.Transactions.BeginErrors},
+	} {
+		pw.printf("gom_transactions_total{%s} %d\n", promLabels("outcome", outcome.name), outcome.count)
+	}
+
+	pw.header("gom_retries_total", "counter", "Retried operations")
+	operations := make([]string, 0, len(s.Retries))
+	for operation := range s.Retries {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		pw.printf("gom_retries_total{%s} %d\n", promLabels("operation", operation), s.Retries[operation])
+	}
+
+	for _, gauge := range []struct {
+		name, help string
+		value      float64
+	}{
+		{"gom_pool_open_connections", "Open connections", float64(s.Pool.OpenConnections)},
+		{"gom_pool_in_use_connections", "Connections in use", float64(s.Pool.InUseConnections)},
+		{"gom_pool_idle_connections", "Idle connections", float64(s.Pool.IdleConnections)},
+		{"gom_pool_max_open_seen", "Highest sampled number of open connections", float64(s.Pool.MaxOpenSeen)},
+		{"gom_pool_max_in_use_seen", "Highest sampled number of in-use connections", float64(s.Pool.MaxInUseSeen)},
+	} {
+		pw.header(gauge.name, "gauge", gauge.help)
+		pw.printf("%s %g\n", gauge.name, gauge.value)
+	}
+	pw.header("gom_stmt_cache_lookups_total", "counter", "Prepared statement cache lookups")
+	pw.printf("gom_stmt_cache_lookups_total{%s} %d\n", promLabels("result", "hit"), s.StmtCache.Hits)
+	pw.printf("gom_stmt_cache_lookups_total{%s} %d\n", promLabels("result", "miss"), s.StmtCache.Misses)
+	pw.header("gom_stmt_cache_size", "gauge", "Prepared statements currently cached")
+	pw.printf("gom_stmt_cache_size %d\n", s.StmtCache.Size)
+
+	pw.header("gom_pool_wait_count_total", "counter", "Connections waited for")
+	pw.printf("gom_pool_wait_count_total %d\n", s.Pool.WaitCount)
+	pw.header("gom_pool_wait_seconds_total", "counter", "Time spent waiting for connections")
+	pw.printf("gom_pool_wait_seconds_total %g\n", s.Pool.WaitDuration.Seconds())
+
+	return pw.err
+}
+
+// promWriter writes Prometheus text and keeps the first write error
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func (p *promWriter) header(name, kind, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// promLabels formats key/value pairs as Prometheus labels
+func promLabels(pairs ...string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(pairs[i])
+		sb.WriteString(`="`)
+		sb.WriteString(promLabelEscaper.Replace(pairs[i+1]))
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
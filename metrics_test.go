@@ -0,0 +1,157 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/gomtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsCollector(t *testing.T) {
+	db := &DB{metrics: newMetricsCollector()}
+	chain := &Chain{db: db, tableName: "users"}
+	run := func(result *define.Result, delay time.Duration) Handler {
+		return func(ctx context.Context, proto *define.SqlProto) *define.Result {
+			time.Sleep(delay)
+			return result
+		}
+	}
+
+	chain.execute(&define.SqlProto{SqlType: define.Query, Sql: "SELECT * FROM users"},
+		run(&define.Result{Data: []map[string]interface{}{{"id": 1}, {"id": 2}}}, 0))
+	chain.execute(&define.SqlProto{SqlType: define.Query, Sql: "SELECT * FROM users"},
+		run(&define.Result{Error: context.DeadlineExceeded}, 2*time.Millisecond))
+	chain.execute(&define.SqlProto{SqlType: define.Exec, Sql: "UPDATE users SET name = ?"},
+		run(&define.Result{Affected: 3}, 0))
+	db.metrics.recordCommit(nil)
+	db.metrics.recordRollback(nil)
+	db.metrics.recordRollback(sql.ErrTxDone)
+
+	snapshot := db.Metrics()
+	assert.Len(t, snapshot.Statements, 2)
+	selects := snapshot.Statements[0]
+	assert.Equal(t, "SELECT", selects.Operation)
+	assert.Equal(t, "users", selects.Table)
+	assert.Equal(t, int64(2), selects.Count)
+	assert.Equal(t, int64(1), selects.Errors)
+	assert.Equal(t, int64(2), selects.RowsScanned)
+	assert.Equal(t, int64(1), selects.Buckets[0].Count, "only the fast query is within 1ms")
+	assert.Equal(t, int64(2), selects.Buckets[len(selects.Buckets)-1].Count)
+	assert.Equal(t, int64(3), snapshot.Statements[1].RowsAffected)
+
+	assert.Equal(t, []ErrorMetrics{{Operation: "SELECT", Table: "users", Category: ErrCategoryTimeout, Count: 1}}, snapshot.Errors)
+	assert.Equal(t, TxMetrics{Commits: 1, Rollbacks: 1, RollbackErrors: 1}, snapshot.Transactions)
+}
+
+func TestMetricsRetries(t *testing.T) {
+	db := &DB{metrics: newMetricsCollector()}
+	attempts := 0
+	err := db.retryWithBackoff(context.Background(), "batch_insert", 3, time.Millisecond, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("deadlock")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"batch_insert": 2}, db.Metrics().Retries)
+
+	// 非瞬时错误不重试
+	attempts = 0
+	err = db.retryWithBackoff(context.Background(), "batch_insert", 3, time.Millisecond, func() error {
+		attempts++
+		return errors.New("Error 1062: Duplicate entry '1' for key 'PRIMARY'")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestBatchRetries(t *testing.T) {
+	mock := gomtest.New()
+	t.Cleanup(mock.Close)
+	db, err := Open(gomtest.MySQL, mock.DSN(), &define.DBOptions{RetryCount: 2, RetryInterval: time.Millisecond})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	mock.On("INSERT INTO `users`").ReturnError(errors.New("Error 1213: Deadlock found when trying to get lock")).Times(1)
+	mock.On("INSERT INTO `users`").ReturnResult(1, 1)
+	mock.On("UPDATE `users`").ReturnError(errors.New("Error 1213: Deadlock found when trying to get lock")).Times(1)
+	mock.On("UPDATE `users`").ReturnResult(0, 1)
+
+	affected, err := db.Chain().Table("users").BatchValues([]map[string]interface{}{{"name": "alice"}}).BatchInsert(10, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	affected, err = db.Chain().Table("users").BatchValues([]map[string]interface{}{{"id": 1, "name": "bob"}}).BatchUpdate(10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected, "the rolled back attempt is not counted")
+	assert.Equal(t, map[string]int64{"batch_insert": 1, "batch_update": 1}, db.Metrics().Retries)
+	mock.AssertStatementsInOrder(t, "BEGIN", "INSERT INTO `users`", "ROLLBACK", "BEGIN", "INSERT INTO `users`", "COMMIT")
+
+	// 约束错误直接返回
+	mock.Reset()
+	mock.On("INSERT INTO `users`").ReturnError(errors.New("Error 1062: Duplicate entry 'alice' for key 'name'"))
+	_, err = db.Chain().Table("users").BatchValues([]map[string]interface{}{{"name": "alice"}}).BatchInsert(10, false)
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int64{"batch_insert": 1, "batch_update": 1}, db.Metrics().Retries)
+
+	// COMMIT 可能已在服务端生效，连接错误不重试
+	mock.Reset()
+	mock.On("COMMIT").ReturnError(driver.ErrBadConn).Times(1)
+	_, err = db.Chain().Table("users").BatchValues([]map[string]interface{}{{"name": "alice"}}).BatchInsert(10, false)
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, map[string]int64{"batch_insert": 1, "batch_update": 1}, db.Metrics().Retries)
+	mock.AssertStatementsInOrder(t, "BEGIN", "INSERT INTO `users`", "COMMIT")
+	assert.Len(t, mock.Statements(), 3)
+}
+
+func TestErrorCategory(t *testing.T) {
+	assert.Equal(t, ErrCategoryTimeout, errorCategory(fmt.Errorf("query: %w", context.DeadlineExceeded)))
+	assert.Equal(t, ErrCategoryConnection, errorCategory(sql.ErrConnDone))
+	assert.Equal(t, ErrCategoryConstraint, errorCategory(errors.New("Error 1062: Duplicate entry '1' for key 'PRIMARY'")))
+	assert.Equal(t, ErrCategorySyntax, errorCategory(errors.New(`ERROR: syntax error at or near "FORM"`)))
+	assert.Equal(t, ErrCategoryOther, errorCategory(errors.New("boom")))
+}
+
+func TestMetricsWritePrometheus(t *testing.T) {
+	collector := newMetricsCollector()
+	collector.recordStatement(&define.SqlProto{SqlType: define.Exec, Sql: "DELETE FROM users", Table: `we"ird`},
+		2*time.Millisecond, &define.Result{Error: errors.New("boom")})
+	collector.recordRetry("batch_insert")
+
+	var sb strings.Builder
+	snapshot := collector.snapshot()
+	assert.NoError(t, snapshot.WritePrometheus(&sb))
+	out := sb.String()
+	assert.Contains(t, out, "# TYPE gom_statement_duration_seconds histogram")
+	assert.Contains(t, out, `gom_statement_duration_seconds_bucket{operation="DELETE",table="we\"ird",le="0.005"} 1`)
+	assert.Contains(t, out, `gom_statement_duration_seconds_bucket{operation="DELETE",table="we\"ird",le="0.001"} 0`)
+	assert.Contains(t, out, `gom_statement_errors_total{operation="DELETE",table="we\"ird",category="other"} 1`)
+	assert.Contains(t, out, `gom_retries_total{operation="batch_insert"} 1`)
+	assert.Contains(t, out, `gom_transactions_total{outcome="commit"} 0`)
+}
+
+func TestMetricsCollectorClose(t *testing.T) {
+	collector := newMetricsCollector()
+	done := make(chan struct{})
+	go func() {
+		collector.run(nil)
+		close(done)
+	}()
+	collector.close()
+	collector.close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sampler goroutine did not stop")
+	}
+}
@@ -0,0 +1,254 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/kmlixh/gom/v4"
+	"github.com/kmlixh/gom/v4/gomtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const createUsers = `-- +gom Up
+CREATE TABLE users (id BIGINT PRIMARY KEY, name VARCHAR(64));
+INSERT INTO users (id, name) VALUES (1, 'a;b');
+
+-- +gom Down
+DROP TABLE users;
+`
+
+func openMock(t *testing.T, driver string) (*gom.DB, *gomtest.Mock) {
+	mock := gomtest.New()
+	t.Cleanup(mock.Close)
+	db, err := gom.Open(driver, mock.DSN(), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, mock
+}
+
+func history(rows ...[]interface{}) *gomtest.Rows {
+	r := gomtest.NewRows("version", "name", "checksum", "applied_at").WithTypes("BIGINT", "VARCHAR", "VARCHAR", "TIMESTAMP")
+	for _, row := range rows {
+		r.AddRow(row...)
+	}
+	return r
+}
+
+func TestParseSQL(t *testing.T) {
+	m, err := ParseSQL(1, "create_users", createUsers)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE TABLE users (id BIGINT PRIMARY KEY, name VARCHAR(64))",
+		"INSERT INTO users (id, name) VALUES (1, 'a;b')",
+	}, m.Up)
+	assert.Equal(t, []string{"DROP TABLE users"}, m.Down)
+	assert.Len(t, m.Checksum, 64)
+	assert.False(t, m.NoTransaction)
+}
+
+func TestParseSQLStatementBlocks(t *testing.T) {
+	m, err := ParseSQL(2, "func", `-- +gom Up
+-- +gom NoTransaction
+-- +gom StatementBegin
+CREATE FUNCTION f() RETURNS void AS $$ BEGIN PERFORM 1; END; $$ LANGUAGE plpgsql;
+-- +gom StatementEnd
+CREATE INDEX CONCURRENTLY idx ON t (a); /* trailing; comment */
+`)
+	require.NoError(t, err)
+	assert.True(t, m.NoTransaction)
+	assert.Equal(t, []string{
+		"CREATE FUNCTION f() RETURNS void AS $$ BEGIN PERFORM 1; END; $$ LANGUAGE plpgsql",
+		"CREATE INDEX CONCURRENTLY idx ON t (a)",
+	}, m.Up)
+	assert.False(t, m.hasDown())
+
+	_, err = ParseSQL(3, "bad", "-- +gom StatementBegin\nSELECT 1;\n")
+	assert.Error(t, err)
+	_, err = ParseSQL(3, "bad", "-- +gom Sideways\n")
+	assert.Error(t, err)
+}
+
+func TestSplitStatementsDollarQuotes(t *testing.T) {
+	assert.Equal(t, []string{
+		"SELECT $1",
+		"DO $body$ BEGIN RAISE NOTICE 'x;y'; END $body$",
+	}, splitStatements("SELECT $1; DO $body$ BEGIN RAISE NOTICE 'x;y'; END $body$;\n-- done;"))
+}
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/2_add_email.sql":    {Data: []byte("ALTER TABLE users ADD email TEXT;")},
+		"migrations/1_create_users.sql": {Data: []byte(createUsers)},
+		"migrations/README.md":          {Data: []byte("not a migration")},
+	}
+	migrations, err := FromFS(fsys, "migrations")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	m := New(nil, Options{})
+	require.NoError(t, m.Add(migrations...))
+	assert.Equal(t, int64(1), m.Migrations()[0].Version)
+	assert.Equal(t, "2_add_email", m.Migrations()[1].String())
+	assert.ErrorContains(t, m.Add(Go(2, "dup", func(*gom.Chain) error { return nil }, nil)), "duplicate")
+}
+
+func TestUpPostgresUsesLockAndTransactions(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	mock.On("pg_try_advisory_lock").Return(gomtest.NewRows("ok").AddRow(true))
+
+	var out bytes.Buffer
+	m := New(db, Options{Out: &out})
+	up, err := ParseSQL(1, "create_users", createUsers)
+	require.NoError(t, err)
+	called := false
+	require.NoError(t, m.Add(up, Go(2, "seed", func(tx *gom.Chain) error {
+		called = tx.IsInTransaction()
+		return nil
+	}, nil)))
+
+	steps, err := m.Up(context.Background())
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.True(t, called)
+	assert.Equal(t, DirectionUp, steps[0].Direction)
+
+	mock.AssertStatements(t,
+		"CREATE TABLE IF NOT EXISTS gom_migrations",
+		"SELECT pg_try_advisory_lock($1)",
+		"SELECT version, name, checksum, applied_at FROM gom_migrations",
+		"BEGIN",
+		"CREATE TABLE users",
+		"INSERT INTO users",
+		"INSERT INTO gom_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)",
+		"COMMIT",
+		"BEGIN",
+		"INSERT INTO gom_migrations",
+		"COMMIT",
+		"SELECT pg_advisory_unlock($1)",
+	)
+	assert.Contains(t, out.String(), "1_create_users up")
+}
+
+func TestUpMySQLRunsDDLWithoutTransaction(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("GET_LOCK").Return(gomtest.NewRows("ok").AddRow(1))
+
+	m := New(db, Options{})
+	up, err := ParseSQL(1, "create_users", createUsers)
+	require.NoError(t, err)
+	require.NoError(t, m.Add(up))
+
+	_, err = m.Up(context.Background())
+	require.NoError(t, err)
+	mock.AssertNotExecuted(t, "BEGIN")
+	mock.AssertStatementsInOrder(t, "GET_LOCK", "CREATE TABLE users", "INSERT INTO gom_migrations", "RELEASE_LOCK")
+}
+
+func TestLockTimeout(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("GET_LOCK").Return(gomtest.NewRows("ok").AddRow(0))
+
+	m := New(db, Options{LockTimeout: time.Second})
+	_, err := m.Up(context.Background())
+	assert.ErrorContains(t, err, "timed out")
+	mock.AssertNotExecuted(t, "SELECT version")
+}
+
+func TestDownAndTo(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	mock.On("pg_try_advisory_lock").Return(gomtest.NewRows("ok").AddRow(true))
+
+	first, err := ParseSQL(1, "create_users", createUsers)
+	require.NoError(t, err)
+	second, err := ParseSQL(2, "add_email", "-- +gom Up\nALTER TABLE users ADD email TEXT;\n-- +gom Down\nALTER TABLE users DROP email;\n")
+	require.NoError(t, err)
+	now := time.Now()
+	mock.On("SELECT version").Return(history(
+		[]interface{}{int64(1), "create_users", first.Checksum, now},
+		[]interface{}{int64(2), "add_email", second.Checksum, now},
+	))
+
+	m := New(db, Options{})
+	require.NoError(t, m.Add(first, second))
+
+	steps, err := m.Down(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, int64(2), steps[0].Version)
+	mock.AssertStatementsInOrder(t, "ALTER TABLE users DROP email", "DELETE FROM gom_migrations WHERE version = $1")
+	mock.AssertNotExecuted(t, "DROP TABLE users")
+
+	mock.Reset()
+	_, err = m.Down(context.Background(), 0)
+	assert.EqualError(t, err, "invalid number of migrations to revert: 0 (must be > 0)")
+	mock.AssertStatements(t)
+
+	mock.On("pg_try_advisory_lock").Return(gomtest.NewRows("ok").AddRow(true))
+	mock.On("SELECT version").Return(history([]interface{}{int64(1), "create_users", first.Checksum, now}))
+	steps, err = m.To(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, DirectionUp, steps[0].Direction)
+
+	_, err = m.To(context.Background(), 7)
+	assert.ErrorContains(t, err, "unknown migration version 7")
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	mock.On("pg_try_advisory_lock").Return(gomtest.NewRows("ok").AddRow(true))
+	mock.On("SELECT version").Return(history([]interface{}{int64(1), "create_users", "edited", time.Now()}))
+
+	m := New(db, Options{})
+	up, err := ParseSQL(1, "create_users", createUsers)
+	require.NoError(t, err)
+	require.NoError(t, m.Add(up))
+
+	_, err = m.Up(context.Background())
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	statuses, err := m.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.True(t, statuses[0].Applied)
+	assert.True(t, statuses[0].Changed)
+}
+
+func TestStatusReportsMissing(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("SELECT version").Return(history([]interface{}{int64(9), "gone", "", time.Now()}))
+
+	m := New(db, Options{})
+	require.NoError(t, m.Add(Go(1, "seed", func(*gom.Chain) error { return nil }, nil)))
+
+	statuses, err := m.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Applied)
+	assert.True(t, statuses[1].Missing)
+}
+
+func TestDryRun(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+
+	var out bytes.Buffer
+	m := New(db, Options{DryRun: true, Out: &out})
+	up, err := ParseSQL(1, "create_users", createUsers)
+	require.NoError(t, err)
+	require.NoError(t, m.Add(up))
+
+	steps, err := m.Up(context.Background())
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	require.Len(t, steps[0].Statements, 3)
+	assert.Contains(t, steps[0].Statements[2].Sql, "INSERT INTO gom_migrations")
+
+	mock.AssertNotExecuted(t, "CREATE TABLE")
+	mock.AssertNotExecuted(t, "pg_try_advisory_lock")
+	assert.Contains(t, out.String(), "-- 1_create_users up")
+	assert.Contains(t, out.String(), "VALUES (1, 'create_users'")
+}
@@ -0,0 +1,246 @@
+package migrate
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kmlixh/gom/v4"
+)
+
+// Migration is a versioned schema change, either SQL statements or Go functions
+type Migration struct {
+	Version int64
+	Name    string
+
+	// Up and Down are the statements of a SQL migration
+	Up   []string
+	Down []string
+
+	// UpFunc and DownFunc run a Go migration; the chain is bound to the migration's transaction
+	UpFunc   func(tx *gom.Chain) error
+	DownFunc func(tx *gom.Chain) error
+
+	// NoTransaction runs the migration outside a transaction, e.g. for CREATE INDEX CONCURRENTLY
+	NoTransaction bool
+
+	// Checksum identifies the source of a SQL migration; it is empty for Go migrations
+	Checksum string
+}
+
+// Go creates a migration from Go functions; down may be nil for irreversible migrations
+func Go(version int64, name string, up, down func(tx *gom.Chain) error) *Migration {
+	return &Migration{Version: version, Name: name, UpFunc: up, DownFunc: down}
+}
+
+// String returns the version and name of the migration
+func (m *Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// hasDown reports whether the migration can be reverted
+func (m *Migration) hasDown() bool {
+	if m.UpFunc != nil {
+		return m.DownFunc != nil
+	}
+	return len(m.Down) > 0
+}
+
+// fileNamePattern matches migration files such as 20240101120000_create_users.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
+
+// directivePrefix starts the comment lines that structure a migration file
+const directivePrefix = "-- +gom "
+
+// FromFS reads the SQL migrations in dir of fsys, e.g. an embed.FS or os.DirFS
+// Files are named <version>_<name>.sql and split into sections by directive comments:
+//
+//	-- +gom Up
+//	CREATE TABLE users (id BIGINT PRIMARY KEY);
+//	-- +gom Down
+//	DROP TABLE users;
+//
+// A file without directives is an irreversible migration made only of Up statements.
+// "-- +gom NoTransaction" runs the file outside a transaction, and statements containing
+// semicolons, such as function bodies, go between "-- +gom StatementBegin" and "-- +gom StatementEnd"
+func FromFS(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []*Migration
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migration, err := ParseSQL(version, match[2], string(content))
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
+
+// ParseSQL parses the content of a SQL migration file
+func ParseSQL(version int64, name, content string) (*Migration, error) {
+	sum := sha256.Sum256([]byte(content))
+	m := &Migration{Version: version, Name: name, Checksum: hex.EncodeToString(sum[:])}
+
+	current := &m.Up
+	var text, block strings.Builder
+	inBlock := false
+	flush := func() {
+		*current = append(*current, splitStatements(text.String())...)
+		text.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, directivePrefix) {
+			if inBlock {
+				block.WriteString(line + "\n")
+			} else {
+				text.WriteString(line + "\n")
+			}
+			continue
+		}
+
+		switch directive := strings.ToLower(strings.TrimSpace(trimmed[len(directivePrefix):])); directive {
+		case "up", "down":
+			if inBlock {
+				return nil, fmt.Errorf("line %d: %s inside a statement block", lineNo, directive)
+			}
+			flush()
+			if directive == "up" {
+				current = &m.Up
+			} else {
+				current = &m.Down
+			}
+		case "notransaction":
+			m.NoTransaction = true
+		case "statementbegin":
+			if inBlock {
+				return nil, fmt.Errorf("line %d: nested StatementBegin", lineNo)
+			}
+			flush()
+			inBlock = true
+		case "statementend":
+			if !inBlock {
+				return nil, fmt.Errorf("line %d: StatementEnd without StatementBegin", lineNo)
+			}
+			if stmt := strings.TrimSuffix(strings.TrimSpace(block.String()), ";"); stmt != "" {
+				*current = append(*current, stmt)
+			}
+			block.Reset()
+			inBlock = false
+		default:
+			return nil, fmt.Errorf("line %d: unknown directive %q", lineNo, trimmed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if inBlock {
+		return nil, fmt.Errorf("StatementBegin without StatementEnd")
+	}
+	flush()
+	return m, nil
+}
+
+// splitStatements splits sql on semicolons outside quotes, comments and dollar-quoted bodies
+// Statements made only of comments are dropped
+func splitStatements(sql string) []string {
+	var statements []string
+	start := 0
+	add := func(end int) {
+		if stmt := strings.TrimSpace(sql[start:end]); stmt != "" && !onlyComments(stmt) {
+			statements = append(statements, stmt)
+		}
+	}
+
+	for i := 0; i < len(sql); i++ {
+		switch ch := sql[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
+			for i++; i < len(sql) && sql[i] != ch; i++ {
+				if sql[i] == '\\' && ch == '\'' {
+					i++
+				}
+			}
+		case ch == '-' && strings.HasPrefix(sql[i:], "--"):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case ch == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case ch == '$':
+			if tag := dollarTag(sql[i:]); tag != "" {
+				if end := strings.Index(sql[i+len(tag):], tag); end >= 0 {
+					i += len(tag) + end + len(tag) - 1
+				} else {
+					i = len(sql)
+				}
+			}
+		case ch == ';':
+			add(i)
+			start = i + 1
+		}
+	}
+	if start < len(sql) {
+		add(len(sql))
+	}
+	return statements
+}
+
+// dollarTag returns the PostgreSQL dollar-quote tag at the start of s, such as $$ or $body$
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		if ch == '$' {
+			return s[:i+1]
+		}
+		if !(ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || i > 1 && ch >= '0' && ch <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+// onlyComments reports whether stmt contains nothing but comments
+func onlyComments(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			if !(strings.HasPrefix(line, "/*") && strings.HasSuffix(line, "*/")) {
+				return false
+			}
+		}
+	}
+	return true
+}
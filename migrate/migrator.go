@@ -0,0 +1,543 @@
+// Package migrate applies versioned schema migrations with gom
+//
+// Migrations come from SQL files (see FromFS) or Go functions (see Go). Applied versions are
+// recorded with their checksums in a history table, and a database lock keeps concurrent
+// migrators, such as replicas starting together, from racing:
+//
+//	m := migrate.New(db, migrate.Options{})
+//	if err := m.AddFS(migrationsFS, "migrations"); err != nil { ... }
+//	steps, err := m.Up(ctx)
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kmlixh/gom/v4"
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// DefaultTable is the history table used when Options.Table is empty
+const DefaultTable = "gom_migrations"
+
+// ErrChecksumMismatch is returned when an applied SQL migration was edited afterwards
+var ErrChecksumMismatch = errors.New("migration changed after it was applied")
+
+// Direction tells whether a step applies or reverts a migration
+type Direction string
+
+const (
+	DirectionUp   Direction = "up"
+	DirectionDown Direction = "down"
+)
+
+// Options configures a Migrator
+type Options struct {
+	// Table is the history table, defaults to DefaultTable
+	Table string
+
+	// LockTimeout bounds the wait for another migrator's lock, defaults to one minute
+	LockTimeout time.Duration
+
+	// DryRun reports the statements of each step without executing them or taking the lock
+	DryRun bool
+
+	// Out receives a line per step; in dry-run mode also the interpolated statements
+	Out io.Writer
+}
+
+// Step is a migration applied or reverted by Up, Down or To
+type Step struct {
+	Version    int64
+	Name       string
+	Direction  Direction
+	Statements []*define.SqlProto // Filled in dry-run mode only
+	Duration   time.Duration
+}
+
+// Status describes a migration known to the Migrator or recorded in the history table
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Changed   bool // Applied with a different checksum
+	Missing   bool // Recorded in the history table but not known to the Migrator
+}
+
+// Migrator applies migrations to a DB
+type Migrator struct {
+	db         *gom.DB
+	opts       Options
+	migrations []*Migration
+}
+
+// record is a row of the history table
+type record struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// New creates a Migrator for db
+func New(db *gom.DB, opts Options) *Migrator {
+	if opts.Table == "" {
+		opts.Table = DefaultTable
+	}
+	if opts.LockTimeout <= 0 {
+		opts.LockTimeout = time.Minute
+	}
+	if opts.Out == nil {
+		opts.Out = io.Discard
+	}
+	return &Migrator{db: db, opts: opts}
+}
+
+// Add registers migrations; versions must be unique
+func (m *Migrator) Add(migrations ...*Migration) error {
+	known := make(map[int64]*Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+	for _, migration := range migrations {
+		if migration.Version <= 0 {
+			return fmt.Errorf("migration %s: version must be positive", migration)
+		}
+		if other, ok := known[migration.Version]; ok {
+			return fmt.Errorf("duplicate migration version %d: %s and %s", migration.Version, other, migration)
+		}
+		known[migration.Version] = migration
+		m.migrations = append(m.migrations, migration)
+	}
+	sort.Slice(m.migrations, func(i, j int) bool {
+		return m.migrations[i].Version < m.migrations[j].Version
+	})
+	return nil
+}
+
+// AddFS registers the SQL migrations in dir of fsys, see FromFS
+func (m *Migrator) AddFS(fsys fs.FS, dir string) error {
+	migrations, err := FromFS(fsys, dir)
+	if err != nil {
+		return err
+	}
+	return m.Add(migrations...)
+}
+
+// Migrations returns the registered migrations in version order
+func (m *Migrator) Migrations() []*Migration {
+	return append([]*Migration(nil), m.migrations...)
+}
+
+// Status reports every registered migration and every recorded version, in version order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	seen := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		seen[migration.Version] = true
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if rec, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = rec.appliedAt
+			status.Changed = changed(migration, rec)
+		}
+		statuses = append(statuses, status)
+	}
+	for version, rec := range applied {
+		if !seen[version] {
+			statuses = append(statuses, Status{Version: version, Name: rec.name, Applied: true, AppliedAt: rec.appliedAt, Missing: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Up applies every pending migration in version order
+func (m *Migrator) Up(ctx context.Context) ([]Step, error) {
+	return m.run(ctx, func(applied map[int64]record) ([]*Migration, Direction, error) {
+		return m.pending(applied, 0), DirectionUp, nil
+	})
+}
+
+// Down reverts the last n applied migrations, newest first
+// Use To(ctx, 0) to revert every migration
+func (m *Migrator) Down(ctx context.Context, n int) ([]Step, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of migrations to revert: %d (must be > 0)", n)
+	}
+	return m.run(ctx, func(applied map[int64]record) ([]*Migration, Direction, error) {
+		migrations, err := m.appliedDesc(applied, func(int64) bool { return true })
+		if err != nil {
+			return nil, "", err
+		}
+		if n < len(migrations) {
+			migrations = migrations[:n]
+		}
+		return migrations, DirectionDown, nil
+	})
+}
+
+// To migrates up or down so that version is the newest applied migration
+// Version 0 reverts every migration
+func (m *Migrator) To(ctx context.Context, version int64) ([]Step, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.run(ctx, func(applied map[int64]record) ([]*Migration, Direction, error) {
+		down, err := m.appliedDesc(applied, func(v int64) bool { return v > version })
+		if err != nil {
+			return nil, "", err
+		}
+		if len(down) > 0 {
+			return down, DirectionDown, nil
+		}
+		return m.pending(applied, version), DirectionUp, nil
+	})
+}
+
+// run locks the history, plans the steps and executes them
+func (m *Migrator) run(ctx context.Context, plan func(applied map[int64]record) ([]*Migration, Direction, error)) ([]Step, error) {
+	if !m.opts.DryRun {
+		if err := m.ensureTable(ctx); err != nil {
+			return nil, err
+		}
+		unlock, err := m.lock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range m.migrations {
+		if rec, ok := applied[migration.Version]; ok && changed(migration, rec) {
+			return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, migration)
+		}
+	}
+
+	migrations, direction, err := plan(applied)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []Step
+	for _, migration := range migrations {
+		step, err := m.apply(ctx, migration, direction)
+		if err != nil {
+			return steps, fmt.Errorf("migration %s %s failed: %w", migration, direction, err)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// pending returns the unapplied migrations up to version, or all of them when version is 0
+func (m *Migrator) pending(applied map[int64]record, version int64) []*Migration {
+	var migrations []*Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok && (version == 0 || migration.Version <= version) {
+			migrations = append(migrations, migration)
+		}
+	}
+	return migrations
+}
+
+// appliedDesc returns the applied migrations selected by include, newest first
+func (m *Migrator) appliedDesc(applied map[int64]record, include func(int64) bool) ([]*Migration, error) {
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		if include(version) {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	migrations := make([]*Migration, 0, len(versions))
+	for _, version := range versions {
+		migration := m.find(version)
+		if migration == nil {
+			return nil, fmt.Errorf("applied migration %d (%s) is missing", version, applied[version].name)
+		}
+		if !migration.hasDown() {
+			return nil, fmt.Errorf("migration %s cannot be reverted", migration)
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+// apply runs one migration in the given direction and updates the history table
+// SQL migrations run in a transaction only where the dialect has transactional DDL
+func (m *Migrator) apply(ctx context.Context, migration *Migration, direction Direction) (Step, error) {
+	step := Step{Version: migration.Version, Name: migration.Name, Direction: direction}
+	start := time.Now()
+
+	var err error
+	switch {
+	case m.opts.DryRun:
+		chain := m.db.Chain().SetContext(ctx).DryRun()
+		err = m.exec(chain, migration, direction)
+		step.Statements = chain.Statements()
+	case migration.NoTransaction || (migration.UpFunc == nil && m.dialect() == "mysql"):
+		err = m.exec(m.db.Chain().SetContext(ctx), migration, direction)
+	default:
+		err = m.db.Chain().SetContext(ctx).Transaction(func(tx *gom.Chain) error {
+			return m.exec(tx, migration, direction)
+		})
+	}
+	if err != nil {
+		return step, err
+	}
+	step.Duration = time.Since(start)
+
+	if m.opts.DryRun {
+		fmt.Fprintf(m.opts.Out, "-- %s %s\n", migration, direction)
+		for _, proto := range step.Statements {
+			fmt.Fprintf(m.opts.Out, "%s;\n", m.db.Interpolate(proto))
+		}
+	} else {
+		fmt.Fprintf(m.opts.Out, "%s %s (%s)\n", migration, direction, step.Duration.Round(time.Millisecond))
+	}
+	return step, nil
+}
+
+// exec runs the migration's statements or function and records the result on chain
+func (m *Migrator) exec(chain *gom.Chain, migration *Migration, direction Direction) error {
+	fn, statements := migration.UpFunc, migration.Up
+	if direction == DirectionDown {
+		fn, statements = migration.DownFunc, migration.Down
+	}
+	if fn != nil {
+		if err := fn(chain); err != nil {
+			return err
+		}
+	}
+	for _, statement := range statements {
+		if result := chain.RawExecute(statement); result.Error != nil {
+			return result.Error
+		}
+	}
+
+	var result define.Result
+	if direction == DirectionUp {
+		result = chain.RawExecute(
+			fmt.Sprintf("INSERT INTO %s (version, name, checksum, applied_at) VALUES (%s, %s, %s, %s)",
+				m.opts.Table, m.placeholder(1), m.placeholder(2), m.placeholder(3), m.placeholder(4)),
+			migration.Version, migration.Name, migration.Checksum, time.Now().UTC())
+	} else {
+		result = chain.RawExecute(
+			fmt.Sprintf("DELETE FROM %s WHERE version = %s", m.opts.Table, m.placeholder(1)),
+			migration.Version)
+	}
+	return result.Error
+}
+
+// ensureTable creates the history table if needed
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	timeType := "TIMESTAMP"
+	if m.dialect() == "mysql" {
+		timeType = "DATETIME"
+	}
+	result := m.db.Chain().SetContext(ctx).RawExecute(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, checksum VARCHAR(64) NOT NULL, applied_at %s NOT NULL)",
+		m.opts.Table, timeType))
+	if result.Error != nil {
+		return fmt.Errorf("failed to create %s: %w", m.opts.Table, result.Error)
+	}
+	return nil
+}
+
+// applied loads the history table; in dry-run mode a missing table means nothing is applied
+func (m *Migrator) applied(ctx context.Context) (map[int64]record, error) {
+	applied := make(map[int64]record)
+	if m.opts.DryRun {
+		exists, err := m.tableExists()
+		if err != nil || !exists {
+			return applied, err
+		}
+	}
+
+	result := m.db.Chain().SetContext(ctx).RawQuery(
+		fmt.Sprintf("SELECT version, name, checksum, applied_at FROM %s ORDER BY version", m.opts.Table))
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", m.opts.Table, result.Error)
+	}
+	for _, row := range result.Data {
+		version, err := toInt64(row["version"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid version in %s: %w", m.opts.Table, err)
+		}
+		applied[version] = record{
+			version:   version,
+			name:      toString(row["name"]),
+			checksum:  toString(row["checksum"]),
+			appliedAt: toTime(row["applied_at"]),
+		}
+	}
+	return applied, nil
+}
+
+// tableExists reports whether the history table exists
+func (m *Migrator) tableExists() (bool, error) {
+	tables, err := m.db.GetTables(m.opts.Table)
+	if err != nil {
+		return false, err
+	}
+	for _, table := range tables {
+		if table == m.opts.Table || table == "public."+m.opts.Table {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// lock takes a session-level database lock on a dedicated connection and returns its release
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	dialect := m.dialect()
+	if dialect != "mysql" && dialect != "postgres" {
+		return func() {}, nil
+	}
+	sqlDB := m.db.GetDB()
+	if sqlDB == nil {
+		return nil, errors.New("migrate needs a DB created by gom.Open or gom.OpenDB to take its lock")
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "gom_migrate:" + m.opts.Table
+	if dialect == "mysql" {
+		var ok sql.NullInt64
+		err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, int(m.opts.LockTimeout.Seconds())).Scan(&ok)
+		if err == nil && ok.Int64 != 1 {
+			err = fmt.Errorf("timed out waiting for lock %s", name)
+		}
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return func() {
+			conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+			conn.Close()
+		}, nil
+	}
+
+	key := lockKey(name)
+	deadline := time.Now().Add(m.opts.LockTimeout)
+	for {
+		var ok bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if ok {
+			return func() {
+				conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+				conn.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			conn.Close()
+			return nil, fmt.Errorf("timed out waiting for lock %s", name)
+		}
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			return nil, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// lockKey maps a lock name to a PostgreSQL advisory lock key
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+func (m *Migrator) dialect() string {
+	return m.db.Factory.GetType()
+}
+
+// placeholder returns the n-th bind parameter in the DB's dialect
+func (m *Migrator) placeholder(n int) string {
+	if m.dialect() == "postgres" {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// changed reports whether an applied SQL migration no longer matches its source
+func changed(migration *Migration, rec record) bool {
+	return migration.Checksum != "" && rec.checksum != "" && migration.Checksum != rec.checksum
+}
+
+func toInt64(v interface{}) (int64, error) {
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, fmt.Errorf("unexpected type %T", v)
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+func toTime(v interface{}) time.Time {
+	if t, ok := v.(time.Time); ok {
+		return t
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, toString(v)); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
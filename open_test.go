@@ -0,0 +1,102 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/gomtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockPool(t *testing.T) (*sql.DB, *gomtest.Mock) {
+	mock := gomtest.New()
+	t.Cleanup(mock.Close)
+	sqlDB, err := sql.Open(gomtest.DriverName, mock.DSN())
+	assert.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlDB, mock
+}
+
+func TestOpenDBLeavesPoolToCaller(t *testing.T) {
+	sqlDB, mock := newMockPool(t)
+	sqlDB.SetMaxOpenConns(3)
+
+	db, err := OpenDB(sqlDB, "postgres", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, db.Stats().MaxOpenConnections)
+
+	result := db.Chain().Table("users").Where("id", define.OpEq, 1).Delete()
+	assert.NoError(t, result.Error)
+	mock.AssertStatements(t, `DELETE FROM "users" WHERE "id" = $1`)
+
+	assert.NoError(t, db.Close())
+	assert.NoError(t, sqlDB.Ping())
+}
+
+func TestBatchInsertKeepsCallerPoolLimit(t *testing.T) {
+	sqlDB, mock := newMockPool(t)
+	sqlDB.SetMaxOpenConns(1)
+	db, err := OpenDB(sqlDB, "mysql", nil)
+	assert.NoError(t, err)
+
+	values := make([]map[string]interface{}, 100)
+	for i := range values {
+		values[i] = map[string]interface{}{"id": i}
+	}
+	_, err = db.Chain().Table("users").BatchValues(values).BatchInsert(10, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, db.Stats().MaxOpenConnections)
+	assert.Len(t, mock.Statements(), 30)
+}
+
+func TestOpenDBOwnPool(t *testing.T) {
+	sqlDB, _ := newMockPool(t)
+	opts := define.DefaultDBOptions()
+	opts.OwnPool = true
+	opts.MaxOpenConns = 7
+
+	db, err := OpenDB(sqlDB, "mysql", &opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, db.Stats().MaxOpenConnections)
+
+	assert.NoError(t, db.Close())
+	assert.Error(t, sqlDB.Ping())
+}
+
+func TestOpenDBUnknownDialect(t *testing.T) {
+	sqlDB, _ := newMockPool(t)
+	_, err := OpenDB(sqlDB, "oracle", nil)
+	assert.ErrorContains(t, err, "oracle")
+}
+
+func TestWrapConnRunsOnConnection(t *testing.T) {
+	sqlDB, mock := newMockPool(t)
+	conn, err := sqlDB.Conn(context.Background())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	db, err := WrapConn(conn, "mysql", nil)
+	assert.NoError(t, err)
+
+	err = db.Chain().Transaction(func(tx *Chain) error {
+		return tx.Table("users").Set("name", "ann").Where("id", define.OpEq, 1).Update().Error
+	})
+	assert.NoError(t, err)
+
+	affected, err := db.Chain().Table("users").BatchValues([]map[string]interface{}{
+		{"name": "a"}, {"name": "b"},
+	}).BatchInsert(1, true)
+	assert.NoError(t, err)
+	assert.Zero(t, affected)
+
+	mock.AssertStatements(t,
+		"BEGIN", "UPDATE `users` SET `name` = ? WHERE `id` = ?", "COMMIT",
+		"BEGIN", "INSERT INTO `users`", "COMMIT",
+		"BEGIN", "INSERT INTO `users`", "COMMIT")
+	assert.Equal(t, sql.DBStats{}, db.Stats())
+
+	assert.NoError(t, db.Close())
+	assert.NoError(t, conn.PingContext(context.Background()))
+}
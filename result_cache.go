@@ -0,0 +1,208 @@
+package gom
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// defaultResultCacheSize bounds the cache Open creates when DBOptions.ResultCache is nil
+const defaultResultCacheSize = 1024
+
+// writeOperations are statement verbs that modify table contents or structure
+var writeOperations = map[string]bool{
+	"INSERT":   true,
+	"UPDATE":   true,
+	"DELETE":   true,
+	"REPLACE":  true,
+	"MERGE":    true,
+	"UPSERT":   true,
+	"TRUNCATE": true,
+	"ALTER":    true,
+	"DROP":     true,
+	"RENAME":   true,
+}
+
+// allTables marks a write whose table could not be determined
+const allTables = ""
+
+// resultCache wraps the DB result cache and defers invalidation of transactional writes
+type resultCache struct {
+	cache   define.Cache
+	mu      sync.Mutex
+	pending map[uint64]map[string]struct{} // Tables written by each open transaction
+}
+
+// newResultCache creates a resultCache around cache
+func newResultCache(cache define.Cache) *resultCache {
+	return &resultCache{cache: cache, pending: make(map[uint64]map[string]struct{})}
+}
+
+// written invalidates tables, or defers it until txID commits when inside a transaction
+func (r *resultCache) written(txID uint64, tables []string) {
+	if r == nil {
+		return
+	}
+	if txID == 0 {
+		r.invalidate(tables)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	set, ok := r.pending[txID]
+	if !ok {
+		set = make(map[string]struct{})
+		r.pending[txID] = set
+	}
+	for _, table := range tables {
+		set[table] = struct{}{}
+	}
+}
+
+// commit applies the invalidations deferred by txID
+func (r *resultCache) commit(txID uint64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	set := r.pending[txID]
+	delete(r.pending, txID)
+	r.mu.Unlock()
+
+	tables := make([]string, 0, len(set))
+	for table := range set {
+		tables = append(tables, table)
+	}
+	r.invalidate(tables)
+}
+
+// discard drops the invalidations deferred by a rolled back transaction
+func (r *resultCache) discard(txID uint64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, txID)
+}
+
+// invalidate removes the entries of tables, or every entry when a table is unknown
+func (r *resultCache) invalidate(tables []string) {
+	if len(tables) == 0 {
+		return
+	}
+	for _, table := range tables {
+		if table == allTables {
+			r.cache.Clear()
+			return
+		}
+	}
+	r.cache.InvalidateTables(tables...)
+}
+
+// Cache enables result caching for List, First and Count on this chain
+// Results are keyed by SQL and arguments and tagged with the queried tables;
+// writes through the same DB invalidate them. Queries inside a transaction bypass the cache
+func (c *Chain) Cache(ttl time.Duration) *Chain {
+	c.cacheTTL = ttl
+	return c
+}
+
+// cacheEnabled reports whether the chain reads through the result cache
+func (c *Chain) cacheEnabled() bool {
+	return c.cacheTTL > 0 && c.tx == nil && c.dryRun == nil && c.db != nil && c.db.results != nil
+}
+
+// executeCached runs a SELECT through the result cache
+func (c *Chain) executeCached(sqlProto *define.SqlProto) *define.Result {
+	key := resultCacheKey(sqlProto)
+	if cached, ok := c.db.results.cache.Get(key); ok {
+		return copyResult(cached)
+	}
+	result := c.executeSqlProto(sqlProto)
+	if result.Error == nil {
+		c.db.results.cache.Set(key, copyResult(result), c.cacheTTL, c.cacheTables())
+	}
+	return result
+}
+
+// cacheTables returns the tables a SELECT built by the chain reads
+func (c *Chain) cacheTables() []string {
+	tables := []string{normalizeTableName(c.tableName)}
+	for _, field := range c.fieldList {
+		upper := strings.ToUpper(field)
+		if idx := strings.Index(upper, "JOIN "); idx >= 0 {
+			if parts := strings.Fields(field[idx+len("JOIN "):]); len(parts) > 0 {
+				tables = append(tables, normalizeTableName(parts[0]))
+			}
+		}
+	}
+	return tables
+}
+
+// recordWrite invalidates the cached results of the tables written by proto
+// The table is read from the SQL rather than proto.Table, which defaults to the chain table
+// and so is wrong for raw statements; statements it cannot be read from clear the whole cache
+func (c *Chain) recordWrite(proto *define.SqlProto) {
+	if c.db.results == nil || !writeOperations[proto.Operation()] {
+		return
+	}
+	var txID uint64
+	if c.tx != nil {
+		txID = c.txID
+	}
+	c.db.results.written(txID, []string{normalizeTableName(writtenTable(proto.Sql))})
+}
+
+// writtenTable extracts the target table of a write statement, or returns allTables
+func writtenTable(sql string) string {
+	fields := strings.Fields(sql)
+	for i := 0; i+1 < len(fields); i++ {
+		switch strings.ToUpper(fields[i]) {
+		case "INTO", "UPDATE", "FROM", "TABLE":
+			next := fields[i+1]
+			if strings.EqualFold(next, "ONLY") || strings.EqualFold(next, "IF") {
+				continue
+			}
+			if end := strings.IndexAny(next, "(;"); end >= 0 {
+				next = next[:end]
+			}
+			return next
+		}
+	}
+	return allTables
+}
+
+// normalizeTableName strips quotes and the schema and lower-cases a table name so tags match,
+// e.g. "public"."Users" and users are both tagged users
+func normalizeTableName(table string) string {
+	table = strings.NewReplacer("`", "", `"`, "").Replace(table)
+	if idx := strings.LastIndexByte(table, '.'); idx >= 0 {
+		table = table[idx+1:]
+	}
+	return strings.ToLower(table)
+}
+
+// resultCacheKey identifies a statement by its SQL text and arguments
+func resultCacheKey(proto *define.SqlProto) string {
+	return fmt.Sprintf("%s\x00%#v", proto.Sql, proto.Args)
+}
+
+// copyResult copies the rows of result so callers cannot modify cached data
+func copyResult(result *define.Result) *define.Result {
+	copied := *result
+	if result.Data != nil {
+		copied.Data = make([]map[string]any, len(result.Data))
+		for i, row := range result.Data {
+			copiedRow := make(map[string]any, len(row))
+			for k, v := range row {
+				copiedRow[k] = v
+			}
+			copied.Data[i] = copiedRow
+		}
+	}
+	return &copied
+}
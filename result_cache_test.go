@@ -0,0 +1,127 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCachingTestDB returns a DB whose SELECTs are answered and counted by an interceptor
+// Writes run against the counting driver so the cache sees them executed
+func newCachingTestDB(t *testing.T) (*DB, *int) {
+	sqlDB := sql.OpenDB(newTestConnector(&prepareCountingDriver{}))
+	t.Cleanup(func() { sqlDB.Close() })
+	db := &DB{DB: sqlDB, results: newResultCache(define.NewLRUCache(10))}
+	selects := 0
+	db.Use(func(ctx context.Context, proto *define.SqlProto, next Handler) *define.Result {
+		if proto.Operation() == "SELECT" {
+			selects++
+			return &define.Result{Data: []map[string]interface{}{{"code": "NZ"}}}
+		}
+		return next(ctx, proto)
+	})
+	return db, &selects
+}
+
+func TestResultCacheHitAndInvalidation(t *testing.T) {
+	db, selects := newCachingTestDB(t)
+	query := func() *define.Result {
+		chain := (&Chain{db: db, tableName: "countries"}).Cache(time.Minute)
+		return chain.executeCached(&define.SqlProto{SqlType: define.Query, Sql: "SELECT * FROM countries WHERE code = ?", Args: []any{"NZ"}})
+	}
+
+	first := query()
+	first.Data[0]["code"] = "modified"
+	second := query()
+	assert.Equal(t, 1, *selects)
+	assert.Equal(t, "NZ", second.Data[0]["code"], "cached rows must not be shared with callers")
+
+	// Writes to another table keep the entry
+	(&Chain{db: db}).RawExecute("UPDATE plans SET price = ?", 10)
+	query()
+	assert.Equal(t, 1, *selects)
+
+	(&Chain{db: db}).RawExecute("DELETE FROM `countries` WHERE code = ?", "NZ")
+	query()
+	assert.Equal(t, 2, *selects)
+
+	// Raw statements on a chain with a table invalidate the table in the SQL
+	(&Chain{db: db, tableName: "countries"}).RawExecute("UPDATE plans SET price = ?", 20)
+	query()
+	assert.Equal(t, 2, *selects)
+	(&Chain{db: db, tableName: "plans"}).RawExecute(`UPDATE "public"."Countries" SET name = ?`, "New Zealand")
+	query()
+	assert.Equal(t, 3, *selects)
+
+	// Writes whose table cannot be read clear everything
+	(&Chain{db: db, tableName: "plans"}).RawExecute("TRUNCATE countries")
+	query()
+	assert.Equal(t, 4, *selects)
+}
+
+func TestResultCacheDefersInvalidationUntilCommit(t *testing.T) {
+	db, selects := newCachingTestDB(t)
+	query := func() {
+		chain := (&Chain{db: db, tableName: "plans"}).Cache(time.Minute)
+		chain.executeCached(&define.SqlProto{SqlType: define.Query, Sql: "SELECT * FROM plans"})
+	}
+	query()
+
+	txChain, err := (&Chain{db: db, tableName: "plans"}).beginTx()
+	assert.NoError(t, err)
+	txChain.RawExecute("INSERT INTO plans (name) VALUES (?)", "pro")
+	query()
+	assert.Equal(t, 1, *selects, "uncommitted writes must not invalidate")
+	assert.NoError(t, txChain.commit())
+	query()
+	assert.Equal(t, 2, *selects)
+
+	txChain, err = (&Chain{db: db, tableName: "plans"}).beginTx()
+	assert.NoError(t, err)
+	txChain.RawExecute("INSERT INTO plans (name) VALUES (?)", "team")
+	assert.NoError(t, txChain.rollback())
+	query()
+	assert.Equal(t, 2, *selects, "rolled back writes must not invalidate")
+}
+
+func TestWrittenTable(t *testing.T) {
+	assert.Equal(t, "users", writtenTable("INSERT INTO users(name) VALUES (?)"))
+	assert.Equal(t, "users", writtenTable("update users set name = ?"))
+	assert.Equal(t, "`users`", writtenTable("DELETE FROM `users` WHERE id = ?"))
+	assert.Equal(t, "users", writtenTable("TRUNCATE TABLE users"))
+	assert.Equal(t, allTables, writtenTable("TRUNCATE users"))
+}
+
+func TestNormalizeTableName(t *testing.T) {
+	assert.Equal(t, "users", normalizeTableName("`Users`"))
+	assert.Equal(t, "users", normalizeTableName(`"public"."users"`))
+	assert.Equal(t, "orders", normalizeTableName("shop.orders"))
+}
+
+func TestLRUCacheExpiryAndTags(t *testing.T) {
+	cache := define.NewLRUCache(2)
+	cache.Set("a", &define.Result{}, time.Minute, []string{"users"})
+	cache.Set("b", &define.Result{}, time.Minute, []string{"orders"})
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+	cache.Set("c", &define.Result{}, time.Minute, []string{"users", "orders"})
+	assert.Equal(t, 2, cache.Len())
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok, "b was evicted as least recently used")
+
+	cache.InvalidateTables("orders")
+	_, ok = cache.Get("c")
+	assert.False(t, ok)
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+
+	cache.Set("d", &define.Result{}, time.Nanosecond, nil)
+	time.Sleep(time.Millisecond)
+	_, ok = cache.Get("d")
+	assert.False(t, ok, "d expired")
+}
@@ -0,0 +1,254 @@
+package gom
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// StmtCacheStats reports prepared statement cache usage
+type StmtCacheStats struct {
+	Hits          int64 // Lookups served by a cached statement
+	Misses        int64 // Lookups that prepared a new statement
+	Evictions     int64 // Statements closed to respect the size limit
+	Invalidations int64 // Statements dropped after schema changes or plan errors
+	Size          int   // Statements currently cached
+}
+
+// stmtCache is an LRU of prepared statements keyed by SQL text
+type stmtCache struct {
+	mu      sync.Mutex
+	db      executor
+	maxSize int
+	order   *list.List // Front is most recently used
+	entries map[string]*list.Element
+
+	hits          int64
+	misses        int64
+	evictions     int64
+	invalidations int64
+}
+
+type stmtEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int  // Callers between get and release; guarded by stmtCache.mu
+	removed bool // Unlinked from the cache; the last release closes stmt
+}
+
+// newStmtCache creates a cache holding at most maxSize statements of db
+func newStmtCache(db executor, maxSize int) *stmtCache {
+	return &stmtCache{
+		db:      db,
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached statement for query, preparing it on a miss
+// The statement stays open until release is called, even if it is evicted or invalidated meanwhile
+func (c *stmtCache) get(ctx context.Context, query string) (stmt *sql.Stmt, release func(), err error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[query]; ok {
+		c.order.MoveToFront(elem)
+		entry := c.acquire(elem)
+		c.mu.Unlock()
+		atomic.AddInt64(&c.hits, 1)
+		return entry.stmt, func() { c.release(entry) }, nil
+	}
+	c.mu.Unlock()
+	atomic.AddInt64(&c.misses, 1)
+
+	// Prepare outside the lock; a concurrent miss on the same query keeps the first statement
+	stmt, err = c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[query]; ok {
+		stmt.Close()
+		c.order.MoveToFront(elem)
+		entry := c.acquire(elem)
+		return entry.stmt, func() { c.release(entry) }, nil
+	}
+	entry := &stmtEntry{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxSize {
+		c.removeElement(c.order.Back())
+		atomic.AddInt64(&c.evictions, 1)
+	}
+	return stmt, func() { c.release(entry) }, nil
+}
+
+// acquire takes a reference on the entry of elem; the caller holds c.mu
+func (c *stmtCache) acquire(elem *list.Element) *stmtEntry {
+	entry := elem.Value.(*stmtEntry)
+	entry.refs++
+	return entry
+}
+
+// release drops a reference taken by get and closes the statement once it left the cache and is unused
+func (c *stmtCache) release(entry *stmtEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.refs--
+	if entry.removed && entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// remove drops the statement for query
+func (c *stmtCache) remove(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[query]; ok {
+		c.removeElement(elem)
+		atomic.AddInt64(&c.invalidations, 1)
+	}
+}
+
+// reset drops every cached statement
+func (c *stmtCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	atomic.AddInt64(&c.invalidations, int64(c.order.Len()))
+	for c.order.Len() > 0 {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// removeElement unlinks elem and closes its statement unless a caller still holds it; the caller holds c.mu
+// A statement closed between get and its use would fail with "sql: statement is closed",
+// so statements in use are closed by their last release instead
+func (c *stmtCache) removeElement(elem *list.Element) {
+	entry := c.order.Remove(elem).(*stmtEntry)
+	delete(c.entries, entry.query)
+	entry.removed = true
+	if entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// stats returns the cache counters
+func (c *stmtCache) stats() StmtCacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+	return StmtCacheStats{
+		Hits:          atomic.LoadInt64(&c.hits),
+		Misses:        atomic.LoadInt64(&c.misses),
+		Evictions:     atomic.LoadInt64(&c.evictions),
+		Invalidations: atomic.LoadInt64(&c.invalidations),
+		Size:          size,
+	}
+}
+
+// StmtCacheStats returns the prepared statement cache counters
+// All counters are zero when DBOptions.StmtCacheSize is not set
+func (db *DB) StmtCacheStats() StmtCacheStats {
+	if db.stmts == nil {
+		return StmtCacheStats{}
+	}
+	return db.stmts.stats()
+}
+
+// ddlOperations are statement verbs that change the schema
+var ddlOperations = map[string]bool{
+	"CREATE":   true,
+	"ALTER":    true,
+	"DROP":     true,
+	"TRUNCATE": true,
+	"RENAME":   true,
+	"COMMENT":  true,
+}
+
+// invalidateStatements drops every cached prepared statement
+func (db *DB) invalidateStatements() {
+	if db.stmts != nil {
+		db.stmts.reset()
+	}
+}
+
+// isStalePlanError reports whether err means a cached statement no longer matches the schema
+func isStalePlanError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "cached plan must not change result type") ||
+		strings.Contains(msg, "Prepared statement needs to be re-prepared")
+}
+
+// usesStmtCache reports whether query should go through the statement cache
+// DDL is never prepared since it changes the schema the cached statements depend on
+func (c *Chain) usesStmtCache(query string) bool {
+	return c.db.stmts != nil && !ddlOperations[(&define.SqlProto{Sql: query}).Operation()]
+}
+
+// cachedStmt returns the cached statement for query bound to the chain transaction, if any
+// release must be called once the statement has been run
+func (c *Chain) cachedStmt(ctx context.Context, query string) (stmt *sql.Stmt, release func(), err error) {
+	stmt, release, err = c.db.stmts.get(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.tx != nil {
+		// The transaction-specific statement is closed with the transaction
+		return c.tx.StmtContext(ctx, stmt), release, nil
+	}
+	return stmt, release, nil
+}
+
+// preparedQueryContext runs a query through the statement cache when it is enabled
+// A statement invalidated by a schema change is dropped and, outside transactions, retried once
+func (c *Chain) preparedQueryContext(ctx context.Context, query string, args []interface{}) (*sql.Rows, error) {
+	if !c.usesStmtCache(query) {
+		return c.queryContext(ctx, query, args)
+	}
+	stmt, release, err := c.cachedStmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.QueryContext(ctx, args...)
+	release()
+	if err != nil && isStalePlanError(err) {
+		c.db.stmts.remove(query)
+		if c.tx == nil {
+			if stmt, release, err = c.cachedStmt(ctx, query); err != nil {
+				return nil, err
+			}
+			defer release()
+			return stmt.QueryContext(ctx, args...)
+		}
+	}
+	return rows, err
+}
+
+// preparedExecContext runs a statement through the statement cache when it is enabled
+func (c *Chain) preparedExecContext(ctx context.Context, query string, args []interface{}) (sql.Result, error) {
+	if !c.usesStmtCache(query) {
+		return c.execContext(ctx, query, args)
+	}
+	stmt, release, err := c.cachedStmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result, err := stmt.ExecContext(ctx, args...)
+	release()
+	if err != nil && isStalePlanError(err) {
+		c.db.stmts.remove(query)
+		if c.tx == nil {
+			if stmt, release, err = c.cachedStmt(ctx, query); err != nil {
+				return nil, err
+			}
+			defer release()
+			return stmt.ExecContext(ctx, args...)
+		}
+	}
+	return result, err
+}
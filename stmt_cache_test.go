@@ -0,0 +1,156 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// prepareCountingDriver is a minimal driver that counts prepared statements
+type prepareCountingDriver struct {
+	prepared int64
+	closed   int64
+}
+
+func (d *prepareCountingDriver) Open(name string) (driver.Conn, error) {
+	return &prepareCountingConn{driver: d}, nil
+}
+
+type prepareCountingConn struct {
+	driver *prepareCountingDriver
+}
+
+func (c *prepareCountingConn) Prepare(query string) (driver.Stmt, error) {
+	if query == "BAD" {
+		return nil, errors.New("syntax error")
+	}
+	atomic.AddInt64(&c.driver.prepared, 1)
+	return &prepareCountingStmt{driver: c.driver}, nil
+}
+func (c *prepareCountingConn) Close() error              { return nil }
+func (c *prepareCountingConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *prepareCountingConn) Commit() error             { return nil }
+func (c *prepareCountingConn) Rollback() error           { return nil }
+
+type prepareCountingStmt struct {
+	driver *prepareCountingDriver
+}
+
+func (s *prepareCountingStmt) Close() error {
+	atomic.AddInt64(&s.driver.closed, 1)
+	return nil
+}
+func (s *prepareCountingStmt) NumInput() int { return -1 }
+func (s *prepareCountingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (s *prepareCountingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{"id"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestStmtCacheLRU(t *testing.T) {
+	drv := &prepareCountingDriver{}
+	sqlDB := sql.OpenDB(newTestConnector(drv))
+	defer sqlDB.Close()
+	cache := newStmtCache(sqlDB, 2)
+	ctx := context.Background()
+
+	for _, query := range []string{"SELECT 1", "SELECT 1", "SELECT 2", "SELECT 1", "SELECT 3"} {
+		_, release, err := cache.get(ctx, query)
+		assert.NoError(t, err)
+		release()
+	}
+	_, _, err := cache.get(ctx, "BAD")
+	assert.Error(t, err)
+
+	stats := cache.stats()
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(4), stats.Misses)
+	assert.Equal(t, int64(1), stats.Evictions, "SELECT 2 is the least recently used")
+	assert.Equal(t, 2, stats.Size)
+	assert.Contains(t, cache.entries, "SELECT 1")
+	assert.NotContains(t, cache.entries, "SELECT 2")
+
+	cache.reset()
+	assert.Equal(t, 0, cache.stats().Size)
+	assert.Equal(t, int64(2), cache.stats().Invalidations)
+}
+
+func TestStmtCacheKeepsStatementsInUse(t *testing.T) {
+	drv := &prepareCountingDriver{}
+	sqlDB := sql.OpenDB(newTestConnector(drv))
+	defer sqlDB.Close()
+	cache := newStmtCache(sqlDB, 1)
+	ctx := context.Background()
+
+	stmt, release, err := cache.get(ctx, "SELECT 1")
+	assert.NoError(t, err)
+	_, releaseOther, err := cache.get(ctx, "SELECT 2") // Evicts SELECT 1 while it is held
+	assert.NoError(t, err)
+	releaseOther()
+	cache.reset()
+	assert.Equal(t, int64(1), atomic.LoadInt64(&drv.closed), "only the unused SELECT 2 is closed")
+
+	_, err = stmt.ExecContext(ctx)
+	assert.NoError(t, err)
+	release()
+	_, err = stmt.ExecContext(ctx)
+	assert.EqualError(t, err, "sql: statement is closed")
+}
+
+func TestChainUsesStmtCache(t *testing.T) {
+	drv := &prepareCountingDriver{}
+	sqlDB := sql.OpenDB(newTestConnector(drv))
+	defer sqlDB.Close()
+	db := &DB{DB: sqlDB, stmts: newStmtCache(sqlDB, 10)}
+	chain := &Chain{db: db}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		rows, err := chain.preparedQueryContext(ctx, "SELECT * FROM users WHERE id = ?", []interface{}{i})
+		assert.NoError(t, err)
+		rows.Close()
+	}
+	_, err := chain.preparedExecContext(ctx, "CREATE TABLE t (id INT)", nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, StmtCacheStats{Hits: 2, Misses: 1, Size: 1}, db.StmtCacheStats())
+
+	// Statements prepared on the DB are reused inside transactions
+	tx, err := sqlDB.Begin()
+	assert.NoError(t, err)
+	txChain := &Chain{db: db, tx: tx}
+	_, err = txChain.preparedExecContext(ctx, "SELECT * FROM users WHERE id = ?", []interface{}{1})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+	assert.Equal(t, int64(3), db.StmtCacheStats().Hits)
+}
+
+func TestIsStalePlanError(t *testing.T) {
+	assert.True(t, isStalePlanError(errors.New("ERROR: cached plan must not change result type (SQLSTATE 0A000)")))
+	assert.False(t, isStalePlanError(errors.New("ERROR: relation does not exist")))
+}
+
+// driverConnector adapts a driver.Driver to a driver.Connector
+type driverConnector struct {
+	driver driver.Driver
+}
+
+func newTestConnector(d driver.Driver) driver.Connector {
+	return driverConnector{driver: d}
+}
+
+func (c driverConnector) Connect(ctx context.Context) (driver.Conn, error) { return c.driver.Open("") }
+func (c driverConnector) Driver() driver.Driver                            { return c.driver }
@@ -0,0 +1,170 @@
+package gom
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kmlixh/gom/v4/define"
+)
+
+// UseTracer enables tracing with tracer
+// Every statement gets a span nested under its transaction span, and batch
+// operations get a span per chunk. Parent spans are taken from the chain
+// context set via WithContext. UseTracer should be called once per DB
+func (db *DB) UseTracer(tracer define.Tracer) *DB {
+	if tracer == nil {
+		return db
+	}
+	p := db.getPlugins()
+	p.mu.Lock()
+	p.tracer = tracer
+	p.mu.Unlock()
+
+	system := ""
+	if db.Factory != nil {
+		system = db.Factory.GetType()
+	}
+
+	db.Use(func(ctx context.Context, proto *define.SqlProto, next Handler) *define.Result {
+		operation := proto.Operation()
+		ctx, span := tracer.Start(ctx, spanName(operation, proto.Table),
+			define.Attr(define.AttrDBSystem, system),
+			define.Attr(define.AttrDBOperation, operation),
+		)
+		if proto.Table != "" {
+			span.SetAttributes(define.Attr(define.AttrDBTable, proto.Table))
+		}
+		result := next(ctx, proto)
+		// Set after next so the span shows the SQL produced by inner interceptors
+		span.SetAttributes(define.Attr(define.AttrDBStatement, sanitizeSQL(proto.Sql)))
+		endSpan(span, result)
+		return result
+	})
+
+	var txSpans sync.Map
+	endTxSpan := func(txID uint64, outcome string, err error) {
+		if value, ok := txSpans.LoadAndDelete(txID); ok {
+			span := value.(define.Span)
+			span.SetAttributes(define.Attr(define.AttrTxOutcome, outcome))
+			span.RecordError(err)
+			span.End()
+		}
+	}
+	db.UseTxHooks(TxHooks{
+		OnBegin: func(ctx context.Context, txID uint64) (context.Context, error) {
+			ctx, span := tracer.Start(ctx, "gom.transaction",
+				define.Attr(define.AttrDBSystem, system),
+				define.Attr(define.AttrTxID, txID),
+			)
+			txSpans.Store(txID, span)
+			return ctx, nil
+		},
+		OnCommit: func(ctx context.Context, txID uint64, err error) {
+			endTxSpan(txID, "commit", err)
+		},
+		OnRollback: func(ctx context.Context, txID uint64, err error) {
+			endTxSpan(txID, "rollback", err)
+		},
+	})
+	return db
+}
+
+// tracer returns the tracer registered with UseTracer, or nil
+func (db *DB) tracer() define.Tracer {
+	if db == nil || db.plugins == nil {
+		return nil
+	}
+	db.plugins.mu.RLock()
+	defer db.plugins.mu.RUnlock()
+	return db.plugins.tracer
+}
+
+// startSpan starts a span when tracing is enabled, otherwise it returns a no-op span
+func (db *DB) startSpan(ctx context.Context, name string, attrs ...define.Attribute) (context.Context, define.Span) {
+	tracer := db.tracer()
+	if tracer == nil {
+		return ctx, define.NoopSpan
+	}
+	return tracer.Start(ctx, name, attrs...)
+}
+
+// traceBatch starts the span covering a whole batch operation
+func (c *Chain) traceBatch(ctx context.Context, operation string, concurrent bool) (context.Context, define.Span) {
+	return c.db.startSpan(ctx, "gom.batch_"+operation,
+		define.Attr(define.AttrDBTable, c.tableName),
+		define.Attr(define.AttrBatchSize, len(c.batchValues)),
+		define.Attr(define.AttrBatchParallel, concurrent),
+	)
+}
+
+// traceChunks wraps a batch processor so every chunk runs in its own child span
+func (c *Chain) traceChunks(processor func(context.Context, []map[string]interface{}) *define.Result) func(context.Context, []map[string]interface{}) *define.Result {
+	if c.db.tracer() == nil {
+		return processor
+	}
+	var chunk int64
+	return func(ctx context.Context, batch []map[string]interface{}) *define.Result {
+		ctx, span := c.db.startSpan(ctx, "gom.batch_chunk",
+			define.Attr(define.AttrBatchChunk, atomic.AddInt64(&chunk, 1)),
+			define.Attr(define.AttrBatchSize, len(batch)),
+		)
+		result := processor(ctx, batch)
+		endSpan(span, result)
+		return result
+	}
+}
+
+// endSpan records the outcome of result on span and ends it
+func endSpan(span define.Span, result *define.Result) {
+	if result != nil {
+		span.SetAttributes(define.Attr(define.AttrDBRows, resultRows(result)))
+		span.RecordError(result.Error)
+	}
+	span.End()
+}
+
+// spanName names a statement span after its operation and table, e.g. "SELECT users"
+func spanName(operation, table string) string {
+	if operation == "" {
+		operation = "SQL"
+	}
+	if table == "" {
+		return operation
+	}
+	return operation + " " + table
+}
+
+// sanitizeSQL replaces quoted string literals with ? so literal values in raw SQL do not reach spans
+func sanitizeSQL(sql string) string {
+	if !strings.Contains(sql, "'") {
+		return sql
+	}
+	var sb strings.Builder
+	sb.Grow(len(sql))
+	inString := false
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		if !inString {
+			if ch == '\'' {
+				inString = true
+				sb.WriteByte('?')
+				continue
+			}
+			sb.WriteByte(ch)
+			continue
+		}
+		if ch == '\'' {
+			// A doubled quote is an escaped quote inside the literal
+			if i+1 < len(sql) && sql[i+1] == '\'' {
+				i++
+				continue
+			}
+			inString = false
+		} else if ch == '\\' {
+			i++
+		}
+	}
+	return sb.String()
+}
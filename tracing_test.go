@@ -0,0 +1,114 @@
+package gom
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/gomtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracerStatementSpans(t *testing.T) {
+	exporter := define.NewInMemoryExporter()
+	tracer := define.NewSimpleTracer(exporter)
+	db := (&DB{}).UseTracer(tracer)
+
+	ctx, request := tracer.Start(context.Background(), "request")
+	chain := (&Chain{db: db, tableName: "users"}).WithContext(ctx)
+	chain.execute(&define.SqlProto{Sql: "SELECT * FROM users WHERE name = 'bob'"}, func(ctx context.Context, proto *define.SqlProto) *define.Result {
+		return &define.Result{Data: []map[string]interface{}{{"id": 1}}}
+	})
+	request.End()
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 2)
+	stmt := spans[0]
+	assert.Equal(t, "SELECT users", stmt.Name)
+	assert.Equal(t, spans[1].SpanID, stmt.ParentID)
+	assert.Equal(t, "SELECT", stmt.Attributes[define.AttrDBOperation])
+	assert.Equal(t, "users", stmt.Attributes[define.AttrDBTable])
+	assert.Equal(t, "SELECT * FROM users WHERE name = ?", stmt.Attributes[define.AttrDBStatement])
+	assert.Equal(t, int64(1), stmt.Attributes[define.AttrDBRows])
+}
+
+func TestTracerTransactionSpans(t *testing.T) {
+	exporter := define.NewInMemoryExporter()
+	db := (&DB{}).UseTracer(define.NewSimpleTracer(exporter))
+	hooks := db.txHookList()
+	assert.Len(t, hooks, 1)
+
+	ctx, err := hooks[0].OnBegin(context.Background(), 9)
+	assert.NoError(t, err)
+	chain := &Chain{db: db, ctx: ctx, txID: 9}
+	chain.execute(&define.SqlProto{Sql: "DELETE FROM users"}, func(ctx context.Context, proto *define.SqlProto) *define.Result {
+		return &define.Result{Error: errors.New("locked")}
+	})
+	hooks[0].OnRollback(ctx, 9, nil)
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 2)
+	stmt, tx := spans[0], spans[1]
+	assert.Equal(t, "gom.transaction", tx.Name)
+	assert.Equal(t, tx.SpanID, stmt.ParentID)
+	assert.EqualError(t, stmt.Err, "locked")
+	assert.Equal(t, "rollback", tx.Attributes[define.AttrTxOutcome])
+	assert.Equal(t, uint64(9), tx.Attributes[define.AttrTxID])
+}
+
+func TestTraceChunks(t *testing.T) {
+	exporter := define.NewInMemoryExporter()
+	db := (&DB{}).UseTracer(define.NewSimpleTracer(exporter))
+	chain := &Chain{db: db, tableName: "users", batchValues: make([]map[string]interface{}, 4)}
+
+	ctx, span := chain.traceBatch(context.Background(), "insert", true)
+	processor := chain.traceChunks(func(ctx context.Context, batch []map[string]interface{}) *define.Result {
+		return &define.Result{Affected: int64(len(batch))}
+	})
+	result := processBatchesWithTimeout(ctx, chain.batchValues, 2, 2, time.Minute, processor)
+	endSpan(span, result)
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 3)
+	batch := spans[2]
+	assert.Equal(t, "gom.batch_insert", batch.Name)
+	for _, chunk := range spans[:2] {
+		assert.Equal(t, "gom.batch_chunk", chunk.Name)
+		assert.Equal(t, batch.SpanID, chunk.ParentID)
+		assert.Equal(t, int64(2), chunk.Attributes[define.AttrDBRows])
+	}
+}
+
+func TestSanitizeSQL(t *testing.T) {
+	assert.Equal(t, "SELECT ? , ?", sanitizeSQL("SELECT 'it''s' , 'a\\'b'"))
+	assert.Equal(t, "SELECT $1", sanitizeSQL("SELECT $1"))
+}
+
+func TestTransactionWithOptionsKeepsChainContext(t *testing.T) {
+	db, _ := openMock(t, gomtest.MySQL)
+	exporter := define.NewInMemoryExporter()
+	tracer := define.NewSimpleTracer(exporter)
+	db.UseTracer(tracer)
+
+	ctx, request := tracer.Start(context.Background(), "request")
+	err := db.Chain().WithContext(ctx).TransactionWithOptions(define.TransactionOptions{}, func(tx *Chain) error {
+		return tx.RawExecute("UPDATE users SET name = 'bob'").Error
+	})
+	request.End()
+	assert.NoError(t, err)
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, "gom.transaction", spans[1].Name)
+	assert.Equal(t, spans[2].SpanID, spans[1].ParentID, "the transaction span is a child of the request span")
+
+	// 已取消的上下文不能开始事务
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = db.Chain().WithContext(canceled).TransactionWithOptions(define.TransactionOptions{}, func(tx *Chain) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
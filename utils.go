@@ -2,6 +2,8 @@ package gom
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -121,23 +123,21 @@ func processBatchesWithTimeout[T any](
 	}
 }
 
-// retryWithBackoff retries an operation with exponential backoff
-func retryWithBackoff(
+// retryWithBackoff retries an operation failing with a transient error with exponential backoff
+// Every retry after a failed attempt is counted in the metrics under name
+func (db *DB) retryWithBackoff(
 	ctx context.Context,
-	retryCount int,
+	name string,
+	attempts int,
 	retryInterval time.Duration,
 	operation func() error,
 ) error {
 	var lastErr error
 
-	for i := 0; i < retryCount; i++ {
-		if err := operation(); err == nil {
-			return nil
-		} else {
-			lastErr = err
-
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
 			// Calculate backoff duration
-			backoff := retryInterval * time.Duration(1<<uint(i))
+			backoff := retryInterval * time.Duration(1<<uint(i-1))
 
 			// Wait with context cancellation support
 			select {
@@ -145,12 +145,53 @@ func retryWithBackoff(
 				return ctx.Err()
 			case <-time.After(backoff):
 			}
+			db.metrics.recordRetry(name)
+		}
+		if lastErr = operation(); lastErr == nil || !retryable(lastErr) {
+			return lastErr
 		}
 	}
 
 	return lastErr
 }
 
+// retryable reports whether err is transient, so that running the failed transaction again may succeed
+// A connection error during COMMIT is not retried: the server may have applied the transaction
+// before the reply was lost, and running it again would apply it twice
+func retryable(err error) bool {
+	if errorCategory(err) == ErrCategoryConnection {
+		var commitErr *commitError
+		return !errors.As(err, &commitErr)
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "deadlock") ||
+		strings.Contains(msg, "could not serialize") ||
+		strings.Contains(msg, "lock wait timeout")
+}
+
+// retryChunks retries batch chunks failing with a transient error, as configured by DBOptions.RetryCount
+func (c *Chain) retryChunks(name string, processor func(context.Context, []map[string]interface{}) *define.Result) func(context.Context, []map[string]interface{}) *define.Result {
+	retries := c.db.options.RetryCount
+	if retries <= 0 || c.dryRun != nil {
+		return processor
+	}
+	interval := c.db.options.RetryInterval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+	return func(ctx context.Context, batch []map[string]interface{}) *define.Result {
+		var result *define.Result
+		err := c.db.retryWithBackoff(ctx, name, retries+1, interval, func() error {
+			result = processor(ctx, batch)
+			return result.Error
+		})
+		if err != nil {
+			return &define.Result{Error: err}
+		}
+		return result
+	}
+}
+
 // trackProgress tracks progress of batch operations
 type progressTracker struct {
 	total     int64
@@ -0,0 +1,155 @@
+package gom
+
+import (
+	"testing"
+
+	"github.com/kmlixh/gom/v4/define"
+	"github.com/kmlixh/gom/v4/gomtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// activeUser is a model generated for a view
+type activeUser struct {
+	ID   int64  `gom:"id,@"`
+	Name string `gom:"name"`
+}
+
+func (m *activeUser) TableName() string { return "active_users" }
+
+func (m *activeUser) ReadOnly() bool { return true }
+
+func TestListViewsPostgres(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	mock.On("FROM pg_catalog.pg_views").Return(gomtest.NewRows("name").
+		AddRow("public.active_users").
+		AddRow("report.daily_sales"))
+	mock.On("FROM pg_catalog.pg_matviews").Return(gomtest.NewRows("name").AddRow("report.monthly_sales"))
+	mock.On("FROM pg_catalog.pg_namespace").Return(gomtest.NewRows("nspname").AddRow("public").AddRow("report"))
+	mock.On("FROM pg_catalog.pg_tables").Return(gomtest.NewRows("name").AddRow("report.orders"))
+
+	views, err := db.GetViews("*.*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"public.active_users", "report.daily_sales"}, views)
+	mock.AssertArgs(t, 0, "%", "%")
+
+	matviews, err := db.GetMaterializedViews("report.monthly*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"report.monthly_sales"}, matviews)
+	mock.AssertArgs(t, 1, "report", "monthly%")
+
+	schemas, err := db.ListSchemas()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"public", "report"}, schemas)
+
+	tables, err := db.GetTables("report.*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"report.orders"}, tables)
+	mock.AssertArgs(t, 3, "report", "%")
+	// 没有 schema 的模式只匹配 public
+	_, err = db.GetTables("orders")
+	require.NoError(t, err)
+	mock.AssertArgs(t, 4, "public", "orders")
+}
+
+func TestListViewsMySQL(t *testing.T) {
+	db, mock := openMock(t, gomtest.MySQL)
+	mock.On("SELECT DATABASE()").Return(gomtest.NewRows("database").AddRow("shop"))
+	mock.On("information_schema.VIEWS").Return(gomtest.NewRows("TABLE_NAME").AddRow("order_totals"))
+	mock.On("information_schema.SCHEMATA").Return(gomtest.NewRows("SCHEMA_NAME").AddRow("shop").AddRow("stats"))
+
+	views, err := db.GetViews("order%")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"order_totals"}, views)
+	matviews, err := db.GetMaterializedViews("*")
+	require.NoError(t, err)
+	assert.Empty(t, matviews)
+	schemas, err := db.ListSchemas()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop", "stats"}, schemas)
+	_, err = db.GetTables("*")
+	require.NoError(t, err)
+
+	mock.AssertStatements(t,
+		"SELECT DATABASE()",
+		"SELECT TABLE_NAME FROM information_schema.VIEWS WHERE TABLE_SCHEMA = ? AND TABLE_NAME LIKE ? ORDER BY TABLE_NAME",
+		"FROM information_schema.SCHEMATA",
+		"SELECT DATABASE()",
+		"SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME",
+	)
+}
+
+func TestGetTableInfoViews(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	mock.On("pg_get_viewdef").Return(gomtest.NewRows("comment", "relkind", "definition").
+		AddRow("monthly totals", "m", " SELECT date_trunc('month', created_at) AS month FROM orders;"))
+	info, err := db.GetTableInfo("report.monthly_sales")
+	require.NoError(t, err)
+	assert.True(t, info.IsView)
+	assert.True(t, info.IsMaterialized)
+	assert.Equal(t, "monthly totals", info.TableComment)
+	assert.Equal(t, " SELECT date_trunc('month', created_at) AS month FROM orders;", info.ViewDefinition)
+
+	db, mock = openMock(t, gomtest.Postgres)
+	mock.On("SELECT CURRENT_SCHEMA").Return(gomtest.NewRows("current_schema").AddRow("public"))
+	mock.On("pg_get_viewdef").Return(gomtest.NewRows("comment", "relkind", "definition").AddRow("", "r", ""))
+	info, err = db.GetTableInfo("orders")
+	require.NoError(t, err)
+	assert.False(t, info.IsView)
+	assert.Empty(t, info.ViewDefinition)
+
+	db, mock = openMock(t, gomtest.MySQL)
+	mock.On("information_schema.tables").Return(gomtest.NewRows("table_comment", "engine", "table_collation", "table_type").
+		AddRow("VIEW", nil, nil, "VIEW"))
+	mock.On("information_schema.views").Return(gomtest.NewRows("view_definition").
+		AddRow("select `shop`.`orders`.`user_id` AS `user_id` from `shop`.`orders`"))
+	info, err = db.GetTableInfo("order_totals")
+	require.NoError(t, err)
+	assert.True(t, info.IsView)
+	assert.False(t, info.IsMaterialized)
+	assert.Empty(t, info.TableComment)
+	assert.Equal(t, "select `shop`.`orders`.`user_id` AS `user_id` from `shop`.`orders`", info.ViewDefinition)
+}
+
+func TestRefreshMaterializedView(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	require.NoError(t, db.RefreshMaterializedView("report.monthly_sales", false))
+	require.NoError(t, db.RefreshMaterializedView("daily_sales", true))
+	mock.AssertStatements(t,
+		`REFRESH MATERIALIZED VIEW "report"."monthly_sales"`,
+		`REFRESH MATERIALIZED VIEW CONCURRENTLY "daily_sales"`,
+	)
+
+	db, mock = openMock(t, gomtest.MySQL)
+	assert.EqualError(t, db.RefreshMaterializedView("daily_sales", false), "mysql does not support materialized views")
+	mock.AssertStatements(t)
+}
+
+func TestReadOnlyModel(t *testing.T) {
+	db, mock := openMock(t, gomtest.Postgres)
+	user := &activeUser{ID: 1, Name: "alice"}
+
+	assert.ErrorIs(t, db.Chain().Insert(user).Error, define.ErrReadOnlyModel)
+	assert.ErrorIs(t, db.Chain().Insert(*user).Error, define.ErrReadOnlyModel)
+	assert.ErrorIs(t, db.Chain().Upsert(user).Error, define.ErrReadOnlyModel)
+	assert.ErrorIs(t, db.Chain().Update(user).Error, define.ErrReadOnlyModel)
+	assert.ErrorIs(t, db.Chain().From(user).Set("name", "bob").Update().Error, define.ErrReadOnlyModel)
+	assert.ErrorIs(t, db.Chain().Delete(user).Error, define.ErrReadOnlyModel)
+	_, err := db.Chain().BatchInsertModels([]activeUser{*user}, 10, false)
+	assert.ErrorIs(t, err, define.ErrReadOnlyModel)
+	_, err = db.Chain().From(user).BatchValues([]map[string]interface{}{{"id": 2, "name": "bob"}}).BatchInsert(10, false)
+	assert.ErrorIs(t, err, define.ErrReadOnlyModel)
+	_, err = db.Chain().From(user).BatchValues([]map[string]interface{}{{"id": 1, "name": "bob"}}).BatchUpdate(10)
+	assert.ErrorIs(t, err, define.ErrReadOnlyModel)
+	_, err = db.Chain().From(user).Where("id", define.OpEq, 1).BatchDelete(10)
+	assert.ErrorIs(t, err, define.ErrReadOnlyModel)
+	assert.EqualError(t, db.Chain().Insert(user).Error, "cannot insert *gom.activeUser: model is read-only")
+	mock.AssertStatements(t)
+
+	// 读取不受影响
+	mock.On("active_users").Return(gomtest.NewRows("id", "name").AddRow(1, "alice"))
+	var users []activeUser
+	require.NoError(t, db.Chain().Table("active_users").List(&users).Error)
+	assert.Equal(t, []activeUser{{ID: 1, Name: "alice"}}, users)
+}